@@ -11,6 +11,7 @@ import (
     "bytes"
     "errors"
     "crypto/aes"
+    "crypto/rand"
     "crypto/cipher"
 )
 
@@ -66,6 +67,49 @@ func Decrypt(cipherText []byte, key []byte, iv...[]byte) ([]byte, error) {
     return plainText, nil
 }
 
+// AES加密, 使用GCM模式(带认证的加密)，注意key必须为16/24/32位长度，
+// 每次加密使用随机生成的nonce，并放在返回的密文前面；additionalData为可选的附加认证数据，解密时必须一致
+func EncryptGCM(plainText []byte, key []byte, additionalData...[]byte) ([]byte, error) {
+    aead, err := newGCM(key)
+    if err != nil {
+        return nil, err
+    }
+    nonce := make([]byte, aead.NonceSize())
+    if _, err := rand.Read(nonce); err != nil {
+        return nil, err
+    }
+    var data []byte
+    if len(additionalData) > 0 {
+        data = additionalData[0]
+    }
+    return aead.Seal(nonce, nonce, plainText, data), nil
+}
+
+// AES解密, 使用GCM模式，密文被篡改或者key不正确时返回错误
+func DecryptGCM(cipherText []byte, key []byte, additionalData...[]byte) ([]byte, error) {
+    aead, err := newGCM(key)
+    if err != nil {
+        return nil, err
+    }
+    nonceSize := aead.NonceSize()
+    if len(cipherText) < nonceSize + aead.Overhead() {
+        return nil, errors.New("cipherText too short")
+    }
+    var data []byte
+    if len(additionalData) > 0 {
+        data = additionalData[0]
+    }
+    return aead.Open(nil, cipherText[:nonceSize], cipherText[nonceSize:], data)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+    block, err := aes.NewCipher(key)
+    if err != nil {
+        return nil, err
+    }
+    return cipher.NewGCM(block)
+}
+
 func PKCS5Padding(src []byte, blockSize int) []byte {
     padding := blockSize - len(src)%blockSize
     padtext := bytes.Repeat([]byte{byte(padding)}, padding)
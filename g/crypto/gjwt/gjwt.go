@@ -0,0 +1,440 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+// JWT(JSON Web Token)签发及校验，支持HS256/RS256/ES256算法，只依赖标准库.
+package gjwt
+
+import (
+    "time"
+    "errors"
+    "strings"
+    "crypto"
+    "crypto/rsa"
+    "crypto/hmac"
+    "crypto/rand"
+    "crypto/ecdsa"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "encoding/base64"
+    "math/big"
+)
+
+const (
+    ALGORITHM_HS256 = "HS256" // HMAC SHA-256
+    ALGORITHM_RS256 = "RS256" // RSA PKCS#1 v1.5 SHA-256
+    ALGORITHM_ES256 = "ES256" // ECDSA P-256 SHA-256
+
+    TOKEN_TYPE_ACCESS  = "access"  // 访问令牌
+    TOKEN_TYPE_REFRESH = "refresh" // 刷新令牌
+
+    gDEFAULT_EXPIRE         = 2*time.Hour    // 访问令牌默认有效期
+    gDEFAULT_REFRESH_EXPIRE = 7*24*time.Hour // 刷新令牌默认有效期
+)
+
+var (
+    ErrInvalidToken     = errors.New("invalid token")
+    ErrInvalidSignature = errors.New("invalid token signature")
+    ErrInvalidAlgorithm = errors.New("unexpected token algorithm")
+    ErrTokenExpired     = errors.New("token is expired")
+    ErrTokenNotValidYet = errors.New("token is not valid yet")
+    ErrInvalidIssuer    = errors.New("invalid token issuer")
+    ErrInvalidAudience  = errors.New("invalid token audience")
+    ErrInvalidType      = errors.New("invalid token type")
+    ErrTokenRevoked     = errors.New("token is revoked")
+)
+
+// JWT配置
+type Config struct {
+    Algorithm     string           // 签名算法：HS256(默认)、RS256、ES256
+    Secret        []byte           // HS256密钥
+    PrivateKey    crypto.Signer    // RS256/ES256签名私钥(*rsa.PrivateKey/*ecdsa.PrivateKey)，只校验令牌时可以为空
+    PublicKey     crypto.PublicKey // RS256/ES256校验公钥，为空时使用私钥对应的公钥
+    Issuer        string           // 签发者(iss)，不为空时签发时写入并在校验时检查
+    Audience      string           // 接收者(aud)，不为空时签发时写入并在校验时检查
+    Expire        time.Duration    // 访问令牌有效期，默认2小时
+    RefreshExpire time.Duration    // 刷新令牌有效期，默认7天
+    Leeway        time.Duration    // 校验exp/nbf时允许的时钟误差
+    Store         Store            // 令牌吊销存储，为空时不支持吊销检查
+}
+
+// JWT管理对象
+type Jwt struct {
+    config Config
+}
+
+// 令牌中的声明(payload)
+type Claims map[string]interface{}
+
+// 访问令牌及刷新令牌
+type TokenPair struct {
+    AccessToken  string `json:"access_token"`
+    RefreshToken string `json:"refresh_token"`
+    ExpiresIn    int64  `json:"expires_in"` // 访问令牌有效期(秒)
+}
+
+// JWT头部
+type header struct {
+    Alg string `json:"alg"`
+    Typ string `json:"typ"`
+}
+
+// 注册声明(sub除外)，刷新令牌时不会复制到新的令牌中
+var registeredClaims = []string{"iss", "aud", "exp", "nbf", "iat", "jti", "typ"}
+
+// 创建JWT管理对象，配置错误(例如缺少密钥)时返回错误
+func New(config Config) (*Jwt, error) {
+    if config.Algorithm == "" {
+        config.Algorithm = ALGORITHM_HS256
+    }
+    if config.Expire <= 0 {
+        config.Expire = gDEFAULT_EXPIRE
+    }
+    if config.RefreshExpire <= 0 {
+        config.RefreshExpire = gDEFAULT_REFRESH_EXPIRE
+    }
+    if config.PublicKey == nil && config.PrivateKey != nil {
+        config.PublicKey = config.PrivateKey.Public()
+    }
+    switch config.Algorithm {
+        case ALGORITHM_HS256:
+            if len(config.Secret) == 0 {
+                return nil, errors.New("secret is required for HS256")
+            }
+        case ALGORITHM_RS256:
+            if _, ok := config.PublicKey.(*rsa.PublicKey); !ok {
+                return nil, errors.New("rsa key is required for RS256")
+            }
+        case ALGORITHM_ES256:
+            if key, ok := config.PublicKey.(*ecdsa.PublicKey); !ok || key.Curve.Params().BitSize != 256 {
+                return nil, errors.New("ecdsa P-256 key is required for ES256")
+            }
+        default:
+            return nil, errors.New("unsupported algorithm: " + config.Algorithm)
+    }
+    return &Jwt{config : config}, nil
+}
+
+// 签发访问令牌，自动写入iat、exp、jti以及配置的iss、aud(claims中已经设置的值优先)
+func (j *Jwt) Sign(claims Claims) (string, error) {
+    return j.sign(claims, TOKEN_TYPE_ACCESS, j.config.Expire)
+}
+
+// 签发访问令牌及刷新令牌
+func (j *Jwt) SignPair(claims Claims) (*TokenPair, error) {
+    access, err := j.sign(claims, TOKEN_TYPE_ACCESS, j.config.Expire)
+    if err != nil {
+        return nil, err
+    }
+    refresh, err := j.sign(claims, TOKEN_TYPE_REFRESH, j.config.RefreshExpire)
+    if err != nil {
+        return nil, err
+    }
+    return &TokenPair {
+        AccessToken  : access,
+        RefreshToken : refresh,
+        ExpiresIn    : int64(j.config.Expire/time.Second),
+    }, nil
+}
+
+// 校验访问令牌并返回声明，检查签名、算法、exp/nbf/iss/aud以及吊销状态；刷新令牌不能作为访问令牌使用
+func (j *Jwt) Parse(token string) (Claims, error) {
+    claims, err := j.parse(token)
+    if err != nil {
+        return nil, err
+    }
+    if claims.GetString("typ") == TOKEN_TYPE_REFRESH {
+        return nil, ErrInvalidType
+    }
+    return claims, nil
+}
+
+// 使用刷新令牌换取新的访问令牌及刷新令牌，原刷新令牌将被吊销(需要设置Store)，防止重复使用
+func (j *Jwt) Refresh(refreshToken string) (*TokenPair, error) {
+    claims, err := j.parse(refreshToken)
+    if err != nil {
+        return nil, err
+    }
+    if claims.GetString("typ") != TOKEN_TYPE_REFRESH {
+        return nil, ErrInvalidType
+    }
+    // 并发使用同一刷新令牌时只有一个请求能够吊销成功，其余请求按照已吊销处理
+    if j.config.Store != nil {
+        if jti := claims.GetString("jti"); jti != "" {
+            ok, err := j.config.Store.RevokeIfAbsent(jti, j.revokeExpire(claims))
+            if err != nil {
+                return nil, err
+            }
+            if !ok {
+                return nil, ErrTokenRevoked
+            }
+        }
+    }
+    custom := make(Claims, len(claims))
+    for k, v := range claims {
+        custom[k] = v
+    }
+    for _, k := range registeredClaims {
+        delete(custom, k)
+    }
+    return j.SignPair(custom)
+}
+
+// 吊销令牌(访问令牌或者刷新令牌)，吊销记录保存到令牌过期为止，需要设置Store
+func (j *Jwt) Revoke(token string) error {
+    claims, err := j.parse(token)
+    if err != nil {
+        return err
+    }
+    return j.revokeClaims(claims)
+}
+
+// 吊销给定声明对应的令牌
+func (j *Jwt) revokeClaims(claims Claims) error {
+    if j.config.Store == nil {
+        return nil
+    }
+    jti := claims.GetString("jti")
+    if jti == "" {
+        return nil
+    }
+    expire := j.revokeExpire(claims)
+    if expire <= 0 {
+        return nil
+    }
+    return j.config.Store.Revoke(jti, expire)
+}
+
+// 吊销记录的保存时间，保存到令牌过期(包含时钟误差)为止
+func (j *Jwt) revokeExpire(claims Claims) time.Duration {
+    return time.Until(time.Unix(claims.GetInt64("exp"), 0)) + j.config.Leeway
+}
+
+// 签发令牌
+func (j *Jwt) sign(claims Claims, typ string, expire time.Duration) (string, error) {
+    now     := time.Now()
+    payload := make(Claims, len(claims) + 6)
+    if j.config.Issuer != "" {
+        payload["iss"] = j.config.Issuer
+    }
+    if j.config.Audience != "" {
+        payload["aud"] = j.config.Audience
+    }
+    payload["iat"] = now.Unix()
+    payload["exp"] = now.Add(expire).Unix()
+    payload["jti"] = newTokenId()
+    for k, v := range claims {
+        payload[k] = v
+    }
+    payload["typ"] = typ
+    headerJson, err := json.Marshal(header{Alg : j.config.Algorithm, Typ : "JWT"})
+    if err != nil {
+        return "", err
+    }
+    payloadJson, err := json.Marshal(payload)
+    if err != nil {
+        return "", err
+    }
+    content   := encodeSegment(headerJson) + "." + encodeSegment(payloadJson)
+    signature, err := j.signContent(content)
+    if err != nil {
+        return "", err
+    }
+    return content + "." + encodeSegment(signature), nil
+}
+
+// 校验令牌并返回声明
+func (j *Jwt) parse(token string) (Claims, error) {
+    parts := strings.Split(token, ".")
+    if len(parts) != 3 {
+        return nil, ErrInvalidToken
+    }
+    headerJson, err := decodeSegment(parts[0])
+    if err != nil {
+        return nil, ErrInvalidToken
+    }
+    h := header{}
+    if err := json.Unmarshal(headerJson, &h); err != nil {
+        return nil, ErrInvalidToken
+    }
+    // 只接受配置的算法，防止算法替换攻击(例如alg=none或者使用公钥作为HMAC密钥)
+    if h.Alg != j.config.Algorithm {
+        return nil, ErrInvalidAlgorithm
+    }
+    signature, err := decodeSegment(parts[2])
+    if err != nil {
+        return nil, ErrInvalidToken
+    }
+    if !j.verifyContent(parts[0] + "." + parts[1], signature) {
+        return nil, ErrInvalidSignature
+    }
+    payloadJson, err := decodeSegment(parts[1])
+    if err != nil {
+        return nil, ErrInvalidToken
+    }
+    claims := Claims{}
+    if err := json.Unmarshal(payloadJson, &claims); err != nil {
+        return nil, ErrInvalidToken
+    }
+    if err := j.validate(claims); err != nil {
+        return nil, err
+    }
+    if j.config.Store != nil {
+        if jti := claims.GetString("jti"); jti != "" {
+            revoked, err := j.config.Store.IsRevoked(jti)
+            if err != nil {
+                return nil, err
+            }
+            if revoked {
+                return nil, ErrTokenRevoked
+            }
+        }
+    }
+    return claims, nil
+}
+
+// 校验时间及签发者、接收者声明
+func (j *Jwt) validate(claims Claims) error {
+    now    := time.Now()
+    leeway := j.config.Leeway
+    if _, ok := claims["exp"]; ok && now.After(time.Unix(claims.GetInt64("exp"), 0).Add(leeway)) {
+        return ErrTokenExpired
+    }
+    if _, ok := claims["nbf"]; ok && now.Add(leeway).Before(time.Unix(claims.GetInt64("nbf"), 0)) {
+        return ErrTokenNotValidYet
+    }
+    if j.config.Issuer != "" && claims.GetString("iss") != j.config.Issuer {
+        return ErrInvalidIssuer
+    }
+    if j.config.Audience != "" && !claims.HasAudience(j.config.Audience) {
+        return ErrInvalidAudience
+    }
+    return nil
+}
+
+// 计算签名
+func (j *Jwt) signContent(content string) ([]byte, error) {
+    switch j.config.Algorithm {
+        case ALGORITHM_HS256:
+            mac := hmac.New(sha256.New, j.config.Secret)
+            mac.Write([]byte(content))
+            return mac.Sum(nil), nil
+        case ALGORITHM_RS256:
+            key, ok := j.config.PrivateKey.(*rsa.PrivateKey)
+            if !ok {
+                return nil, errors.New("rsa private key is required for signing")
+            }
+            hash := sha256.Sum256([]byte(content))
+            return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+        case ALGORITHM_ES256:
+            key, ok := j.config.PrivateKey.(*ecdsa.PrivateKey)
+            if !ok {
+                return nil, errors.New("ecdsa private key is required for signing")
+            }
+            hash := sha256.Sum256([]byte(content))
+            r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+            if err != nil {
+                return nil, err
+            }
+            // JWS规范要求签名为固定长度的r||s
+            signature := make([]byte, 64)
+            r.FillBytes(signature[:32])
+            s.FillBytes(signature[32:])
+            return signature, nil
+    }
+    return nil, ErrInvalidAlgorithm
+}
+
+// 校验签名
+func (j *Jwt) verifyContent(content string, signature []byte) bool {
+    switch j.config.Algorithm {
+        case ALGORITHM_HS256:
+            mac := hmac.New(sha256.New, j.config.Secret)
+            mac.Write([]byte(content))
+            return hmac.Equal(signature, mac.Sum(nil))
+        case ALGORITHM_RS256:
+            hash := sha256.Sum256([]byte(content))
+            return rsa.VerifyPKCS1v15(j.config.PublicKey.(*rsa.PublicKey), crypto.SHA256, hash[:], signature) == nil
+        case ALGORITHM_ES256:
+            if len(signature) != 64 {
+                return false
+            }
+            hash := sha256.Sum256([]byte(content))
+            r    := new(big.Int).SetBytes(signature[:32])
+            s    := new(big.Int).SetBytes(signature[32:])
+            return ecdsa.Verify(j.config.PublicKey.(*ecdsa.PublicKey), hash[:], r, s)
+    }
+    return false
+}
+
+// 获取字符串类型的声明
+func (c Claims) GetString(key string) string {
+    if v, ok := c[key].(string); ok {
+        return v
+    }
+    return ""
+}
+
+// 获取整型的声明(JSON数字解析后为float64)
+func (c Claims) GetInt64(key string) int64 {
+    switch v := c[key].(type) {
+        case float64: return int64(v)
+        case int64:   return v
+        case int:     return int64(v)
+        case json.Number:
+            i, _ := v.Int64()
+            return i
+    }
+    return 0
+}
+
+// 获取主题(sub)，一般为用户标识
+func (c Claims) Subject() string {
+    return c.GetString("sub")
+}
+
+// 获取过期时间
+func (c Claims) ExpiresAt() time.Time {
+    return time.Unix(c.GetInt64("exp"), 0)
+}
+
+// 判断接收者(aud)是否包含给定值，aud可以为字符串或者字符串数组
+func (c Claims) HasAudience(audience string) bool {
+    switch v := c["aud"].(type) {
+        case string:
+            return v == audience
+        case []interface{}:
+            for _, item := range v {
+                if s, ok := item.(string); ok && s == audience {
+                    return true
+                }
+            }
+        case []string:
+            for _, s := range v {
+                if s == audience {
+                    return true
+                }
+            }
+    }
+    return false
+}
+
+// 生成令牌唯一标识(jti)
+func newTokenId() string {
+    b := make([]byte, 16)
+    rand.Read(b)
+    return hex.EncodeToString(b)
+}
+
+// base64url编码(无填充)
+func encodeSegment(b []byte) string {
+    return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// base64url解码(无填充)
+func decodeSegment(s string) ([]byte, error) {
+    return base64.RawURLEncoding.DecodeString(s)
+}
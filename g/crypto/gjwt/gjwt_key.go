@@ -0,0 +1,55 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package gjwt
+
+import (
+    "errors"
+    "crypto"
+    "crypto/x509"
+    "encoding/pem"
+)
+
+// 解析PEM格式的私钥(RSA PKCS#1/PKCS#8、EC私钥)，用于RS256/ES256签名
+func ParsePrivateKey(pemData []byte) (crypto.Signer, error) {
+    block, _ := pem.Decode(pemData)
+    if block == nil {
+        return nil, errors.New("invalid pem data")
+    }
+    if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+        return key, nil
+    }
+    if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+        return key, nil
+    }
+    key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+    if err != nil {
+        return nil, err
+    }
+    if signer, ok := key.(crypto.Signer); ok {
+        return signer, nil
+    }
+    return nil, errors.New("unsupported private key type")
+}
+
+// 解析PEM格式的公钥(PKIX公钥、RSA PKCS#1公钥或者证书)，用于RS256/ES256校验
+func ParsePublicKey(pemData []byte) (crypto.PublicKey, error) {
+    block, _ := pem.Decode(pemData)
+    if block == nil {
+        return nil, errors.New("invalid pem data")
+    }
+    if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+        return key, nil
+    }
+    if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+        return key, nil
+    }
+    cert, err := x509.ParseCertificate(block.Bytes)
+    if err != nil {
+        return nil, err
+    }
+    return cert.PublicKey, nil
+}
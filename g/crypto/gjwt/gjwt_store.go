@@ -0,0 +1,117 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package gjwt
+
+import (
+    "sync"
+    "time"
+    "gitee.com/johng/gf/g/os/gcache"
+    "gitee.com/johng/gf/g/database/gredis"
+)
+
+const (
+    gDEFAULT_REDIS_PREFIX = "gjwt:revoked:" // Redis吊销记录默认键名前缀
+)
+
+// 令牌吊销存储接口，按照令牌唯一标识(jti)记录吊销状态，记录在令牌过期后可以删除
+type Store interface {
+    Revoke(jti string, expire time.Duration) error
+    IsRevoked(jti string) (bool, error)
+    // 原子性地判断并吊销令牌，令牌此前未被吊销时返回true，已被吊销时返回false(用于刷新令牌的一次性使用)
+    RevokeIfAbsent(jti string, expire time.Duration) (bool, error)
+}
+
+// 基于内存缓存的吊销存储(单进程)
+type cacheStore struct {
+    mu    sync.Mutex
+    cache *gcache.Cache
+}
+
+// 基于Redis的吊销存储(多进程/多节点共享)
+type redisStore struct {
+    redis  *gredis.Redis
+    prefix string
+}
+
+// 创建基于gcache的吊销存储，不传递参数时使用新的缓存对象
+func NewCacheStore(cache...*gcache.Cache) Store {
+    if len(cache) > 0 && cache[0] != nil {
+        return &cacheStore{cache : cache[0]}
+    }
+    return &cacheStore{cache : gcache.New()}
+}
+
+func (s *cacheStore) Revoke(jti string, expire time.Duration) error {
+    s.mu.Lock()
+    s.set(jti, expire)
+    s.mu.Unlock()
+    return nil
+}
+
+func (s *cacheStore) IsRevoked(jti string) (bool, error) {
+    return s.cache.Get(jti) != nil, nil
+}
+
+func (s *cacheStore) RevokeIfAbsent(jti string, expire time.Duration) (bool, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.cache.Get(jti) != nil {
+        return false, nil
+    }
+    s.set(jti, expire)
+    return true, nil
+}
+
+// 写入吊销记录，gcache的过期时间单位为毫秒，并且0表示不过期
+func (s *cacheStore) set(jti string, expire time.Duration) {
+    ms := int(expire/time.Millisecond)
+    if ms <= 0 {
+        ms = 1
+    }
+    s.cache.Set(jti, struct{}{}, ms)
+}
+
+// 创建基于gredis的吊销存储，prefix为键名前缀，默认为gjwt:revoked:
+func NewRedisStore(redis *gredis.Redis, prefix...string) Store {
+    s := &redisStore{redis : redis, prefix : gDEFAULT_REDIS_PREFIX}
+    if len(prefix) > 0 {
+        s.prefix = prefix[0]
+    }
+    return s
+}
+
+func (s *redisStore) Revoke(jti string, expire time.Duration) error {
+    _, err := s.redis.Do("SET", s.prefix + jti, 1, "PX", redisExpire(expire))
+    return err
+}
+
+// 使用SET NX保证原子性，键已存在时Redis返回nil
+func (s *redisStore) RevokeIfAbsent(jti string, expire time.Duration) (bool, error) {
+    v, err := s.redis.Do("SET", s.prefix + jti, 1, "PX", redisExpire(expire), "NX")
+    if err != nil {
+        return false, err
+    }
+    return v != nil, nil
+}
+
+func (s *redisStore) IsRevoked(jti string) (bool, error) {
+    v, err := s.redis.Do("EXISTS", s.prefix + jti)
+    if err != nil {
+        return false, err
+    }
+    n, _ := v.(int64)
+    return n > 0, nil
+}
+
+// Redis过期时间(毫秒)，最小为1毫秒
+func redisExpire(expire time.Duration) int64 {
+    ms := int64(expire/time.Millisecond)
+    if ms <= 0 {
+        ms = 1
+    }
+    return ms
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package gjwt
+
+import (
+    "sync"
+    "time"
+    "strings"
+    "testing"
+    "crypto/rsa"
+    "crypto/rand"
+    "crypto/ecdsa"
+    "crypto/elliptic"
+)
+
+func Test_Algorithms(t *testing.T) {
+    rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+    ecKey, _  := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    configs   := []Config {
+        {Algorithm : ALGORITHM_HS256, Secret : []byte("secret")},
+        {Algorithm : ALGORITHM_RS256, PrivateKey : rsaKey},
+        {Algorithm : ALGORITHM_ES256, PrivateKey : ecKey},
+    }
+    for _, config := range configs {
+        j, err := New(config)
+        if err != nil {
+            t.Fatal(err)
+        }
+        token, err := j.Sign(Claims{"sub" : "1001", "role" : "admin"})
+        if err != nil {
+            t.Fatal(err)
+        }
+        claims, err := j.Parse(token)
+        if err != nil {
+            t.Fatal(config.Algorithm, err)
+        }
+        if claims.Subject() != "1001" || claims.GetString("role") != "admin" {
+            t.Error(config.Algorithm, "unexpected claims:", claims)
+        }
+        // 篡改payload
+        parts    := strings.Split(token, ".")
+        parts[1]  = encodeSegment([]byte(`{"sub":"1002"}`))
+        if _, err := j.Parse(strings.Join(parts, ".")); err != ErrInvalidSignature {
+            t.Error(config.Algorithm, "tampered token accepted:", err)
+        }
+    }
+}
+
+func Test_Validate(t *testing.T) {
+    j, _ := New(Config{Secret : []byte("secret"), Issuer : "gf", Audience : "app"})
+    expired, _ := j.Sign(Claims{"exp" : time.Now().Add(-time.Minute).Unix()})
+    if _, err := j.Parse(expired); err != ErrTokenExpired {
+        t.Error("expected expired error, got:", err)
+    }
+    future, _ := j.Sign(Claims{"nbf" : time.Now().Add(time.Minute).Unix()})
+    if _, err := j.Parse(future); err != ErrTokenNotValidYet {
+        t.Error("expected not valid yet error, got:", err)
+    }
+    other, _ := New(Config{Secret : []byte("secret"), Issuer : "other", Audience : "app"})
+    token, _ := other.Sign(Claims{})
+    if _, err := j.Parse(token); err != ErrInvalidIssuer {
+        t.Error("expected issuer error, got:", err)
+    }
+    rs, _ := New(Config{Algorithm : ALGORITHM_HS256, Secret : []byte("another")})
+    token, _ = rs.Sign(Claims{})
+    if _, err := j.Parse(token); err != ErrInvalidSignature {
+        t.Error("expected signature error, got:", err)
+    }
+}
+
+func Test_RefreshAndRevoke(t *testing.T) {
+    j, _ := New(Config{Secret : []byte("secret"), Store : NewCacheStore()})
+    pair, err := j.SignPair(Claims{"sub" : "1001", "name" : "john"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := j.Parse(pair.RefreshToken); err != ErrInvalidType {
+        t.Error("refresh token accepted as access token:", err)
+    }
+    newPair, err := j.Refresh(pair.RefreshToken)
+    if err != nil {
+        t.Fatal(err)
+    }
+    claims, err := j.Parse(newPair.AccessToken)
+    if err != nil || claims.Subject() != "1001" || claims.GetString("name") != "john" {
+        t.Error("unexpected refreshed claims:", claims, err)
+    }
+    // 刷新令牌只能使用一次
+    if _, err := j.Refresh(pair.RefreshToken); err != ErrTokenRevoked {
+        t.Error("expected revoked error, got:", err)
+    }
+    if err := j.Revoke(newPair.AccessToken); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := j.Parse(newPair.AccessToken); err != ErrTokenRevoked {
+        t.Error("expected revoked error, got:", err)
+    }
+}
+
+func Test_RefreshConcurrent(t *testing.T) {
+    j, _ := New(Config{Secret : []byte("secret"), Store : NewCacheStore()})
+    pair, err := j.SignPair(Claims{"sub" : "1001"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    // 同一刷新令牌并发刷新时只能有一个请求成功
+    var (
+        wg      sync.WaitGroup
+        mu      sync.Mutex
+        success int
+    )
+    for i := 0; i < 20; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            if _, err := j.Refresh(pair.RefreshToken); err == nil {
+                mu.Lock()
+                success++
+                mu.Unlock()
+            } else if err != ErrTokenRevoked {
+                t.Error("expected revoked error, got:", err)
+            }
+        }()
+    }
+    wg.Wait()
+    if success != 1 {
+        t.Error("expected exactly one successful refresh, got:", success)
+    }
+}
@@ -14,6 +14,7 @@ import (
     "gitee.com/johng/gf/g/os/genv"
     "gitee.com/johng/gf/g/os/gview"
     "gitee.com/johng/gf/g/os/gfile"
+    "gitee.com/johng/gf/g/util/gi18n"
     "gitee.com/johng/gf/g/container/gmap"
 )
 
@@ -55,6 +56,7 @@ func View() *gview.View {
         }
         // 框架内置函数
         view.BindFunc("config", funcConfig)
+        view.BindFunc("T",      funcT)
         Set(gFRAME_CORE_COMPONENT_NAME_VIEW, view)
         return view
     }
@@ -92,3 +94,7 @@ func funcConfig(pattern string, file...string) gview.HTML {
     return gview.HTML(Config().GetString(pattern, file...))
 }
 
+// 模板内置方法：T，使用默认语言翻译(ghttp中的模板解析使用当前请求的语言)
+func funcT(key string, args...interface{}) string {
+    return gi18n.T(gi18n.Instance().GetDefaultLocale(), key, args...)
+}
@@ -0,0 +1,333 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+// 开发环境热编译运行工具.
+// 监控项目目录下的源码文件，文件变化后(去抖)自动执行go build重新编译并重启程序：
+// 1、程序为ghttp Web Server时，通过ghttp的平滑重启机制(与Server.Restart相同)使用新的可执行文件重启，已有的连接不会中断；
+// 2、编译失败时，正在运行的Web Server对所有请求输出编译错误页面，编译成功后自动恢复；
+// 3、其他程序直接结束旧进程后启动新进程。
+// 使用方式参考geg/frame/grun/gf.go，例如：gf run main.go
+package grun
+
+import (
+    "os"
+    "fmt"
+    "time"
+    "bytes"
+    "errors"
+    "os/exec"
+    "runtime"
+    "strings"
+    "crypto/rand"
+    "crypto/subtle"
+    "encoding/hex"
+    "path/filepath"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/os/gfile"
+    "gitee.com/johng/gf/g/os/gproc"
+    "gitee.com/johng/gf/g/os/gtime"
+    "gitee.com/johng/gf/g/os/gfsnotify"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+// 以下常量需要与ghttp包保持一致；
+// 进程消息中的来源PID只有低16位并且由发送方自行填写，因此与服务进程之间使用随机生成的令牌进行身份校验，
+// 消息格式：动作:令牌:内容
+const (
+    gPID_ENVKEY         = "GF_RUNNER_PID"        // 启动程序时，环境变量中保存的grun进程ID
+    gTOKEN_ENVKEY       = "GF_RUNNER_TOKEN"      // 启动程序时，环境变量中保存的通信令牌
+    gOUTPUT_ENVKEY      = "GF_RUNNER_OUTPUT"     // 启动程序时，环境变量中保存的可执行文件输出目录(服务进程只接受该目录中的可执行文件重启)
+    gGPROC_GROUP        = "GF_GPROC_RUNNER"      // grun进程接收消息的分组名称
+    gSERVER_GPROC_GROUP = "GF_GPROC_HTTP_SERVER" // ghttp服务进程接收消息的分组名称
+    gMSG_STARTED        = "started:"             // ghttp服务进程启动后发送的消息，格式：started:令牌:服务进程ID
+    gACTION_RESTART     = "restart:"             // 通知ghttp服务进程使用新的可执行文件平滑重启，格式：restart:令牌:可执行文件路径
+    gACTION_ERROR       = "error:"               // 通知ghttp服务进程展示编译错误，格式：error:令牌:编译错误信息
+)
+
+const (
+    gDEFAULT_DELAY    = 500*time.Millisecond // 默认的文件变化去抖时间
+    gDEFAULT_KEEP_BIN = 2                    // 保留的可执行文件数量(平滑重启时旧进程仍在使用旧的可执行文件)
+)
+
+// 热编译运行配置，未设置的参数使用默认值
+type Config struct {
+    Path       string        // 项目根目录(监控目录)，默认为当前工作目录
+    Main       string        // 编译入口文件或者包路径(相对于Path)，默认为"."
+    Output     string        // 可执行文件输出目录，默认为系统临时目录下的grun/项目名称
+    Args       []string      // 程序运行参数
+    BuildArgs  []string      // go build的额外参数，例如：-race、-tags=dev
+    Ignores    []string      // 不监控的目录名称，默认为vendor；隐藏目录(以"."开头)及输出目录始终不监控
+    Extensions []string      // 触发重新编译的文件扩展名，默认为.go
+    Delay      time.Duration // 文件变化后等待的去抖时间，等待期间有新的变化时重新计时，默认500毫秒
+}
+
+// 热编译运行对象
+type Runner struct {
+    config    Config
+    name      string             // 项目名称，作为可执行文件的名称前缀
+    changes   chan struct{}      // 文件变化通知
+    process   *gproc.Process     // grun直接启动的程序进程
+    exited    chan struct{}      // 程序进程退出通知
+    serverPid *gtype.Int         // 当前的ghttp服务进程ID，平滑重启后由新的服务进程通知更新
+    token     string             // 与服务进程通信的令牌(随机生成)
+    binaries  []string           // 编译生成的可执行文件，旧的文件在重启后删除
+}
+
+// 创建热编译运行对象
+func New(config...Config) *Runner {
+    c := Config{}
+    if len(config) > 0 {
+        c = config[0]
+    }
+    if c.Path == "" {
+        c.Path, _ = os.Getwd()
+    }
+    // 监控事件中的文件路径为真实路径
+    if p := gfile.RealPath(c.Path); p != "" {
+        c.Path = p
+    }
+    if c.Main == "" {
+        c.Main = "."
+    }
+    name := gfile.Basename(c.Path)
+    if c.Output == "" {
+        c.Output = filepath.Join(os.TempDir(), "grun", name)
+    }
+    if p, err := filepath.Abs(c.Output); err == nil {
+        c.Output = p
+    }
+    if c.Ignores == nil {
+        c.Ignores = []string{"vendor"}
+    }
+    if c.Extensions == nil {
+        c.Extensions = []string{".go"}
+    }
+    if c.Delay <= 0 {
+        c.Delay = gDEFAULT_DELAY
+    }
+    return &Runner {
+        config    : c,
+        name      : name,
+        changes   : make(chan struct{}, 1),
+        serverPid : gtype.NewInt(),
+        token     : newToken(),
+        binaries  : make([]string, 0),
+    }
+}
+
+// 使用给定配置执行热编译运行(阻塞执行)
+func Run(config...Config) error {
+    return New(config...).Run()
+}
+
+// 开始监控并编译运行(阻塞执行)，只有在监控初始化失败时才返回错误
+func (r *Runner) Run() error {
+    if !gfile.IsDir(r.config.Path) {
+        return errors.New(fmt.Sprintf("project path '%s' does not exist", r.config.Path))
+    }
+    if err := gfile.Mkdir(r.config.Output); err != nil {
+        return err
+    }
+    // 需要在启动程序之前开始接收消息，以便收到服务进程的启动通知
+    go r.receive()
+    if err := r.watch(); err != nil {
+        return err
+    }
+    glog.Printfln("grun: watching %s", r.config.Path)
+    r.rebuild()
+    for range r.changes {
+        // 去抖：等待期间有新的变化时重新计时，防止批量保存文件时重复编译
+        for waiting := true; waiting; {
+            select {
+                case <- r.changes:
+                case <- time.After(r.config.Delay):
+                    waiting = false
+            }
+        }
+        r.rebuild()
+    }
+    return nil
+}
+
+// 逐个目录添加监控，忽略的目录及其子目录不添加监控
+func (r *Runner) watch() error {
+    watcher, err := gfsnotify.New()
+    if err != nil {
+        return err
+    }
+    return filepath.Walk(r.config.Path, func(path string, info os.FileInfo, err error) error {
+        if err != nil || !info.IsDir() {
+            return nil
+        }
+        if path != r.config.Path && r.isIgnored(path) {
+            return filepath.SkipDir
+        }
+        return watcher.Add(path, r.onChange, false)
+    })
+}
+
+// 文件变化回调，只有监控的源码文件变化时才通知重新编译
+func (r *Runner) onChange(event *gfsnotify.Event) {
+    if event.IsChmod() || !r.isWatchedFile(event.Path) {
+        return
+    }
+    // 新建的目录会被自动添加监控，因此这里需要再次过滤忽略的目录
+    for dir := filepath.Dir(event.Path); len(dir) > len(r.config.Path); dir = filepath.Dir(dir) {
+        if r.isIgnored(dir) {
+            return
+        }
+    }
+    select {
+        case r.changes <- struct{}{}:
+        default:
+    }
+}
+
+// 判断是否为监控的文件类型
+func (r *Runner) isWatchedFile(path string) bool {
+    ext := strings.ToLower(filepath.Ext(path))
+    for _, v := range r.config.Extensions {
+        if strings.EqualFold(v, ext) {
+            return true
+        }
+    }
+    return false
+}
+
+// 判断目录是否忽略监控
+func (r *Runner) isIgnored(path string) bool {
+    if path == r.config.Output || strings.HasPrefix(path, r.config.Output + string(filepath.Separator)) {
+        return true
+    }
+    name := filepath.Base(path)
+    if strings.HasPrefix(name, ".") {
+        return true
+    }
+    for _, v := range r.config.Ignores {
+        if v == name {
+            return true
+        }
+    }
+    return false
+}
+
+// 接收ghttp服务进程的启动通知，记录最新的服务进程ID(使用消息内容中的完整进程ID)
+func (r *Runner) receive() {
+    prefix := gMSG_STARTED + r.token + ":"
+    for {
+        if msg := gproc.Receive(gGPROC_GROUP); msg != nil {
+            data := string(msg.Data)
+            if len(data) > len(prefix) && subtle.ConstantTimeCompare([]byte(data[:len(prefix)]), []byte(prefix)) == 1 {
+                if pid := gconv.Int(data[len(prefix):]); pid > 0 {
+                    r.serverPid.Set(pid)
+                }
+            }
+        }
+    }
+}
+
+// 重新编译并重启，编译失败时通知正在运行的服务进程展示编译错误
+func (r *Runner) rebuild() {
+    binary := filepath.Join(r.config.Output, fmt.Sprintf("%s-%d", r.name, gtime.Millisecond()))
+    if runtime.GOOS == "windows" {
+        binary += ".exe"
+    }
+    start := gtime.Millisecond()
+    if output, err := r.build(binary); err != nil {
+        glog.Printfln("grun: build failed: %s\n%s", err.Error(), output)
+        if pid := r.serverPid.Val(); pid > 0 {
+            gproc.Send(pid, []byte(gACTION_ERROR + r.token + ":" + output), gSERVER_GPROC_GROUP)
+        }
+        return
+    }
+    glog.Printfln("grun: build finished in %d ms", gtime.Millisecond() - start)
+    r.binaries = append(r.binaries, binary)
+    r.restart(binary)
+    // 删除旧的可执行文件
+    for len(r.binaries) > gDEFAULT_KEEP_BIN {
+        gfile.Remove(r.binaries[0])
+        r.binaries = r.binaries[1:]
+    }
+}
+
+// 执行go build，返回编译输出内容
+func (r *Runner) build(binary string) (string, error) {
+    path, err := exec.LookPath("go")
+    if err != nil {
+        return "", err
+    }
+    args := append([]string{"build", "-o", binary}, r.config.BuildArgs...)
+    args  = append(args, r.config.Main)
+    buffer  := bytes.NewBuffer(nil)
+    p       := gproc.NewProcess(path, args)
+    p.Dir    = r.config.Path
+    p.Stdout = buffer
+    p.Stderr = buffer
+    err      = p.Run()
+    return buffer.String(), err
+}
+
+// 使用新的可执行文件重启程序：Web Server进程平滑重启，其他程序结束后重新启动
+func (r *Runner) restart(binary string) {
+    if pid := r.serverPid.Val(); pid > 0 {
+        if err := gproc.Send(pid, []byte(gACTION_RESTART + r.token + ":" + binary), gSERVER_GPROC_GROUP); err == nil {
+            glog.Printfln("grun: server %d restarting", pid)
+            return
+        }
+        // 服务进程已经退出，使用新的进程启动
+        r.serverPid.Set(0)
+    }
+    r.stop()
+    r.start(binary)
+}
+
+// 启动程序进程
+func (r *Runner) start(binary string) {
+    env := append(os.Environ(),
+        fmt.Sprintf("%s=%d", gPID_ENVKEY,    gproc.Pid()),
+        fmt.Sprintf("%s=%s", gTOKEN_ENVKEY,  r.token),
+        fmt.Sprintf("%s=%s", gOUTPUT_ENVKEY, r.config.Output),
+    )
+    p   := gproc.NewProcess(binary, r.config.Args, env)
+    p.Dir  = r.config.Path
+    // 程序作为独立的gproc主进程运行，否则ghttp服务进程会将grun当做需要通知退出的父进程
+    p.PPid = 0
+    pid, err := p.Start()
+    if err != nil {
+        glog.Printfln("grun: start process failed: %s", err.Error())
+        return
+    }
+    glog.Printfln("grun: process %d started", pid)
+    exited   := make(chan struct{})
+    r.process = p
+    r.exited  = exited
+    go func() {
+        p.Wait()
+        close(exited)
+    }()
+}
+
+// 结束grun直接启动的程序进程(如果仍在运行)
+func (r *Runner) stop() {
+    if r.process == nil {
+        return
+    }
+    select {
+        case <- r.exited:
+        default:
+            r.process.Kill()
+            <- r.exited
+    }
+    r.process = nil
+}
+
+// 生成随机的通信令牌
+func newToken() string {
+    b := make([]byte, 16)
+    rand.Read(b)
+    return hex.EncodeToString(b)
+}
@@ -0,0 +1,248 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// HTTP客户端链式请求构造.
+
+package ghttp
+
+import (
+    "io"
+    "bytes"
+    "errors"
+    "context"
+    "strings"
+    "net/url"
+    "net/http"
+    "mime/multipart"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/encoding/gjson"
+)
+
+// 链式请求构造对象，通过c.R()创建，例如：
+// c.R().SetQuery(g.Map{"page" : 1}).SetJSON(user).SetHeader("X-Token", token).Post(url)
+type ClientRequest struct {
+    client      *Client           // 所属客户端
+    header      http.Header       // 本次请求的HEADER(优先于客户端的HEADER设置)
+    query       url.Values        // GET参数
+    form        url.Values        // 表单参数
+    files       []clientFile      // 上传文件
+    body        []byte            // 请求内容
+    contentType string            // 请求内容类型
+    ctx         context.Context   // 请求上下文
+    err         error             // 构造过程中产生的错误，在执行请求时返回
+}
+
+// 上传文件项
+type clientFile struct {
+    field    string    // 表单名称
+    filename string    // 文件名称
+    reader   io.Reader // 文件内容
+}
+
+// 创建链式请求构造对象
+func (c *Client) R() *ClientRequest {
+    return &ClientRequest {
+        client : c,
+        header : make(http.Header),
+        query  : make(url.Values),
+        form   : make(url.Values),
+        ctx    : c.ctx,
+    }
+}
+
+// 设置HEADER
+func (r *ClientRequest) SetHeader(key, value string) *ClientRequest {
+    r.header.Set(key, value)
+    return r
+}
+
+// 批量设置HEADER
+func (r *ClientRequest) SetHeaders(headers map[string]string) *ClientRequest {
+    for k, v := range headers {
+        r.header.Set(k, v)
+    }
+    return r
+}
+
+// 设置GET参数，参数值会转换为字符串，[]string/[]interface{}类型的参数值将作为同名的多个参数
+func (r *ClientRequest) SetQuery(params map[string]interface{}) *ClientRequest {
+    for k, v := range params {
+        r.query[k] = clientParamValues(v)
+    }
+    return r
+}
+
+// 设置单个GET参数
+func (r *ClientRequest) SetQueryParam(key, value string) *ClientRequest {
+    r.query.Set(key, value)
+    return r
+}
+
+// 设置表单参数，有上传文件时使用multipart/form-data提交，否则使用application/x-www-form-urlencoded提交
+func (r *ClientRequest) SetForm(params map[string]interface{}) *ClientRequest {
+    for k, v := range params {
+        r.form[k] = clientParamValues(v)
+    }
+    return r
+}
+
+// 将参数值转换为字符串数组
+func clientParamValues(v interface{}) []string {
+    switch v.(type) {
+        case []string, []interface{}:
+            return gconv.Strings(v)
+    }
+    return []string{gconv.String(v)}
+}
+
+// 设置单个表单参数
+func (r *ClientRequest) SetFormField(key, value string) *ClientRequest {
+    r.form.Set(key, value)
+    return r
+}
+
+// 添加上传文件，文件内容从reader读取，请求使用multipart/form-data提交
+func (r *ClientRequest) SetFile(field, filename string, reader io.Reader) *ClientRequest {
+    r.files = append(r.files, clientFile{field, filename, reader})
+    return r
+}
+
+// 设置JSON请求内容，参数为string/[]byte时直接作为请求内容，其他类型进行JSON编码
+func (r *ClientRequest) SetJSON(v interface{}) *ClientRequest {
+    switch value := v.(type) {
+        case string:
+            r.body = []byte(value)
+        case []byte:
+            r.body = value
+        default:
+            if b, err := gjson.Encode(v); err == nil {
+                r.body = b
+            } else {
+                r.err  = err
+            }
+    }
+    r.contentType = "application/json"
+    return r
+}
+
+// 设置请求内容及内容类型
+func (r *ClientRequest) SetBody(body []byte, contentType...string) *ClientRequest {
+    r.body = body
+    if len(contentType) > 0 {
+        r.contentType = contentType[0]
+    }
+    return r
+}
+
+// 设置请求上下文，用于控制本次请求的超时及取消
+func (r *ClientRequest) SetContext(ctx context.Context) *ClientRequest {
+    r.ctx = ctx
+    return r
+}
+
+// GET请求
+func (r *ClientRequest) Get(url string) (*ClientResponse, error) {
+    return r.Execute("GET", url)
+}
+
+// POST请求
+func (r *ClientRequest) Post(url string) (*ClientResponse, error) {
+    return r.Execute("POST", url)
+}
+
+// PUT请求
+func (r *ClientRequest) Put(url string) (*ClientResponse, error) {
+    return r.Execute("PUT", url)
+}
+
+// PATCH请求
+func (r *ClientRequest) Patch(url string) (*ClientResponse, error) {
+    return r.Execute("PATCH", url)
+}
+
+// DELETE请求
+func (r *ClientRequest) Delete(url string) (*ClientResponse, error) {
+    return r.Execute("DELETE", url)
+}
+
+// HEAD请求
+func (r *ClientRequest) Head(url string) (*ClientResponse, error) {
+    return r.Execute("HEAD", url)
+}
+
+// OPTIONS请求
+func (r *ClientRequest) Options(url string) (*ClientResponse, error) {
+    return r.Execute("OPTIONS", url)
+}
+
+// 执行请求，与DoRequest使用相同的请求流程(客户端HEADER、账号密码、请求上下文及失败重试)
+func (r *ClientRequest) Execute(method, link string) (*ClientResponse, error) {
+    if r.err != nil {
+        return nil, r.err
+    }
+    link = r.client.getUrl(link)
+    if len(r.query) > 0 {
+        if strings.Contains(link, "?") {
+            link += "&" + r.query.Encode()
+        } else {
+            link += "?" + r.query.Encode()
+        }
+    }
+    body, contentType, err := r.buildBody()
+    if err != nil {
+        return nil, err
+    }
+    req, err := http.NewRequest(strings.ToUpper(method), link, bytes.NewReader(body))
+    if err != nil {
+        return nil, err
+    }
+    // 请求内容类型需要优先于客户端的HEADER设置，但是可以通过SetHeader覆盖
+    header := make(http.Header, len(r.header) + 1)
+    if contentType != "" {
+        header.Set("Content-Type", contentType)
+    }
+    for k, v := range r.header {
+        header[k] = v
+    }
+    if r.ctx != nil {
+        req = req.WithContext(r.ctx)
+    }
+    return r.client.doRequest(req, header)
+}
+
+// 构造请求内容，返回请求内容及内容类型
+func (r *ClientRequest) buildBody() ([]byte, string, error) {
+    if len(r.files) > 0 {
+        buffer := bytes.NewBuffer(nil)
+        writer := multipart.NewWriter(buffer)
+        for k, values := range r.form {
+            for _, v := range values {
+                if err := writer.WriteField(k, v); err != nil {
+                    return nil, "", err
+                }
+            }
+        }
+        for _, f := range r.files {
+            if f.reader == nil {
+                return nil, "", errors.New("nil reader for upload file: " + f.field)
+            }
+            w, err := writer.CreateFormFile(f.field, f.filename)
+            if err != nil {
+                return nil, "", err
+            }
+            if _, err := io.Copy(w, f.reader); err != nil {
+                return nil, "", err
+            }
+        }
+        if err := writer.Close(); err != nil {
+            return nil, "", err
+        }
+        return buffer.Bytes(), writer.FormDataContentType(), nil
+    }
+    if len(r.form) > 0 {
+        return []byte(r.form.Encode()), "application/x-www-form-urlencoded", nil
+    }
+    return r.body, r.contentType, nil
+}
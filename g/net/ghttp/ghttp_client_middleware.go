@@ -0,0 +1,111 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// HTTP客户端请求中间件及熔断器.
+
+package ghttp
+
+import (
+    "sync"
+    "time"
+    "errors"
+    "net/http"
+    "github.com/eapache/go-resiliency/breaker"
+)
+
+// 客户端请求处理方法
+type ClientHandlerFunc func(req *http.Request) (*http.Response, error)
+
+// 客户端请求中间件，可以在请求前后进行处理(例如：签名、日志、监控及链路追踪)，
+// 通过调用next执行后续的中间件及请求，不调用next时可以直接返回结果(例如：缓存)。
+type ClientMiddleware func(req *http.Request, next ClientHandlerFunc) (*http.Response, error)
+
+// 熔断器打开时的请求错误，请求不会发送到服务端
+type ClientBreakerOpenError struct {
+    Host string // 熔断的主机
+}
+
+func (e *ClientBreakerOpenError) Error() string {
+    return "circuit breaker is open for host: " + e.Host
+}
+
+// 按照主机管理的熔断器
+type clientBreakers struct {
+    mu               sync.Mutex
+    errorThreshold   int                         // 熔断器打开需要的连续错误次数
+    successThreshold int                         // 熔断器半开状态下关闭需要的连续成功次数
+    timeout          time.Duration               // 熔断器打开后进入半开状态的等待时间
+    items            map[string]*breaker.Breaker // 熔断器，键名为主机(包含端口)
+}
+
+// 表示服务端错误的状态码(5xx)，用于熔断器统计
+var errClientServerStatus = errors.New("server error status")
+
+// 添加请求中间件，中间件在每次请求(包括重试)时执行，按照添加顺序嵌套执行，例如：
+// c.Use(func(req *http.Request, next ghttp.ClientHandlerFunc) (*http.Response, error) {
+//     req.Header.Set("X-Sign", sign(req))
+//     return next(req)
+// })
+func (c *Client) Use(middlewares...ClientMiddleware) {
+    c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// 开启按照主机的熔断器：连续errorThreshold次请求失败(请求出错或者5xx状态码)后熔断器打开，
+// 打开期间的请求直接返回*ClientBreakerOpenError错误；timeout时间后进入半开状态，
+// 半开状态下连续successThreshold次请求成功后关闭，出现失败时重新打开。
+func (c *Client) SetBreaker(errorThreshold, successThreshold int, timeout time.Duration) {
+    c.breakers = &clientBreakers {
+        errorThreshold   : errorThreshold,
+        successThreshold : successThreshold,
+        timeout          : timeout,
+        items            : make(map[string]*breaker.Breaker),
+    }
+}
+
+// 执行中间件及请求(单次请求，不包括重试)
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+    handler := ClientHandlerFunc(c.doWithBreaker)
+    for i := len(c.middlewares) - 1; i >= 0; i-- {
+        middleware, next := c.middlewares[i], handler
+        handler = func(req *http.Request) (*http.Response, error) {
+            return middleware(req, next)
+        }
+    }
+    return handler(req)
+}
+
+// 执行请求，开启熔断器时通过请求主机的熔断器执行
+func (c *Client) doWithBreaker(req *http.Request) (*http.Response, error) {
+    if c.breakers == nil {
+        return c.Do(req)
+    }
+    var resp *http.Response
+    var err  error
+    e := c.breakers.get(req.URL.Host).Run(func() error {
+        if resp, err = c.Do(req); err != nil {
+            return err
+        }
+        if resp.StatusCode >= http.StatusInternalServerError {
+            return errClientServerStatus
+        }
+        return nil
+    })
+    if e == breaker.ErrBreakerOpen {
+        return nil, &ClientBreakerOpenError{Host : req.URL.Host}
+    }
+    return resp, err
+}
+
+// 获取/创建主机对应的熔断器
+func (b *clientBreakers) get(host string) *breaker.Breaker {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    if v, ok := b.items[host]; ok {
+        return v
+    }
+    v := breaker.New(b.errorThreshold, b.successThreshold, b.timeout)
+    b.items[host] = v
+    return v
+}
@@ -8,29 +8,53 @@
 package ghttp
 
 import (
+    "os"
+    "io"
+    "net"
     "time"
     "bytes"
     "strings"
+    "context"
     "net/http"
     "mime/multipart"
-    "os"
-    "io"
 )
 
 // http客户端
 type Client struct {
-    http.Client                // 底层http client对象
-    header   map[string]string // HEADER信息Map
-    authUser string            // HTTP基本权限设置：名称
-    authPass string            // HTTP基本权限设置：密码
+    http.Client                        // 底层http client对象
+    header        map[string]string    // HEADER信息Map
+    authUser      string               // HTTP基本权限设置：名称
+    authPass      string               // HTTP基本权限设置：密码
+    prefix        string               // 请求地址前缀，请求地址不包含协议时自动添加
+    ctx           context.Context      // 请求上下文，用于控制单次请求的超时及取消
+    retryCount    int                  // 请求失败时的重试次数
+    retryInterval time.Duration        // 第一次重试的等待时间，之后每次重试翻倍(指数退避)
+    retryMax      time.Duration        // 重试等待时间上限
+    retryStatus   map[int]bool         // 需要重试的返回状态码
+    retryFunc     func(resp *http.Response, err error) bool // 自定义重试条件
+    retryAll      bool                 // 是否重试所有请求(包括非幂等请求)
+    middlewares   []ClientMiddleware   // 请求中间件，按照添加顺序执行
+    breakers      *clientBreakers      // 按照主机的熔断器(开启熔断时有效)
 }
 
-// http客户端对象指针
+// 默认的http客户端，用于包方法(ghttp.Get等)，多次请求之间复用连接
+var defaultClient = NewClient()
+
+// http客户端对象指针，默认开启连接复用(keep-alive)，并使用环境变量(HTTP_PROXY/HTTPS_PROXY/NO_PROXY)中的代理设置
 func NewClient() (*Client) {
     return &Client{
         Client : http.Client {
             Transport: &http.Transport {
-                DisableKeepAlives: true,
+                Proxy                 : http.ProxyFromEnvironment,
+                DialContext           : (&net.Dialer {
+                    Timeout   : gCLIENT_DIAL_TIMEOUT,
+                    KeepAlive : gCLIENT_KEEP_ALIVE,
+                }).DialContext,
+                MaxIdleConns          : gCLIENT_MAX_IDLE_CONNS,
+                MaxIdleConnsPerHost   : gCLIENT_MAX_IDLE_CONNS_PER_HOST,
+                IdleConnTimeout       : gCLIENT_IDLE_CONN_TIMEOUT,
+                TLSHandshakeTimeout   : gCLIENT_TLS_HANDSHAKE_TIMEOUT,
+                ExpectContinueTimeout : time.Second,
             },
         },
         header : make(map[string]string),
@@ -42,6 +66,19 @@ func (c *Client) SetHeader(key, value string) {
     c.header[key] = value
 }
 
+// 设置请求地址前缀，例如：http://127.0.0.1:8199，设置后请求时可以只传递URI
+func (c *Client) SetPrefix(prefix string) {
+    c.prefix = strings.TrimRight(prefix, "/")
+}
+
+// 获取完整的请求地址
+func (c *Client) getUrl(url string) string {
+    if c.prefix != "" && !strings.Contains(url, "://") {
+        return c.prefix + "/" + strings.TrimLeft(url, "/")
+    }
+    return url
+}
+
 // 设置请求过期时间
 func (c *Client) SetTimeOut(t time.Duration)  {
     c.Timeout = t
@@ -67,6 +104,7 @@ func (c *Client) Put(url, data string) (*ClientResponse, error) {
 // 如果服务端对Content-Type有要求，可使用Client对象进行请求，单独设置相关属性。
 // 支持文件上传，需要字段格式为：FieldName=@file:
 func (c *Client) Post(url, data string) (*ClientResponse, error) {
+    url = c.getUrl(url)
     var req *http.Request
     if strings.Contains(data, "@file:") {
         buffer := new(bytes.Buffer)
@@ -105,24 +143,7 @@ func (c *Client) Post(url, data string) (*ClientResponse, error) {
             req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
         }
     }
-    // 自定义header
-    if len(c.header) > 0 {
-        for k, v := range c.header {
-            req.Header.Set(k, v)
-        }
-    }
-    // HTTP账号密码
-    if len(c.authUser) > 0 {
-        req.SetBasicAuth(c.authUser, c.authPass)
-    }
-    // 执行请求
-    resp, err := c.Do(req)
-    if err != nil {
-        return nil, err
-    }
-    r := &ClientResponse{}
-    r.Response = *resp
-    return r, nil
+    return c.doRequest(req)
 }
 
 // DELETE请求
@@ -155,18 +176,37 @@ func (c *Client) DoRequest(method, url string, data []byte) (*ClientResponse, er
     if strings.Compare("POST", strings.ToUpper(method)) == 0 {
         return c.Post(url, string(data))
     }
-    req, err := http.NewRequest(strings.ToUpper(method), url, bytes.NewReader(data))
+    req, err := http.NewRequest(strings.ToUpper(method), c.getUrl(url), bytes.NewReader(data))
     if err != nil {
         return nil, err
     }
+    return c.doRequest(req)
+}
+
+// 执行请求，设置自定义header、账号密码及请求上下文，并按照重试设置进行失败重试；
+// 参数header为本次请求的header，优先于客户端的header设置
+func (c *Client) doRequest(req *http.Request, header...http.Header) (*ClientResponse, error) {
     // 自定义header
     if len(c.header) > 0 {
         for k, v := range c.header {
             req.Header.Set(k, v)
         }
     }
+    if len(header) > 0 {
+        for k, v := range header[0] {
+            req.Header[k] = v
+        }
+    }
+    // HTTP账号密码
+    if len(c.authUser) > 0 {
+        req.SetBasicAuth(c.authUser, c.authPass)
+    }
+    // 请求本身设置的上下文优先
+    if c.ctx != nil && req.Context() == context.Background() {
+        req = req.WithContext(c.ctx)
+    }
     // 执行请求
-    resp, err := c.Do(req)
+    resp, err := c.doWithRetry(req)
     if err != nil {
         return nil, err
     }
@@ -214,5 +254,5 @@ func Trace(url, data string) (*ClientResponse, error) {
 
 // 该方法支持二进制提交数据
 func DoRequest(method, url string, data []byte) (*ClientResponse, error) {
-    return NewClient().DoRequest(method, url, data)
+    return defaultClient.DoRequest(method, url, data)
 }
@@ -8,26 +8,99 @@
 package ghttp
 
 import (
+    "fmt"
+    "strings"
     "io/ioutil"
     "net/http"
+    "encoding/xml"
+    "gitee.com/johng/gf/g/encoding/gjson"
 )
 
 // 客户端请求结果对象
 type ClientResponse struct {
     http.Response
+    body []byte // 读取后的返回内容(返回内容只能读取一次，因此需要缓存)
+    read bool   // 返回内容是否已读取
 }
 
-// 获取返回的数据
+// 非2xx状态码的请求结果错误，通过ClientResponse.Error获取
+type ClientResponseError struct {
+    StatusCode int    // 返回状态码
+    Status     string // 返回状态，例如：404 Not Found
+    Body       []byte // 返回内容
+}
+
+func (e *ClientResponseError) Error() string {
+    return fmt.Sprintf("http status %s: %s", e.Status, e.Body)
+}
+
+// 获取返回的数据，可以多次调用
 func (r *ClientResponse) ReadAll() []byte {
+    if r.read {
+        return r.body
+    }
+    r.read = true
+    if r.Body == nil {
+        return nil
+    }
     body, err := ioutil.ReadAll(r.Body)
+    r.Body.Close()
     if err != nil {
         return nil
     }
+    r.body = body
     return body
 }
 
+// 获取返回的数据(字符串)
+func (r *ClientResponse) ReadAllString() string {
+    return string(r.ReadAll())
+}
+
+// 将返回的JSON数据解析为gjson.Json对象
+func (r *ClientResponse) ReadJson() (*gjson.Json, error) {
+    return gjson.DecodeToJson(r.ReadAll())
+}
+
+// 将返回的数据解析到给定的变量中(注意参数为指针)，返回内容类型为XML时按照XML解析，否则按照JSON解析
+func (r *ClientResponse) Struct(v interface{}) error {
+    if strings.Contains(r.Header.Get("Content-Type"), "xml") {
+        return xml.Unmarshal(r.ReadAll(), v)
+    }
+    return gjson.DecodeTo(r.ReadAll(), v)
+}
+
+// 是否请求成功(2xx状态码)
+func (r *ClientResponse) IsSuccess() bool {
+    return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// 是否为客户端错误(4xx状态码)
+func (r *ClientResponse) IsClientError() bool {
+    return r.StatusCode >= 400 && r.StatusCode < 500
+}
+
+// 是否为服务端错误(5xx状态码)
+func (r *ClientResponse) IsServerError() bool {
+    return r.StatusCode >= 500
+}
+
+// 非2xx状态码时返回*ClientResponseError错误(包含返回内容)，否则返回nil
+func (r *ClientResponse) Error() error {
+    if r.IsSuccess() {
+        return nil
+    }
+    return &ClientResponseError {
+        StatusCode : r.StatusCode,
+        Status     : r.Status,
+        Body       : r.ReadAll(),
+    }
+}
+
 // 关闭返回的HTTP链接
 func (r *ClientResponse) Close()  {
     r.Response.Close = true
-    r.Body.Close()
-}
\ No newline at end of file
+    if r.Body != nil {
+        r.Body.Close()
+    }
+}
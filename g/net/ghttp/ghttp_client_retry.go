@@ -0,0 +1,124 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// HTTP客户端请求失败重试.
+
+package ghttp
+
+import (
+    "io"
+    "time"
+    "io/ioutil"
+    "net/http"
+)
+
+const (
+    gCLIENT_RETRY_MAX_INTERVAL = 30*time.Second // 默认的重试等待时间上限
+)
+
+// 默认需要重试的返回状态码
+var defaultClientRetryStatus = []int {
+    http.StatusTooManyRequests,
+    http.StatusBadGateway,
+    http.StatusServiceUnavailable,
+    http.StatusGatewayTimeout,
+}
+
+// 设置请求失败重试，count为最大重试次数，interval为第一次重试的等待时间，之后每次重试等待时间翻倍(指数退避)；
+// 请求出错(例如连接失败)或者返回给定的状态码时进行重试，不传递状态码时默认为429、502、503、504。
+// 默认只重试幂等的请求(GET、HEAD、OPTIONS、TRACE、PUT、DELETE，或者带有Idempotency-Key头的请求)，
+// 其他请求(例如POST、PATCH)重试可能导致重复执行，需要通过SetRetryAllMethods开启。
+func (c *Client) SetRetry(count int, interval time.Duration, status...int) {
+    c.retryCount    = count
+    c.retryInterval = interval
+    if c.retryMax == 0 {
+        c.retryMax = gCLIENT_RETRY_MAX_INTERVAL
+    }
+    if len(status) == 0 {
+        status = defaultClientRetryStatus
+    }
+    c.retryStatus = make(map[int]bool, len(status))
+    for _, v := range status {
+        c.retryStatus[v] = true
+    }
+}
+
+// 设置重试等待时间上限，默认为30秒
+func (c *Client) SetRetryMaxInterval(max time.Duration) {
+    c.retryMax = max
+}
+
+// 设置自定义重试条件，返回true时进行重试，设置后替代默认的重试条件(请求出错或者返回指定的状态码)，
+// 非幂等请求的限制仍然有效
+func (c *Client) SetRetryCondition(f func(resp *http.Response, err error) bool) {
+    c.retryFunc = f
+}
+
+// 设置是否重试所有请求(包括POST、PATCH等非幂等请求)，默认只重试幂等请求
+func (c *Client) SetRetryAllMethods(enabled bool) {
+    c.retryAll = enabled
+}
+
+// 判断请求是否幂等(可以安全地重复执行)
+func isIdempotentRequest(req *http.Request) bool {
+    switch req.Method {
+        case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+            return true
+    }
+    return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
+
+// 判断请求结果是否需要重试
+func (c *Client) needRetry(req *http.Request, resp *http.Response, err error) bool {
+    if !c.retryAll && !isIdempotentRequest(req) {
+        return false
+    }
+    if c.retryFunc != nil {
+        return c.retryFunc(resp, err)
+    }
+    if err != nil {
+        // 熔断器打开时快速失败，不进行重试
+        if _, ok := err.(*ClientBreakerOpenError); ok {
+            return false
+        }
+        return true
+    }
+    return c.retryStatus[resp.StatusCode]
+}
+
+// 执行请求，失败时按照重试设置重试；请求上下文被取消或者超时时不再重试
+func (c *Client) doWithRetry(req *http.Request) (*http.Response, error) {
+    interval := c.retryInterval
+    for i := 0; ; i++ {
+        resp, err := c.send(req)
+        if i >= c.retryCount || !c.needRetry(req, resp, err) {
+            return resp, err
+        }
+        // 请求内容需要能够重新读取才能重试
+        if req.Body != nil && req.Body != http.NoBody {
+            if req.GetBody == nil {
+                return resp, err
+            }
+            body, e := req.GetBody()
+            if e != nil {
+                return resp, err
+            }
+            req.Body = body
+        }
+        // 释放失败请求的连接，以便复用
+        if resp != nil {
+            io.Copy(ioutil.Discard, resp.Body)
+            resp.Body.Close()
+        }
+        select {
+            case <- req.Context().Done():
+                return nil, req.Context().Err()
+            case <- time.After(interval):
+        }
+        if interval *= 2; interval > c.retryMax {
+            interval = c.retryMax
+        }
+    }
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 内存测试客户端(不需要监听端口).
+
+package ghttp
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "net/http/cookiejar"
+)
+
+const (
+    gTEST_CLIENT_PREFIX      = "http://127.0.0.1" // 测试客户端默认的请求地址前缀
+    gTEST_CLIENT_REMOTE_ADDR = "127.0.0.1:10000"  // 测试客户端请求的客户端地址
+)
+
+// 内存请求处理对象，将客户端请求直接交给Server处理，不经过网络
+type testTransport struct {
+    server *Server
+}
+
+// 创建一个直接在内存中请求Server的客户端，不需要调用s.Start()监听端口，
+// 请求会完整经过Server的处理流程(事件回调、路由、Cookie/Session、状态码回调等)，
+// 客户端会自动保存返回的Cookie，因此多次请求之间可以保持Session；
+// 请求地址可以只传递URI，例如：NewTestClient(s).Get("/user/1")；
+// 第一次请求时Server完成请求处理初始化，此后与Server运行时一样不能再注册路由。
+func NewTestClient(s *Server) *Client {
+    c := NewClient()
+    c.Transport = &testTransport{server : s}
+    c.Jar, _    = cookiejar.New(nil)
+    c.SetPrefix(gTEST_CLIENT_PREFIX)
+    return c
+}
+
+// 实现http.RoundTripper接口
+func (t *testTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+    if err := t.server.prepareServe(); err != nil {
+        return nil, err
+    }
+    // RoundTripper不能修改传入的请求对象，因此这里复制一份交给Server处理
+    r       := new(http.Request)
+    *r       = *req
+    u       := *req.URL
+    r.URL    = &u
+    r.Header = make(http.Header, len(req.Header))
+    for k, v := range req.Header {
+        r.Header[k] = append([]string(nil), v...)
+    }
+    if r.Body == nil {
+        r.Body = http.NoBody
+    }
+    // 通过SetHeader设置的Host头作为请求域名，以便测试域名路由
+    if host := r.Header.Get("Host"); host != "" {
+        r.Host = host
+        r.Header.Del("Host")
+    }
+    if r.Host == "" {
+        r.Host = u.Host
+    }
+    r.RequestURI = u.RequestURI()
+    r.RemoteAddr = gTEST_CLIENT_REMOTE_ADDR
+    w := httptest.NewRecorder()
+    t.server.ServeHTTP(w, r)
+    resp := w.Result()
+    resp.Request = req
+    return resp, nil
+}
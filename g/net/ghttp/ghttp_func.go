@@ -8,7 +8,8 @@ package ghttp
 
 import "gitee.com/johng/gf/g/encoding/gurl"
 
-// 构建请求参数，将参数进行urlencode编码
+// 构建请求参数，将参数进行urlencode编码，
+// 客户端请求参数推荐使用链式请求构造对象设置，例如：c.R().SetQuery(params).Get(url)
 func BuildParams(params map[string]string) string {
     var s string
     for k, v := range params {
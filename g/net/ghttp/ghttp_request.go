@@ -7,9 +7,12 @@
 package ghttp
 
 import (
+    "net"
+    "bytes"
     "io/ioutil"
     "net/http"
     "gitee.com/johng/gf/g/encoding/gjson"
+    "gitee.com/johng/gf/g/crypto/gjwt"
     "gitee.com/johng/gf/g/container/gtype"
     "gitee.com/johng/gf/g/util/gregex"
     "gitee.com/johng/gf/g/os/gtime"
@@ -25,7 +28,8 @@ type Request struct {
     queryVars     map[string][]string // GET参数
     routerVars    map[string][]string // 路由解析参数
     exit          *gtype.Bool         // 是否退出当前请求流程执行
-    Id            int                 // 请求id(唯一)
+    id            int                 // 请求序号(服务内唯一)，用于关联请求相关的内部对象
+    requestId     string              // 请求标识(X-Request-Id)，通过Id方法获取
     Server        *Server             // 请求关联的服务器对象
     Cookie        *Cookie             // 与当前请求绑定的Cookie对象(并发安全)
     Session       *Session            // 与当前请求绑定的Session对象(并发安全)
@@ -36,7 +40,17 @@ type Request struct {
     Param         interface{}         // 开发者自定义参数
     parsedHost    *gtype.String       // 解析过后不带端口号的服务器域名名称
     clientIp      *gtype.String       // 解析过后的客户端IP地址
+    domainMatches []*domainMatchItem  // 请求域名匹配的域名及域名模式(不包括默认域名)
     isFileRequest bool                // 是否为静态文件请求(非服务请求，当静态文件存在时，优先级会被服务请求高，被识别为文件请求)
+    uploadConfig  *UploadConfig       // 当前请求的文件上传配置(为nil时使用Server配置)
+    uploadFiles   map[string][]*UploadFile // 上传文件(按照表单名称)
+    uploadError   error               // 上传文件解析错误
+    rawRead       bool                // 是否已经读取原始请求内容
+    rawBody       []byte              // 原始请求内容(GetRaw读取后缓存)
+    bodyArrays    map[string][]string // JSON/XML/YAML请求内容中的数组参数(用于struct绑定)
+    error         interface{}         // 请求处理过程中产生的panic错误
+    jwtClaims     gjwt.Claims         // JWT认证通过的令牌声明
+    locale        string              // 当前请求的语言(GetLocale时识别)
 }
 
 // 创建一个Request对象
@@ -46,8 +60,9 @@ func newRequest(s *Server, r *http.Request, w http.ResponseWriter) *Request {
         parsedPost : gtype.NewBool(),
         queryVars  : make(map[string][]string),
         routerVars : make(map[string][]string),
+        uploadFiles : make(map[string][]*UploadFile),
         exit       : gtype.NewBool(),
-        Id         : s.servedCount.Add(1),
+        id         : s.servedCount.Add(1),
         Server     : s,
         Request    : *r,
         Response   : newResponse(s, w),
@@ -79,10 +94,15 @@ func (r *Request) Get(key string, def ... string) string {
     return r.GetRequestString(key, def...)
 }
 
-// 获取原始请求输入字符串，注意：只能获取一次，读完就没了
+// 获取原始请求输入内容，读取后会缓存，可以多次获取
 func (r *Request) GetRaw() []byte {
-    result, _ := ioutil.ReadAll(r.Body)
-    return result
+    if !r.rawRead {
+        r.rawRead    = true
+        r.rawBody, _ = ioutil.ReadAll(r.Body)
+        // 重置请求体，以便后续的表单解析仍然可以读取
+        r.Body = ioutil.NopCloser(bytes.NewReader(r.rawBody))
+    }
+    return r.rawBody
 }
 
 // 获取原始json请求输入字符串，并解析为json对象
@@ -168,17 +188,28 @@ func (r *Request) IsAjaxRequest() bool {
 func (r *Request) GetClientIp() string {
     ip := r.clientIp.Val()
     if len(ip) == 0 {
-        array, _ := gregex.MatchString(`(.+):(\d+)`, r.RemoteAddr)
-        if len(array) > 1 {
-            ip = array[1]
-        } else {
-            ip = r.RemoteAddr
+        ip = r.GetRemoteIp()
+        // 只有直接连接的客户端为可信代理时才使用代理Header中的客户端IP，防止伪造
+        if r.Server.isTrustedProxy(ip) {
+            ip = r.getForwardedIp(ip)
         }
         r.clientIp.Set(ip)
     }
     return ip
 }
 
+// 获得直接连接的客户端IP(经过代理时为代理服务器的IP)
+func (r *Request) GetRemoteIp() string {
+    if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+        return host
+    }
+    array, _ := gregex.MatchString(`(.+):(\d+)`, r.RemoteAddr)
+    if len(array) > 1 {
+        return array[1]
+    }
+    return r.RemoteAddr
+}
+
 // 获得来源URL地址
 func (r *Request) GetReferer() string {
     return r.Header.Get("Referer")
@@ -0,0 +1,37 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 请求语言识别及翻译.
+
+package ghttp
+
+import (
+    "gitee.com/johng/gf/g/util/gi18n"
+)
+
+const (
+    gDEFAULT_LOCALE_NAME = "lang" // 指定请求语言的GET参数及Cookie名称
+)
+
+// 获取当前请求的语言，按照 GET参数lang -> Cookie lang -> Accept-Language头 的顺序，
+// 选择第一个有对应翻译文件的语言，都不匹配时返回gi18n的默认语言
+func (r *Request) GetLocale() string {
+    if r.locale == "" {
+        locales := []string{r.GetQueryString(gDEFAULT_LOCALE_NAME), r.Cookie.Get(gDEFAULT_LOCALE_NAME)}
+        locales  = append(locales, gi18n.ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+        r.locale = gi18n.Instance().Match(locales...)
+    }
+    return r.locale
+}
+
+// 设置当前请求的语言(仅对当前请求有效，需要保持时可以设置到名称为lang的Cookie中)
+func (r *Request) SetLocale(locale string) {
+    r.locale = gi18n.NormalizeLocale(locale)
+}
+
+// 按照当前请求的语言翻译指定的键名，参数同gi18n.T
+func (r *Request) T(key string, args...interface{}) string {
+    return gi18n.T(r.GetLocale(), key, args...)
+}
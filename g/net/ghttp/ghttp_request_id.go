@@ -0,0 +1,74 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 请求标识(X-Request-Id)处理.
+
+package ghttp
+
+import (
+    "strconv"
+    "strings"
+    "gitee.com/johng/gf/g/os/gtime"
+    "gitee.com/johng/gf/g/util/grand"
+)
+
+const (
+    gDEFAULT_REQUEST_ID_HEADER = "X-Request-Id" // 默认的请求标识Header名称
+    gREQUEST_ID_MAX_LENGTH     = 128            // 客户端传递的请求标识最大长度，超过时重新生成
+)
+
+// 开启请求标识中间件，每个请求在处理前从请求Header中获取请求标识(不存在或者不合法时自动生成)，
+// 并通过相同名称的Header返回给客户端，以便于跨服务的请求链路追踪；
+// 请求标识可以通过r.Id()获取，并可在access log中输出，header参数用于自定义Header名称，默认为X-Request-Id。
+func (s *Server) EnableRequestId(header...string) {
+    name := gDEFAULT_REQUEST_ID_HEADER
+    if len(header) > 0 && header[0] != "" {
+        name = header[0]
+    }
+    s.requestIdHeader.Set(name)
+}
+
+// 请求处理前的请求标识初始化(开启请求标识中间件时有效)
+func (r *Request) initRequestId() {
+    name := r.Server.requestIdHeader.Val()
+    if name == "" {
+        return
+    }
+    r.Response.Header().Set(name, r.Id())
+}
+
+// 获取当前请求的唯一标识，优先使用客户端传递的请求标识Header，不存在时自动生成
+func (r *Request) Id() string {
+    if r.requestId == "" {
+        name := r.Server.requestIdHeader.Val()
+        if name == "" {
+            name = gDEFAULT_REQUEST_ID_HEADER
+        }
+        if id := r.Header.Get(name); isValidRequestId(id) {
+            r.requestId = id
+        } else {
+            r.requestId = makeRequestId(r.id)
+        }
+    }
+    return r.requestId
+}
+
+// 生成请求标识，由时间戳、请求序号及随机字符串组成
+func makeRequestId(seq int) string {
+    return strings.ToUpper(strconv.FormatInt(gtime.Nanosecond(), 32) + strconv.FormatInt(int64(seq), 32) + grand.RandStr(6))
+}
+
+// 检查客户端传递的请求标识是否合法，只允许可见的ASCII字符，防止日志注入
+func isValidRequestId(id string) bool {
+    if id == "" || len(id) > gREQUEST_ID_MAX_LENGTH {
+        return false
+    }
+    for i := 0; i < len(id); i++ {
+        if id[i] < 0x21 || id[i] > 0x7e {
+            return false
+        }
+    }
+    return true
+}
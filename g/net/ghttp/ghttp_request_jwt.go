@@ -0,0 +1,126 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// JWT认证(无状态认证，适用于移动端等不使用Session的接口).
+
+package ghttp
+
+import (
+    "fmt"
+    "errors"
+    "strings"
+    "net/http"
+    "gitee.com/johng/gf/g/crypto/gjwt"
+)
+
+const (
+    gDEFAULT_JWT_LOOKUP    = "header:Authorization"      // 默认的令牌获取位置，Cookie及GET参数需要通过lookup参数显式开启
+    gJWT_ERROR_DESCRIPTION = "token verification failed" // 非令牌错误(例如吊销存储访问失败)对客户端展示的描述信息
+)
+
+var (
+    ErrJwtTokenMissing = errors.New("token is missing")
+)
+
+// 绑定JWT认证中间件，pattern参数同BindHandler，lookup为令牌获取位置，格式为"来源:名称"，多个使用","分隔，
+// 来源支持header、cookie、query，默认只从Authorization头获取，例如："header:Authorization,cookie:jwt"；
+// 注意：使用cookie时浏览器会自动携带令牌，需要自行做好CSRF防护(例如SameSite Cookie)；
+// 使用query时令牌会出现在访问日志、代理服务器日志及Referer头中，非必要(例如WebSocket握手)不建议使用；
+// 认证失败时返回401状态码(可通过BindStatusHandler自定义)，认证成功后可以通过r.GetJwtClaims获取令牌声明；
+// 认证作为路由规则在BeforeServe事件回调之前执行，不占用该pattern的事件回调注册，匹配的多个认证规则均需通过。
+func (s *Server) BindJwtAuth(pattern string, j *gjwt.Jwt, lookup...string) error {
+    return s.setRouteRule(gROUTE_RULE_JWT_AUTH, pattern, func(r *Request) {
+        if _, err := r.ParseJwt(j, lookup...); err != nil {
+            r.setJwtUnauthorized(err)
+            r.Exit()
+        }
+    })
+}
+
+// 域名下绑定JWT认证中间件
+func (d *Domain) BindJwtAuth(pattern string, j *gjwt.Jwt, lookup...string) error {
+    for domain, _ := range d.m {
+        if err := d.s.BindJwtAuth(pattern + "@" + domain, j, lookup...); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// 获取请求中的令牌，没有时返回空字符串
+func (r *Request) GetJwtToken(lookup...string) string {
+    rules := gDEFAULT_JWT_LOOKUP
+    if len(lookup) > 0 && lookup[0] != "" {
+        rules = lookup[0]
+    }
+    for _, rule := range strings.Split(rules, ",") {
+        array := strings.SplitN(strings.TrimSpace(rule), ":", 2)
+        if len(array) != 2 {
+            continue
+        }
+        token := ""
+        name  := strings.TrimSpace(array[1])
+        switch strings.ToLower(strings.TrimSpace(array[0])) {
+            case "header":
+                token = r.Header.Get(name)
+                if len(token) > 7 && strings.EqualFold(token[:7], "Bearer ") {
+                    token = token[7:]
+                }
+            case "cookie":
+                token = r.Cookie.Get(name)
+            case "query":
+                token = r.GetQueryString(name)
+        }
+        if token = strings.TrimSpace(token); token != "" {
+            return token
+        }
+    }
+    return ""
+}
+
+// 获取并校验请求中的令牌，校验成功后令牌声明将保存到请求中
+func (r *Request) ParseJwt(j *gjwt.Jwt, lookup...string) (gjwt.Claims, error) {
+    token := r.GetJwtToken(lookup...)
+    if token == "" {
+        return nil, ErrJwtTokenMissing
+    }
+    claims, err := j.Parse(token)
+    if err != nil {
+        return nil, err
+    }
+    r.jwtClaims = claims
+    return claims, nil
+}
+
+// 获取认证通过的令牌声明，未认证时返回nil
+func (r *Request) GetJwtClaims() gjwt.Claims {
+    return r.jwtClaims
+}
+
+// 返回401状态码，并按照RFC 6750设置WWW-Authenticate头；
+// 只有gjwt的令牌校验错误才会展示具体原因，其他错误(例如Redis访问失败)使用统一的描述信息，防止泄露内部信息
+func (r *Request) setJwtUnauthorized(err error) {
+    value := `Bearer realm="api"`
+    if err != ErrJwtTokenMissing {
+        description := gJWT_ERROR_DESCRIPTION
+        if isJwtTokenError(err) {
+            description = err.Error()
+        }
+        value += fmt.Sprintf(`, error="invalid_token", error_description="%s"`, description)
+    }
+    r.Response.Header().Set("WWW-Authenticate", value)
+    r.Response.WriteStatus(http.StatusUnauthorized)
+}
+
+// 判断是否为gjwt的令牌校验错误
+func isJwtTokenError(err error) bool {
+    switch err {
+        case gjwt.ErrInvalidToken, gjwt.ErrInvalidSignature, gjwt.ErrInvalidAlgorithm,
+             gjwt.ErrTokenExpired, gjwt.ErrTokenNotValidYet, gjwt.ErrInvalidIssuer,
+             gjwt.ErrInvalidAudience, gjwt.ErrInvalidType, gjwt.ErrTokenRevoked:
+            return true
+    }
+    return false
+}
@@ -7,7 +7,13 @@
 package ghttp
 
 import (
+    "bytes"
+    "strings"
+    "net/url"
+    "net/http"
+    "encoding/json"
     "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/encoding/gparser"
 )
 
 // 初始化POST请求参数
@@ -15,8 +21,21 @@ func (r *Request) initPost() {
     if !r.parsedPost.Val() {
         // 快速保存，尽量避免并发问题
         r.parsedPost.Set(true)
-        // MultiMedia表单请求解析允许最大使用内存：1GB
-        r.ParseMultipartForm(1024*1024*1024)
+        // 请求体大小限制
+        if max := r.getUploadConfig().MaxBodySize; max > 0 {
+            r.Body = http.MaxBytesReader(r.Response.Writer, r.Body, max)
+        }
+        // MultiMedia表单请求自行解析，上传文件超过内存限制时写入临时目录
+        // JSON/XML/YAML请求内容按照Content-Type解析为POST参数
+        contentType := r.Header.Get("Content-Type")
+        if strings.Contains(contentType, "multipart/form-data") {
+            r.parseMultipartForm()
+        } else if format := getBodyFormat(contentType); format != "" {
+            r.ParseForm()
+            r.parseEntityBody(format)
+        } else {
+            r.ParseForm()
+        }
     }
 }
 
@@ -146,5 +165,73 @@ func (r *Request) GetPostToStruct(object interface{}, mapping...map[string]strin
     for k, v := range r.GetPostMap() {
         params[k] = v
     }
+    // 结构化请求内容中的数组参数完整绑定
+    for k, v := range r.bodyArrays {
+        params[k] = v
+    }
     gconv.MapToStruct(params, object, tagmap)
-}
\ No newline at end of file
+    r.bindUploadFilesToStruct(object, tagmap)
+    r.recordApiRequest(object)
+}
+// 根据Content-Type获取结构化请求内容的数据格式(json/xml/yaml)，其他类型返回空字符串
+func getBodyFormat(contentType string) string {
+    mime := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+    switch {
+        case mime == "application/json", mime == "text/json", strings.HasSuffix(mime, "+json"):
+            return ENTITY_FORMAT_JSON
+        case mime == "application/xml", mime == "text/xml", strings.HasSuffix(mime, "+xml"):
+            return ENTITY_FORMAT_XML
+        case mime == "application/yaml", mime == "application/x-yaml", mime == "text/yaml", mime == "text/x-yaml":
+            return ENTITY_FORMAT_YAML
+    }
+    return ""
+}
+
+// 解析结构化请求内容，第一层键值对作为POST参数，数组作为同名的多个参数，
+// 嵌套的对象转换为JSON字符串；请求内容不是对象或者解析失败时忽略。
+func (r *Request) parseEntityBody(format string) {
+    data := r.GetRaw()
+    if len(data) == 0 {
+        return
+    }
+    m := (map[string]interface{})(nil)
+    if format == ENTITY_FORMAT_JSON {
+        // 数值使用json.Number保存，防止超过2^53的整数转换为float64时精度丢失
+        decoder := json.NewDecoder(bytes.NewReader(data))
+        decoder.UseNumber()
+        if err := decoder.Decode(&m); err != nil {
+            return
+        }
+    } else {
+        p, err := gparser.LoadContent(data, format)
+        if err != nil {
+            return
+        }
+        m = p.ToMap()
+    }
+    if m == nil {
+        return
+    }
+    if r.PostForm == nil {
+        r.PostForm = make(url.Values)
+    }
+    // XML内容会包含根节点，只有一个根节点时使用根节点下的内容
+    if format == ENTITY_FORMAT_XML && len(m) == 1 {
+        for _, v := range m {
+            if root, ok := v.(map[string]interface{}); ok {
+                m = root
+            }
+        }
+    }
+    for k, v := range m {
+        if array, ok := v.([]interface{}); ok {
+            r.PostForm[k] = gconv.Strings(array)
+            if r.bodyArrays == nil {
+                r.bodyArrays = make(map[string][]string)
+            }
+            r.bodyArrays[k] = r.PostForm[k]
+        } else {
+            r.PostForm[k] = []string{gconv.String(v)}
+        }
+    }
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package ghttp
+
+import (
+    "fmt"
+    "testing"
+)
+
+type entityBindUser struct {
+    Id     int64
+    Ids    []int
+    Scores []float64
+    Names  []string
+    Tags   []interface{}
+    Age    entityBindAge
+}
+
+type entityBindAge int
+
+func Test_Request_BindEntityBody(t *testing.T) {
+    s := GetServer("request-bind-entity")
+    s.BindHandler("/user", func(r *Request) {
+        user := new(entityBindUser)
+        r.GetRequestToStruct(user)
+        r.Response.Write(fmt.Sprintf("%d %v %v %v %v %d", user.Id, user.Ids, user.Scores, user.Names, user.Tags, user.Age))
+    })
+    s.BindHandler("/post", func(r *Request) {
+        user := new(entityBindUser)
+        r.GetPostToStruct(user)
+        r.Response.Write(fmt.Sprintf("%d %v", user.Id, user.Ids))
+    })
+    c     := NewTestClient(s)
+    cases := []struct {
+        url    string
+        body   string
+        expect string
+    }{
+        // 数组按照属性的元素类型转换，超过2^53的整数不丢失精度
+        {"/user", `{"id":12345678901234567,"ids":[1,2],"scores":[1.5,2],"names":["a","b"],"tags":["x",1],"age":18}`,
+            "12345678901234567 [1 2] [1.5 2] [a b] [x 1] 18"},
+        {"/user", `{"ids":3}`, "0 [3] [] [] [] 0"},
+        {"/post", `{"id":9007199254740993,"ids":["4","5"]}`, "9007199254740993 [4 5]"},
+    }
+    for _, v := range cases {
+        resp, err := c.R().SetBody([]byte(v.body), "application/json").Post(v.url)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if content := string(resp.ReadAll()); resp.StatusCode != 200 || content != v.expect {
+            t.Errorf("%s %s: expect %q, got %d %q", v.url, v.body, v.expect, resp.StatusCode, content)
+        }
+    }
+}
@@ -146,4 +146,5 @@ func (r *Request) GetQueryToStruct(object interface{}, mapping...map[string]stri
         params[k] = v
     }
     gconv.MapToStruct(params, object, tagmap)
+    r.recordApiRequest(object)
 }
\ No newline at end of file
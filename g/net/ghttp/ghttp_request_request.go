@@ -130,6 +130,14 @@ func (r *Request) GetRequestToStruct(object interface{}, mapping...map[string]st
     for k, v := range r.GetRequestMap() {
         params[k] = v
     }
+    // 结构化请求内容中的数组参数完整绑定(路由及GET参数优先)
+    for k, v := range r.bodyArrays {
+        if r.GetRouterArray(k) == nil && r.GetQuery(k) == nil {
+            params[k] = v
+        }
+    }
     gconv.MapToStruct(params, object, tagmap)
+    r.bindUploadFilesToStruct(object, tagmap)
+    r.recordApiRequest(object)
 }
 
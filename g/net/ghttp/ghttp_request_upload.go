@@ -0,0 +1,260 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 文件上传处理.
+
+package ghttp
+
+import (
+    "io"
+    "os"
+    "fmt"
+    "errors"
+    "reflect"
+    "strings"
+    "strconv"
+    "mime/multipart"
+    "net/textproto"
+    "gitee.com/johng/gf/g/os/gfile"
+    "gitee.com/johng/gf/g/os/gtime"
+    "gitee.com/johng/gf/g/util/grand"
+    "github.com/fatih/structs"
+)
+
+const (
+    gDEFAULT_UPLOAD_MAX_MEMORY  = 32 << 20 // 默认上传文件保存在内存中的最大大小(32MB)，超过后写入临时文件
+)
+
+// 文件上传配置；multipart表单由标准库解析，超过MaxMemory的文件写入系统临时目录(可通过TMPDIR环境变量修改)，
+// 由于MaxFileSize在解析完成后才进行检查，因此需要同时设置MaxBodySize限制单个请求写入临时文件的大小
+type UploadConfig struct {
+    MaxBodySize  int64    // 请求体最大大小(byte)，0表示不限制，读取请求体时超出即终止解析
+    MaxFileSize  int64    // 单个文件最大大小(byte)，0表示不限制，在请求体解析完成后检查
+    MaxMemory    int64    // 上传文件保存在内存中的最大总大小(byte)，超过后写入系统临时目录
+    AllowedExts  []string // 允许上传的文件扩展名，如：.jpg，为空表示不限制
+    AllowedMimes []string // 允许上传的文件MIME类型，如：image/png，支持image/*形式，为空表示不限制
+}
+
+// 上传文件对象
+type UploadFile struct {
+    Filename    string                // 客户端提交的文件名称
+    Size        int64                 // 文件大小(byte)
+    ContentType string                // 客户端提交的文件MIME类型
+    Header      textproto.MIMEHeader  // 文件表单项的header信息
+    header      *multipart.FileHeader // 标准库解析的文件表单项(内容保存在内存或者临时文件中)
+}
+
+// 默认的文件上传配置
+var defaultUploadConfig = UploadConfig {
+    MaxMemory : gDEFAULT_UPLOAD_MAX_MEMORY,
+}
+
+// 设置指定路由的文件上传配置，pattern格式同BindHandler，未设置的路由使用ServerConfig.UploadConfig；
+// 多个配置匹配同一请求时使用优先级最高的配置，相同的pattern重复设置时进行替换
+func (s *Server) SetUploadConfig(pattern string, config UploadConfig) error {
+    return s.setRouteRule(gROUTE_RULE_UPLOAD, pattern, func(r *Request) {
+        r.uploadConfig = &config
+    })
+}
+
+// 获取指定名称的上传文件，当存在多个同名文件时返回第一个，不存在时返回nil
+func (r *Request) GetUploadFile(name string) *UploadFile {
+    if files := r.GetUploadFiles(name); len(files) > 0 {
+        return files[0]
+    }
+    return nil
+}
+
+// 获取指定名称的上传文件列表
+func (r *Request) GetUploadFiles(name string) []*UploadFile {
+    r.initPost()
+    return r.uploadFiles[name]
+}
+
+// 获取上传文件解析时产生的错误(例如：文件大小超出限制、文件类型不被允许等)，不符合要求的文件会被忽略
+func (r *Request) GetUploadError() error {
+    r.initPost()
+    return r.uploadError
+}
+
+// 获取当前请求的文件上传配置
+func (r *Request) getUploadConfig() *UploadConfig {
+    if r.uploadConfig != nil {
+        return r.uploadConfig
+    }
+    return &r.Server.config.UploadConfig
+}
+
+// 记录上传解析错误，只保留第一个错误
+func (r *Request) setUploadError(err error) {
+    if r.uploadError == nil {
+        r.uploadError = err
+    }
+}
+
+// 解析multipart表单，使用标准库的ParseMultipartForm解析(上传文件超过内存限制时写入系统临时目录)，
+// 以便r.FormFile、r.MultipartForm等标准库方法仍然可用；解析后按照配置对上传文件进行检查，
+// 不符合要求的文件同时从r.MultipartForm中移除
+func (r *Request) parseMultipartForm() {
+    config := r.getUploadConfig()
+    if err := r.ParseMultipartForm(config.MaxMemory); err != nil {
+        r.setUploadError(err)
+        return
+    }
+    if r.MultipartForm == nil {
+        return
+    }
+    for name, headers := range r.MultipartForm.File {
+        allowed := make([]*multipart.FileHeader, 0, len(headers))
+        for _, header := range headers {
+            if file, err := newUploadFile(header, config); err != nil {
+                r.setUploadError(err)
+            } else {
+                allowed = append(allowed, header)
+                r.uploadFiles[name] = append(r.uploadFiles[name], file)
+            }
+        }
+        if len(allowed) > 0 {
+            r.MultipartForm.File[name] = allowed
+        } else {
+            delete(r.MultipartForm.File, name)
+        }
+    }
+}
+
+// 根据multipart文件表单项创建上传文件对象，并进行扩展名、MIME类型及大小检查
+func newUploadFile(header *multipart.FileHeader, config *UploadConfig) (*UploadFile, error) {
+    file := &UploadFile {
+        Filename    : header.Filename,
+        Size        : header.Size,
+        ContentType : header.Header.Get("Content-Type"),
+        Header      : header.Header,
+        header      : header,
+    }
+    if !checkUploadExt(file.Filename, config.AllowedExts) {
+        return nil, fmt.Errorf(`file extension not allowed: "%s"`, file.Filename)
+    }
+    if !checkUploadMime(file.ContentType, config.AllowedMimes) {
+        return nil, fmt.Errorf(`file content type not allowed: "%s"`, file.ContentType)
+    }
+    if config.MaxFileSize > 0 && file.Size > config.MaxFileSize {
+        return nil, fmt.Errorf(`file size exceeds limit %d: "%s"`, config.MaxFileSize, file.Filename)
+    }
+    return file, nil
+}
+
+// 检查文件扩展名是否允许上传
+func checkUploadExt(filename string, exts []string) bool {
+    if len(exts) == 0 {
+        return true
+    }
+    ext := strings.ToLower(gfile.Ext(filename))
+    for _, v := range exts {
+        if strings.EqualFold(v, ext) {
+            return true
+        }
+    }
+    return false
+}
+
+// 检查文件MIME类型是否允许上传，支持image/*形式的通配
+func checkUploadMime(contentType string, mimes []string) bool {
+    if len(mimes) == 0 {
+        return true
+    }
+    mimeType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+    for _, v := range mimes {
+        v = strings.ToLower(v)
+        if v == mimeType || (strings.HasSuffix(v, "/*") && strings.HasPrefix(mimeType, v[0 : len(v) - 1])) {
+            return true
+        }
+    }
+    return false
+}
+
+// 删除请求产生的上传临时文件(请求结束时调用)
+func (r *Request) removeUploadTmpFiles() {
+    if r.MultipartForm != nil {
+        r.MultipartForm.RemoveAll()
+    }
+}
+
+// 将struct中类型为*UploadFile或者[]*UploadFile的属性与上传文件进行绑定，
+// 参数名称按照params标签、自定义映射及属性名称(不区分首字母大小写)进行匹配
+func (r *Request) bindUploadFilesToStruct(object interface{}, tagmap map[string]string) {
+    r.initPost()
+    if len(r.uploadFiles) == 0 {
+        return
+    }
+    names := make(map[string]string)
+    for k, v := range tagmap {
+        names[v] = k
+    }
+    elem := reflect.ValueOf(object).Elem()
+    for _, field := range structs.Fields(object) {
+        if !field.IsExported() {
+            continue
+        }
+        value := elem.FieldByName(field.Name())
+        name  := field.Name()
+        files := r.uploadFiles[name]
+        if v, ok := names[name]; ok {
+            files = r.uploadFiles[v]
+        } else if len(files) == 0 {
+            files = r.uploadFiles[strings.ToLower(name[0:1]) + name[1:]]
+        }
+        if len(files) == 0 {
+            continue
+        }
+        switch value.Interface().(type) {
+            case *UploadFile:
+                value.Set(reflect.ValueOf(files[0]))
+            case []*UploadFile:
+                value.Set(reflect.ValueOf(files))
+        }
+    }
+}
+
+// 打开上传文件用于读取，使用完毕后需要调用Close关闭
+func (f *UploadFile) Open() (io.ReadCloser, error) {
+    return f.header.Open()
+}
+
+// 保存上传文件到指定目录，目录不存在时自动创建。
+// 参数randomName为true时使用随机文件名称(保留扩展名)，否则使用客户端提交的文件名称。
+// 返回保存后的文件名称(不包含目录)。
+func (f *UploadFile) Save(dir string, randomName...bool) (filename string, err error) {
+    if !gfile.Exists(dir) {
+        if err = gfile.Mkdir(dir); err != nil {
+            return "", err
+        }
+    } else if !gfile.IsDir(dir) {
+        return "", errors.New(`"` + dir + `" is not a directory`)
+    }
+    // 去掉客户端提交的路径信息，防止写入目标目录之外
+    filename = gfile.Basename(strings.Replace(f.Filename, "\\", "/", -1))
+    if len(randomName) > 0 && randomName[0] {
+        filename  = strings.ToLower(strconv.FormatInt(gtime.Nanosecond(), 36) + grand.RandStr(6))
+        filename += strings.ToLower(gfile.Ext(f.Filename))
+    }
+    if filename == "" || filename == "." || filename == ".." {
+        return "", errors.New("invalid file name: " + f.Filename)
+    }
+    path := strings.TrimRight(dir, gfile.Separator) + gfile.Separator + filename
+    src, err := f.Open()
+    if err != nil {
+        return "", err
+    }
+    defer src.Close()
+    dst, err := os.Create(path)
+    if err != nil {
+        return "", err
+    }
+    defer dst.Close()
+    if _, err = io.Copy(dst, src); err != nil {
+        return "", err
+    }
+    return filename, nil
+}
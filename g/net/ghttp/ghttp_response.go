@@ -22,6 +22,8 @@ type Response struct {
     Server  *Server
     Writer  *ResponseWriter // ResponseWriter的别名
     request *Request        // 关联的Request请求对象
+    sse     *SSEWriter      // 关联的SSE输出对象(开启SSE时有效)
+    flushed bool            // 是否已经向客户端输出过header信息(流式输出时)
 }
 
 // 创建一个ghttp.Response对象指针
@@ -55,6 +57,9 @@ func (r *Response) Write(content ... interface{}) {
         }
     }
     r.mu.Unlock()
+    if r.streaming {
+        r.Flush()
+    }
 }
 
 // 返回信息，支持自定义format格式
@@ -84,6 +89,7 @@ func (r *Response) WriteJson(content interface{}) error {
     } else {
         r.Header().Set("Content-Type", "application/json")
         r.Write(b)
+        r.recordApiResponse(content)
     }
     return nil
 }
@@ -114,6 +120,7 @@ func (r *Response) WriteXml(content interface{}, rootTag...string) error {
     } else {
         r.Header().Set("Content-Type", "application/xml")
         r.Write(b)
+        r.recordApiResponse(content)
     }
     return nil
 }
@@ -199,9 +206,35 @@ func (r *Response) ClearBuffer() {
     r.mu.Unlock()
 }
 
+// 开启流式输出，关闭当前请求的输出缓冲，之后写入的内容将会立即发送到客户端。
+// 注意开启后header信息(包括Cookie)将会随第一次输出发送，之后不能再修改。
+func (r *Response) EnableStream() {
+    r.streaming = true
+}
+
+// 当前请求是否为流式输出
+func (r *Response) IsStreaming() bool {
+    return r.streaming
+}
+
+// 立即将缓冲区数据(以及header信息)输出到客户端，返回输出时产生的错误(例如客户端已断开)
+// (业务goroutine及SSE心跳goroutine可能同时调用，header信息的输出需要加锁)
+func (r *Response) Flush() error {
+    r.mu.Lock()
+    if !r.flushed {
+        r.flushed = true
+        r.Header().Set("Server", r.Server.config.ServerAgent)
+        r.request.Cookie.Output()
+    }
+    r.mu.Unlock()
+    return r.Writer.flush()
+}
+
 // 输出缓冲区数据到客户端
 func (r *Response) OutputBuffer() {
+    r.mu.Lock()
     r.Header().Set("Server", r.Server.config.ServerAgent)
+    r.mu.Unlock()
     //r.handleGzip()
     r.Writer.OutputBuffer()
 }
@@ -0,0 +1,205 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 内容协商(根据Accept及format参数选择JSON/XML/YAML/TOML格式输出).
+
+package ghttp
+
+import (
+    "fmt"
+    "sort"
+    "reflect"
+    "strings"
+    "strconv"
+    "net/http"
+    "gitee.com/johng/gf/g/encoding/gparser"
+)
+
+const (
+    ENTITY_FORMAT_JSON = "json"
+    ENTITY_FORMAT_XML  = "xml"
+    ENTITY_FORMAT_YAML = "yaml"
+    ENTITY_FORMAT_TOML = "toml"
+)
+
+// 各数据格式输出时使用的Content-Type
+var entityContentTypes = map[string]string {
+    ENTITY_FORMAT_JSON : "application/json",
+    ENTITY_FORMAT_XML  : "application/xml",
+    ENTITY_FORMAT_YAML : "application/x-yaml",
+    ENTITY_FORMAT_TOML : "application/toml",
+}
+
+// MIME类型与数据格式的映射，通配类型优先使用JSON格式
+var entityMimeFormats = map[string]string {
+    "*/*"                : ENTITY_FORMAT_JSON,
+    "application/*"      : ENTITY_FORMAT_JSON,
+    "application/json"   : ENTITY_FORMAT_JSON,
+    "text/json"          : ENTITY_FORMAT_JSON,
+    "text/*"             : ENTITY_FORMAT_XML,
+    "application/xml"    : ENTITY_FORMAT_XML,
+    "text/xml"           : ENTITY_FORMAT_XML,
+    "application/yaml"   : ENTITY_FORMAT_YAML,
+    "application/x-yaml" : ENTITY_FORMAT_YAML,
+    "text/yaml"          : ENTITY_FORMAT_YAML,
+    "text/x-yaml"        : ENTITY_FORMAT_YAML,
+    "application/toml"   : ENTITY_FORMAT_TOML,
+    "text/toml"          : ENTITY_FORMAT_TOML,
+}
+
+// 支持的数据格式，通配类型(例如：*/*)按照该顺序选择
+var entityFormats = []string {
+    ENTITY_FORMAT_JSON,
+    ENTITY_FORMAT_XML,
+    ENTITY_FORMAT_YAML,
+    ENTITY_FORMAT_TOML,
+}
+
+// Accept中的单个媒体类型
+type acceptItem struct {
+    mime string  // 媒体类型(小写)
+    q    float64 // 权重
+}
+
+// 返回YAML
+func (r *Response) WriteYaml(content interface{}) error {
+    return r.writeEntity(ENTITY_FORMAT_YAML, content)
+}
+
+// 返回TOML，注意TOML格式只支持map/struct类型的数据
+func (r *Response) WriteToml(content interface{}) error {
+    return r.writeEntity(ENTITY_FORMAT_TOML, content)
+}
+
+// 根据客户端请求的数据格式输出数据(内容协商)：优先使用format参数(json/xml/yaml/yml/toml)，
+// 其次按照Accept头的权重选择，没有Accept头时输出JSON；
+// 客户端可接受的格式中，不支持该数据的格式(例如XML/TOML不支持数组)会被跳过，都不支持时返回406状态码。
+func (r *Response) WriteEntity(content interface{}, rootTag...string) error {
+    r.Header().Add("Vary", "Accept")
+    for _, format := range r.request.getEntityFormats() {
+        if isEntitySupported(format, content) {
+            return r.writeEntity(format, content, rootTag...)
+        }
+    }
+    r.WriteStatus(http.StatusNotAcceptable)
+    return nil
+}
+
+// 判断数据格式是否支持给定的数据，XML及TOML需要以键名作为节点名称，只支持map/struct类型的数据
+func isEntitySupported(format string, content interface{}) bool {
+    if format != ENTITY_FORMAT_XML && format != ENTITY_FORMAT_TOML {
+        return true
+    }
+    t := reflect.TypeOf(content)
+    for t != nil && t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    return t != nil && (t.Kind() == reflect.Map || t.Kind() == reflect.Struct)
+}
+
+// 按照指定的数据格式编码并输出，数据格式不支持该数据时返回错误
+func (r *Response) writeEntity(format string, content interface{}, rootTag...string) error {
+    if !isEntitySupported(format, content) {
+        return fmt.Errorf(`%s format does not support content of type %T`, format, content)
+    }
+    var b   []byte
+    var err error
+    switch format {
+        case ENTITY_FORMAT_XML:  b, err = gparser.VarToXml(content, rootTag...)
+        case ENTITY_FORMAT_YAML: b, err = gparser.VarToYaml(content)
+        case ENTITY_FORMAT_TOML: b, err = gparser.VarToToml(content)
+        default:                 b, err = gparser.VarToJson(content)
+    }
+    if err != nil {
+        return err
+    }
+    r.Header().Set("Content-Type", entityContentTypes[format])
+    r.Write(b)
+    r.recordApiResponse(content)
+    return nil
+}
+
+// 获取客户端可接受的数据格式，按照优先级排序，都不支持时返回空数组；
+// 客户端通过权重0明确拒绝的格式(例如：application/json;q=0)不会通过通配类型再被选择。
+func (r *Request) getEntityFormats() []string {
+    if format := strings.ToLower(r.GetQueryString("format")); format != "" {
+        if format == "yml" {
+            format = ENTITY_FORMAT_YAML
+        }
+        if _, ok := entityContentTypes[format]; ok {
+            return []string{format}
+        }
+        return nil
+    }
+    accept := r.Header.Get("Accept")
+    if strings.TrimSpace(accept) == "" {
+        return []string{ENTITY_FORMAT_JSON}
+    }
+    items   := parseAccept(accept)
+    refused := make(map[string]bool)
+    for _, item := range items {
+        if format, ok := entityMimeFormats[item.mime]; ok && item.q == 0 && !strings.Contains(item.mime, "*") {
+            refused[format] = true
+        }
+    }
+    formats := make([]string, 0)
+    add     := func(format string) {
+        if format == "" || refused[format] {
+            return
+        }
+        for _, v := range formats {
+            if v == format {
+                return
+            }
+        }
+        formats = append(formats, format)
+    }
+    for _, item := range items {
+        if item.q == 0 {
+            continue
+        }
+        if !strings.HasSuffix(item.mime, "/*") {
+            add(entityMimeFormats[item.mime])
+            continue
+        }
+        // 通配类型优先使用默认的格式，其次按照顺序使用通配类型下的其他格式
+        add(entityMimeFormats[item.mime])
+        for _, format := range entityFormats {
+            for mime, v := range entityMimeFormats {
+                if v == format && !strings.Contains(mime, "*") && (item.mime == "*/*" || strings.HasPrefix(mime, item.mime[0 : len(item.mime) - 1])) {
+                    add(format)
+                }
+            }
+        }
+    }
+    return formats
+}
+
+// 解析Accept头，按照权重从高到低排序(相同权重保持原有顺序)，权重为0的媒体类型(表示客户端拒绝)排在最后
+func parseAccept(accept string) []acceptItem {
+    items := make([]acceptItem, 0)
+    for _, part := range strings.Split(accept, ",") {
+        fields := strings.Split(part, ";")
+        item   := acceptItem {
+            mime : strings.ToLower(strings.TrimSpace(fields[0])),
+            q    : 1,
+        }
+        for _, param := range fields[1:] {
+            param = strings.TrimSpace(param)
+            if strings.HasPrefix(param, "q=") {
+                if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+                    item.q = q
+                }
+            }
+        }
+        if item.mime != "" {
+            items = append(items, item)
+        }
+    }
+    sort.SliceStable(items, func(i, j int) bool {
+        return items[i].q > items[j].q
+    })
+    return items
+}
@@ -0,0 +1,58 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package ghttp
+
+import (
+    "testing"
+)
+
+func Test_Response_WriteEntity(t *testing.T) {
+    s := GetServer("response-write-entity")
+    s.BindHandler("/map", func(r *Request) {
+        r.Response.WriteEntity(map[string]interface{}{"id" : 1})
+    })
+    s.BindHandler("/list", func(r *Request) {
+        r.Response.WriteEntity([]int{1, 2})
+    })
+    s.BindHandler("/toml", func(r *Request) {
+        if err := r.Response.WriteToml([]int{1, 2}); err == nil {
+            t.Error("toml encoding of slice should fail")
+        }
+    })
+    c     := NewTestClient(s)
+    cases := []struct {
+        url         string
+        accept      string
+        status      int
+        contentType string
+    }{
+        {"/map",            "",                                  200, "application/json"},
+        {"/map",            "application/xml",                   200, "application/xml"},
+        {"/map",            "text/html, application/x-yaml;q=0.9", 200, "application/x-yaml"},
+        {"/map?format=yml", "application/json",                  200, "application/x-yaml"},
+        {"/map",            "image/png",                         406, ""},
+        // 明确拒绝的格式不能通过通配类型再被选择
+        {"/map",            "application/json;q=0, */*",         200, "application/xml"},
+        {"/map",            "application/json;q=0, application/xml;q=0, application/*", 200, "application/x-yaml"},
+        // XML不支持数组，使用客户端可接受的其他格式
+        {"/list",           "application/xml, application/json;q=0.5", 200, "application/json"},
+        {"/list",           "application/xml",                   406, ""},
+        {"/list",           "text/*",                            200, "application/json"},
+        {"/list?format=xml", "",                                 406, ""},
+        {"/toml",           "",                                  200, ""},
+    }
+    for _, v := range cases {
+        resp, err := c.R().SetHeader("Accept", v.accept).Get(v.url)
+        if err != nil {
+            t.Fatal(err)
+        }
+        resp.ReadAll()
+        if resp.StatusCode != v.status || (v.contentType != "" && resp.Header.Get("Content-Type") != v.contentType) {
+            t.Errorf("%s %q: expect %d %q, got %d %q", v.url, v.accept, v.status, v.contentType, resp.StatusCode, resp.Header.Get("Content-Type"))
+        }
+    }
+}
@@ -0,0 +1,162 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// Server-Sent Events(SSE)输出.
+
+package ghttp
+
+import (
+    "sync"
+    "time"
+    "errors"
+    "strings"
+    "strconv"
+    "gitee.com/johng/gf/g/encoding/gparser"
+)
+
+// SSE输出对象，用于向客户端推送事件
+type SSEWriter struct {
+    mu       sync.Mutex     // 输出互斥锁(业务输出与心跳输出之间互斥)
+    response *Response      // 关联的Response对象
+    closed   bool           // 是否已关闭(客户端断开或者请求结束)
+    done     chan struct{}  // 关闭事件通道
+}
+
+// 当前SSE已关闭时返回的错误
+var ErrSSEClosed = errors.New("sse connection closed")
+
+// 开启SSE输出，设置对应的header信息并开启流式输出，同一请求多次调用返回同一对象。
+// 当客户端断开或者请求结束时，Done()返回的通道会被关闭，业务方法应当以此结束推送循环。
+func (r *Response) SSE() *SSEWriter {
+    if r.sse != nil {
+        return r.sse
+    }
+    w := &SSEWriter {
+        response : r,
+        done     : make(chan struct{}),
+    }
+    r.sse = w
+    r.Header().Set("Content-Type",      "text/event-stream; charset=utf-8")
+    r.Header().Set("Cache-Control",     "no-cache")
+    r.Header().Set("Connection",        "keep-alive")
+    r.Header().Set("X-Accel-Buffering", "no")
+    r.EnableStream()
+    r.Flush()
+    // 监听客户端断开
+    go func() {
+        select {
+            case <- r.request.Context().Done():
+                w.Close()
+            case <- w.done:
+        }
+    }()
+    return w
+}
+
+// 推送一个事件，name为空时表示默认的message事件，id为可选的事件ID(客户端重连时通过Last-Event-ID回传)。
+// data为string/[]byte时原样输出，其他类型编码为JSON输出。
+func (w *SSEWriter) Event(name string, data interface{}, id...string) error {
+    content := ""
+    if len(id) > 0 && id[0] != "" {
+        content += "id: " + sseEscape(id[0]) + "\n"
+    }
+    if name != "" {
+        content += "event: " + sseEscape(name) + "\n"
+    }
+    value := ""
+    switch v := data.(type) {
+        case string: value = v
+        case []byte: value = string(v)
+        default:
+            b, err := gparser.VarToJson(data)
+            if err != nil {
+                return err
+            }
+            value = string(b)
+    }
+    // 多行数据需要拆分为多个data字段
+    for _, line := range strings.Split(strings.Replace(value, "\r\n", "\n", -1), "\n") {
+        content += "data: " + line + "\n"
+    }
+    return w.write(content + "\n")
+}
+
+// 推送默认的message事件
+func (w *SSEWriter) Data(data interface{}) error {
+    return w.Event("", data)
+}
+
+// 设置客户端断开后的重连间隔
+func (w *SSEWriter) Retry(interval time.Duration) error {
+    return w.write("retry: " + strconv.FormatInt(int64(interval/time.Millisecond), 10) + "\n\n")
+}
+
+// 推送注释内容，客户端会忽略该内容，常用于保持连接
+func (w *SSEWriter) Comment(text string) error {
+    return w.write(": " + sseEscape(text) + "\n\n")
+}
+
+// 开启心跳，按照给定的间隔推送注释内容以保持连接(防止代理服务器超时断开)，SSE关闭时自动停止
+func (w *SSEWriter) Heartbeat(interval time.Duration) {
+    go func() {
+        ticker := time.NewTicker(interval)
+        defer ticker.Stop()
+        for {
+            select {
+                case <- w.done:
+                    return
+                case <- ticker.C:
+                    if err := w.Comment("heartbeat"); err != nil {
+                        return
+                    }
+            }
+        }
+    }()
+}
+
+// 获取关闭事件通道，客户端断开或者请求结束时该通道会被关闭
+func (w *SSEWriter) Done() <-chan struct{} {
+    return w.done
+}
+
+// 是否已关闭
+func (w *SSEWriter) IsClosed() bool {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    return w.closed
+}
+
+// 关闭SSE，关闭之后的推送将会返回ErrSSEClosed
+func (w *SSEWriter) Close() {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if !w.closed {
+        w.closed = true
+        close(w.done)
+    }
+}
+
+// 输出内容到客户端，写入失败时(客户端断开)自动关闭
+func (w *SSEWriter) write(content string) error {
+    w.mu.Lock()
+    if w.closed {
+        w.mu.Unlock()
+        return ErrSSEClosed
+    }
+    w.response.mu.Lock()
+    w.response.buffer = append(w.response.buffer, content...)
+    w.response.mu.Unlock()
+    err := w.response.Flush()
+    w.mu.Unlock()
+    if err != nil {
+        w.Close()
+    }
+    return err
+}
+
+// 去掉换行符，防止破坏SSE协议格式
+func sseEscape(s string) string {
+    return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -22,6 +22,8 @@ func (r *Response) Template(tpl string, params map[string]interface{}, funcmap..
     fmap["get"]       = r.funcGet
     fmap["post"]      = r.funcPost
     fmap["request"]   = r.funcRequest
+    fmap["url"]       = r.funcUrl
+    fmap["T"]         = r.funcT
     if b, err := gins.View().Parse(tpl, params, fmap); err != nil {
         r.Write("Tpl Parsing Error: " + err.Error())
         return err
@@ -44,4 +46,9 @@ func (r *Response) funcPost(key string, def...string) gview.HTML {
 // 模板内置函数: request
 func (r *Response) funcRequest(key string, def...string) gview.HTML {
     return gview.HTML(r.request.Get(key, def...))
-}
\ No newline at end of file
+}
+
+// 模板内置函数: T，按照当前请求的语言翻译，例如：{{T "user.hello" .name}}
+func (r *Response) funcT(key string, args...interface{}) string {
+    return r.request.T(key, args...)
+}
@@ -15,14 +15,23 @@ import (
 // 自定义的ResponseWriter，用于写入流的控制
 type ResponseWriter struct {
     http.ResponseWriter
-    mu     sync.RWMutex    // 缓冲区互斥锁
-    Status int             // http status
-    buffer []byte          // 缓冲区内容
+    mu        sync.RWMutex    // 缓冲区互斥锁
+    Status    int             // http status
+    buffer    []byte          // 缓冲区内容
+    size      int             // 已经输出到客户端的内容大小(byte)
+    streaming bool            // 是否流式输出(关闭缓冲，写入即输出)
 }
 
-// 覆盖父级的WriteHeader方法
+// 覆盖父级的Write方法，默认写入缓冲区，流式输出时直接输出到客户端
 func (w *ResponseWriter) Write(buffer []byte) (int, error) {
+    w.mu.Lock()
     w.buffer = append(w.buffer, buffer...)
+    w.mu.Unlock()
+    if w.streaming {
+        if err := w.flush(); err != nil {
+            return 0, err
+        }
+    }
     return len(buffer), nil
 }
 
@@ -32,12 +41,43 @@ func (w *ResponseWriter) WriteHeader(code int) {
     w.ResponseWriter.WriteHeader(code)
 }
 
+// 将缓冲区数据立即输出到客户端，实现http.Flusher接口
+func (w *ResponseWriter) Flush() {
+    w.flush()
+}
+
+// 输出缓冲区数据到客户端并刷新底层连接，返回写入时产生的错误(例如客户端已断开)
+func (w *ResponseWriter) flush() error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if len(w.buffer) > 0 {
+        n, err := w.ResponseWriter.Write(w.buffer)
+        w.size  += n
+        w.buffer = make([]byte, 0)
+        if err != nil {
+            return err
+        }
+    }
+    if f, ok := w.ResponseWriter.(http.Flusher); ok {
+        f.Flush()
+    }
+    return nil
+}
+
 // 输出buffer数据到客户端
 func (w *ResponseWriter) OutputBuffer() {
+    w.mu.Lock()
+    defer w.mu.Unlock()
     if len(w.buffer) > 0 {
-        w.mu.Lock()
-        w.ResponseWriter.Write(w.buffer)
+        n, _    := w.ResponseWriter.Write(w.buffer)
+        w.size  += n
         w.buffer = make([]byte, 0)
-        w.mu.Unlock()
     }
 }
+
+// 获取已经输出到客户端的内容大小(byte)
+func (w *ResponseWriter) OutputSize() int {
+    w.mu.RLock()
+    defer w.mu.RUnlock()
+    return w.size
+}
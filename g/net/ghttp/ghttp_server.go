@@ -13,7 +13,9 @@ import (
     "strings"
     "reflect"
     "runtime"
+    "net"
     "net/http"
+    "crypto/tls"
     "gitee.com/johng/gf/g/os/glog"
     "gitee.com/johng/gf/g/os/gproc"
     "gitee.com/johng/gf/g/os/gcache"
@@ -48,8 +50,6 @@ const (
     gDEFAULT_COOKIE_MAX_AGE    = 86400*365       // 默认cookie有效期(一年)
     gDEFAULT_SESSION_MAX_AGE   = 600             // 默认session有效期(600秒)
     gDEFAULT_SESSION_ID_NAME   = "gfsessionid"   // 默认存放Cookie中的SessionId名称
-    gSERVE_CACHE_LRU_SIZE      = 100000          // 服务回调函数缓存LRU大小
-    gHOOKS_CACHE_LRU_SIZE      = 100000          // 事件回调函数缓存LRU大小
     gROUTE_REGISTER_HANDLER    = 1
     gROUTE_REGISTER_OBJECT     = 2
     gROUTE_REGISTER_CONTROLLER = 3
@@ -60,22 +60,31 @@ type Server struct {
     // 基本属性变量
     name             string                   // 服务名称，方便识别
     paths            *gspath.SPath            // 静态文件检索对象(类似nginx tryfile功能)
+    staticPaths      []staticPathItem         // 静态文件目录映射(按照前缀长度从长到短排序)
+    staticEtags      *gmap.StringStringMap    // 静态文件ETag缓存(文件变化时自动失效)
+    staticEtagMu     sync.Mutex               // 静态文件ETag缓存及文件监控添加的互斥锁，防止重复添加监控
     config           ServerConfig             // 配置对象
     servers          []*gracefulServer        // 底层http.Server列表
+    listeners        []net.Listener           // 开发者设置的监听对象(HTTP)
     methodsMap       map[string]struct{}      // 所有支持的HTTP Method(初始化时自动填充)
     servedCount      *gtype.Int               // 已经服务的请求数(4-8字节，不考虑溢出情况)，同时作为请求ID
     closeQueue       *gqueue.Queue            // 请求结束的关闭队列(存放的是需要异步关闭处理的*Request对象)
+    prepareOnce      sync.Once                // 请求处理初始化(只执行一次)
+    serving          *gtype.Bool              // 是否已经开始处理请求(请求处理初始化之后)，之后路由前缀树不能再改变
+    prepareError     error                    // 请求处理初始化产生的错误(例如IP访问控制规则错误)
     // 服务注册相关
-    serveTree        map[string]interface{}   // 所有注册的服务回调函数(路由表，树型结构，哈希表+链表优先级匹配)
-    hooksTree        map[string]interface{}   // 所有注册的事件回调函数(路由表，树型结构，哈希表+链表优先级匹配)
-    serveCache       *gcache.Cache            // 服务注册路由内存缓存
-    hooksCache       *gcache.Cache            // 事件回调路由内存缓存
+    serveTree        map[string]*routerTree   // 所有注册的服务回调函数(路由前缀树，键名为域名)
+    hooksTree        map[string]map[string]*routerTree // 所有注册的事件回调函数(路由前缀树，键名为域名及事件名称)
+    rulesTree        map[string]map[string]*routerTree // 所有注册的路由规则(IP访问控制、JWT认证、上传配置等，路由前缀树，键名为域名及规则类型)
     routesMap        map[string]string        // 已经注册的路由及对应的注册方法文件地址
+    routeNames       map[string]string        // 路由名称与路由URI的映射(用于反向生成URL)
+    domainPatterns   []*domainPattern         // 域名模式(通配符及命名参数)，按照优先级排序
     // 自定义状态码回调
     hsmu             sync.RWMutex             // status handler互斥锁
     statusHandlerMap map[string]HandlerFunc   // 不同状态码下的注册处理方法(例如404状态时的处理方法)
     // COOKIE
     cookieMaxAge     *gtype.Int               // Cookie有效期
+    cookieSecrets    *gtype.Interface         // 签名及加密Cookie的密钥列表(第一个为当前密钥)
     cookies          *gmap.IntInterfaceMap    // 当前服务器正在服务(请求正在执行)的Cookie(每个请求一个Cookie对象)
     // SESSION
     sessionMaxAge    *gtype.Int               // Session有效期
@@ -86,11 +95,24 @@ type Server struct {
     logHandler       *gtype.Interface         // 自定义日志处理回调方法
     errorLogEnabled  *gtype.Bool              // 是否开启error log
     accessLogEnabled *gtype.Bool              // 是否开启access log
+    accessLogFormat  *gtype.String            // access log格式
+    requestIdHeader  *gtype.String            // 请求标识的Header名称，为空表示未开启请求标识中间件
     accessLogger     *glog.Logger             // access log日志对象
+    errorMode        *gtype.String            // 错误页面模式(prod/dev)
+    errorStatusFunc  *gtype.Interface         // panic错误到HTTP状态码的转换方法
+    recoveryHandler  *gtype.Interface         // 自定义panic恢复处理方法
     errorLogger      *glog.Logger             // error log日志对象
     // 其他属性
     nameToUriType    *gtype.Int               // 服务注册时对象和方法名称转换为URI时的规则
     gzipMimesMap     map[string]struct{}      // 支持gzip压缩的类型
+    wsHub            *WebSocketHub            // WebSocket连接管理对象
+    openApi          *openApi                 // OpenAPI接口文档管理对象
+    metrics          *metrics                 // 服务监控指标管理对象
+    tls              *tlsManager              // HTTPS证书管理对象
+    // IP访问控制
+    ipAccess         *ipAccessList            // 服务级别的IP访问控制规则(由DenyIps/AllowIps解析)
+    domainIpAccess   *gmap.StringInterfaceMap // 域名级别的IP访问控制规则(键名为域名)
+    trustedProxies   []*net.IPNet             // 可信代理网段(由TrustedProxies解析)
 }
 
 // 路由对象
@@ -109,12 +131,15 @@ type handlerMap  map[string]*handlerItem
 // http回调函数注册信息
 type handlerItem struct {
     rtype    int          // 注册方式
-    ctype    reflect.Type // 控制器类型(反射类型)
-    fname    string       // 回调方法名称
+    ctype    reflect.Type // 控制器/对象类型(反射类型)
+    fname    string       // 回调方法名称(控制器/对象方法名称)
     faddr    HandlerFunc  // 准确的执行方法内存地址(与以上两个参数二选一)
     finit    HandlerFunc  // 初始化请求回调方法(执行对象注册方式下有效)
     fshut    HandlerFunc  // 完成请求回调方法(执行对象注册方式下有效)
     router   *Router      // 注册时绑定的路由对象
+    names    []string     // 路由参数名称(与前缀树匹配的参数值按照顺序对应，匿名参数为空字符串)
+    rank     int          // 优先级排名(在所属路由前缀树中的排序位置，值越小优先级越高)
+    caller   string       // 注册时的文件地址(文件:行号)
 }
 
 // 根据特定URL.Path解析后的路由检索结果项
@@ -166,6 +191,8 @@ func serverProcInit() {
     go handleProcessSignal()
     // 异步监听进程间消息
     go handleProcessMessage()
+    // 由热编译工具(grun)启动时，通知grun当前服务进程
+    notifyRunnerStarted()
 }
 
 // 获取/创建一个默认配置的HTTP Server(默认监听端口是80)
@@ -181,36 +208,47 @@ func GetServer(name...interface{}) (*Server) {
     s := &Server {
         name             : sname,
         paths            : gspath.New(),
+        staticPaths      : make([]staticPathItem, 0),
+        staticEtags      : gmap.NewStringStringMap(),
         servers          : make([]*gracefulServer, 0),
         methodsMap       : make(map[string]struct{}),
         statusHandlerMap : make(map[string]HandlerFunc),
-        serveTree        : make(map[string]interface{}),
-        hooksTree        : make(map[string]interface{}),
-        serveCache       : gcache.New(),
-        hooksCache       : gcache.New(),
+        serveTree        : make(map[string]*routerTree),
+        hooksTree        : make(map[string]map[string]*routerTree),
+        rulesTree        : make(map[string]map[string]*routerTree),
         routesMap        : make(map[string]string),
+        routeNames       : make(map[string]string),
         cookies          : gmap.NewIntInterfaceMap(),
         sessions         : gcache.New(),
         servedCount      : gtype.NewInt(),
+        serving          : gtype.NewBool(),
         closeQueue       : gqueue.New(),
         accessLogger     : glog.New(),
         errorLogger      : glog.New(),
         // 可设置的属性，具体设置由ServerConfig管理
         cookieMaxAge     : gtype.NewInt(),
+        cookieSecrets    : gtype.NewInterface(),
         sessionMaxAge    : gtype.NewInt(),
         sessionIdName    : gtype.NewString(),
         logPath          : gtype.NewString(),
         accessLogEnabled : gtype.NewBool(),
+        accessLogFormat  : gtype.NewString(),
+        requestIdHeader  : gtype.NewString(),
+        errorMode        : gtype.NewString(),
+        errorStatusFunc  : gtype.NewInterface(),
+        recoveryHandler  : gtype.NewInterface(),
         errorLogEnabled  : gtype.NewBool(),
         logHandler       : gtype.NewInterface(),
         nameToUriType    : gtype.NewInt(),
         gzipMimesMap     : make(map[string]struct{}),
+        wsHub            : NewWebSocketHub(),
+        openApi          : newOpenApi(),
+        metrics          : newMetrics(),
+        tls              : newTlsManager(),
+        domainIpAccess   : gmap.NewStringInterfaceMap(),
     }
-    s.errorLogger.SetBacktraceSkip(4)
+    s.errorLogger.SetBacktraceSkip(5)
     s.accessLogger.SetBacktraceSkip(4)
-    // 设置路由解析缓存上限，使用LRU进行缓存淘汰
-    s.serveCache.SetCap(gSERVE_CACHE_LRU_SIZE)
-    s.hooksCache.SetCap(gHOOKS_CACHE_LRU_SIZE)
     for _, v := range strings.Split(gHTTP_METHODS, ",") {
         s.methodsMap[v] = struct{}{}
     }
@@ -232,34 +270,13 @@ func (s *Server) Start() error {
         return errors.New("server is already running")
     }
 
-    // 如果设置了静态文件目录，那么优先按照静态文件目录进行检索，其次是当前可执行文件工作目录；
-    // 并且如果是开发环境，默认也会添加main包的源码目录路径做为二级检索。
-    if s.config.ServerRoot != "" {
-        s.paths.Set(s.config.ServerRoot)
-    }
-    s.paths.Add(gfile.SelfDir())
-    if p := gfile.MainPkgPath(); gfile.Exists(p) {
-        s.paths.Add(p)
-    }
-
     // 底层http server配置
     if s.config.Handler == nil {
-        s.config.Handler = http.HandlerFunc(s.defaultHttpHandle)
+        s.config.Handler = s
     }
-    // 不允许访问的路由注册
-    if s.config.DenyRoutes != nil {
-        for _, v := range s.config.DenyRoutes {
-            s.BindHookHandler(v, HOOK_BEFORE_SERVE, func(r *Request) {
-                r.Response.WriteStatus(403)
-                r.Exit()
-            })
-        }
-    }
-    // gzip压缩文件类型
-    if s.config.GzipContentTypes != nil {
-        for _, v := range s.config.GzipContentTypes {
-            s.gzipMimesMap[v] = struct{}{}
-        }
+    // 请求处理初始化，配置错误(例如IP访问控制规则错误)时不启动服务
+    if err := s.prepareServe(); err != nil {
+        return err
     }
 
     // 启动http server
@@ -273,7 +290,12 @@ func (s *Server) Start() error {
         }
     }
     if !reloaded {
-        s.startServer(nil)
+        // systemd socket activation传递的监听文件描述符优先于监听地址配置
+        s.startServer(s.getSystemdFdMap(), s.listeners...)
+    }
+    // 调试模式下打印所有注册的路由信息
+    if s.config.Debug {
+        s.DumpRoutes()
     }
 
     // 如果是子进程，那么服务开启后通知父进程销毁
@@ -282,12 +304,51 @@ func (s *Server) Start() error {
             gproc.Send(gproc.PPid(), []byte("exit"), gADMIN_GPROC_COMM_GROUP)
         })
     }
-
-    // 开启异步关闭队列处理循环
-    s.startCloseQueueLoop()
     return nil
 }
 
+// 请求处理的初始化，包括静态文件检索目录、路由访问控制、gzip压缩类型及异步关闭队列，只会执行一次；
+// 由Start调用，内存测试客户端(NewTestClient)及作为http.Handler挂载时不需要监听端口，在第一次请求时调用。
+// 初始化产生的错误会在每次调用时返回。
+func (s *Server) prepareServe() error {
+    s.prepareOnce.Do(func() {
+        // 如果设置了静态文件目录，那么优先按照静态文件目录进行检索，其次是当前可执行文件工作目录；
+        // 并且如果是开发环境，默认也会添加main包的源码目录路径做为二级检索。
+        if s.config.ServerRoot != "" {
+            s.paths.Set(s.config.ServerRoot)
+        }
+        s.paths.Add(gfile.SelfDir())
+        if p := gfile.MainPkgPath(); gfile.Exists(p) {
+            s.paths.Add(p)
+        }
+        // 不允许访问的路由注册
+        if s.config.DenyRoutes != nil {
+            for _, v := range s.config.DenyRoutes {
+                s.BindHookHandler(v, HOOK_BEFORE_SERVE, func(r *Request) {
+                    r.Response.WriteStatus(403)
+                    r.Exit()
+                })
+            }
+        }
+        // IP访问控制规则及可信代理
+        if err := s.prepareIpAccess(); err != nil {
+            glog.Error(err)
+            s.prepareError = err
+        }
+        // gzip压缩文件类型
+        if s.config.GzipContentTypes != nil {
+            for _, v := range s.config.GzipContentTypes {
+                s.gzipMimesMap[v] = struct{}{}
+            }
+        }
+        // 开启异步关闭队列处理循环
+        s.startCloseQueueLoop()
+        // 路由前缀树在处理请求期间并发读，此后不再允许注册
+        s.serving.Set(true)
+    })
+    return s.prepareError
+}
+
 // 阻塞执行监听
 func (s *Server) Run() error {
     if err := s.Start(); err != nil {
@@ -311,10 +372,11 @@ func Wait() {
 }
 
 
-// 开启底层Web Server执行
-func (s *Server) startServer(fdMap listenerFdMap) {
+// 开启底层Web Server执行，fdMap为继承的监听文件描述符，listeners为开发者设置的监听对象
+func (s *Server) startServer(fdMap listenerFdMap, listeners...net.Listener) {
     var httpsEnabled bool
-    if len(s.config.HTTPSCertPath) > 0 && len(s.config.HTTPSKeyPath) > 0 {
+    var tlsConfig    *tls.Config
+    if s.isHttpsEnabled() {
         // ================
         // HTTPS
         // ================
@@ -327,6 +389,13 @@ func (s *Server) startServer(fdMap listenerFdMap) {
             }
         }
         httpsEnabled = len(s.config.HTTPSAddr) > 0
+        // 证书加载失败时不启动HTTPS服务
+        if config, err := s.newTlsConfig(); err == nil {
+            tlsConfig = config
+        } else {
+            glog.Error(err)
+            httpsEnabled = false
+        }
         var array []string
         if v, ok := fdMap["https"]; ok && len(v) > 0 {
             array = strings.Split(v, ",")
@@ -334,7 +403,7 @@ func (s *Server) startServer(fdMap listenerFdMap) {
             array = strings.Split(s.config.HTTPSAddr, ",")
         }
         for _, v := range array {
-            if len(v) == 0 {
+            if len(v) == 0 || !httpsEnabled {
                 continue
             }
             fd    := 0
@@ -358,8 +427,8 @@ func (s *Server) startServer(fdMap listenerFdMap) {
     // ================
     // HTTP
     // ================
-    // 当HTTPS服务未启用时，默认HTTP地址才会生效
-    if !httpsEnabled && len(s.config.Addr) == 0 {
+    // 当HTTPS服务未启用并且没有设置监听对象时，默认HTTP地址才会生效
+    if !httpsEnabled && len(listeners) == 0 && len(s.config.Addr) == 0 {
         s.config.Addr = gDEFAULT_HTTP_ADDR
     }
     var array []string
@@ -388,13 +457,18 @@ func (s *Server) startServer(fdMap listenerFdMap) {
             s.servers = append(s.servers, s.newGracefulServer(addr))
         }
     }
+    for _, ln := range listeners {
+        gs := s.newGracefulServer(listenerAddr(ln))
+        gs.rawListener = ln
+        s.servers = append(s.servers, gs)
+    }
     // 开始执行异步监听
     for _, v := range s.servers {
         go func(server *gracefulServer) {
             serverRunning.Add(1)
             err := (error)(nil)
             if server.isHttps {
-                err = server.ListenAndServeTLS(s.config.HTTPSCertPath, s.config.HTTPSKeyPath)
+                err = server.ListenAndServeTLS(tlsConfig)
             } else {
                 err = server.ListenAndServe()
             }
@@ -419,6 +493,11 @@ func (s *Server) Status() int {
     return SERVER_STATUS_STOPPED
 }
 
+// 判断Server是否已经开始处理请求(运行中，或者内存测试客户端已经发起过请求)，此时不能再注册路由
+func (s *Server) isServing() bool {
+    return s.Status() == SERVER_STATUS_RUNNING || s.serving.Val()
+}
+
 // 获取当前监听的文件描述符信息，构造成map返回
 func (s *Server) getListenerFdMap() map[string]string {
     m := map[string]string {
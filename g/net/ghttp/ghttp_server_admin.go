@@ -23,6 +23,8 @@ import (
     "time"
     "runtime"
     "bytes"
+    "net/http"
+    "html/template"
     "gitee.com/johng/gf/g/os/gfile"
 )
 
@@ -59,6 +61,7 @@ func (p *utilAdmin) Index(r *Request) {
                 <title>gf ghttp admin</title>
             </head>
             <body>
+                <p><a href="{{$.uri}}/routes">routes</a></p>
                 <p><a href="{{$.uri}}/restart">restart</a></p>
                 <p><a href="{{$.uri}}/shutdown">shutdown</a></p>
             </body>
@@ -67,6 +70,27 @@ func (p *utilAdmin) Index(r *Request) {
     r.Response.Write(buffer)
 }
 
+// 路由列表，包括服务路由、事件回调、路由规则及状态码回调，请求JSON(或者format=json)时输出JSON格式
+func (p *utilAdmin) Routes(r *Request) {
+    data := map[string]interface{} {
+        "routes" : r.Server.GetRoutes(),
+        "hooks"  : r.Server.GetHookRoutes(),
+        "rules"  : r.Server.GetRuleRoutes(),
+        "status" : r.Server.GetStatusHandlers(),
+    }
+    if r.GetQueryString("format") == "json" || isJsonRequest(r) {
+        r.Response.WriteJson(data)
+        return
+    }
+    buffer := bytes.NewBuffer(nil)
+    if err := adminRoutesTemplate.Execute(buffer, data); err != nil {
+        r.Response.WriteStatus(http.StatusInternalServerError, err.Error())
+        return
+    }
+    r.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+    r.Response.Write(buffer.Bytes())
+}
+
 // 服务重启
 func (p *utilAdmin) Restart(r *Request) {
     var err error = nil
@@ -168,7 +192,8 @@ func forkReloadProcess(newExeFilePath...string) error {
     if len(newExeFilePath) > 0 {
         path = newExeFilePath[0]
     }
-    p   := gproc.NewProcess(path, os.Args, os.Environ())
+    // 运行参数不包含原有的可执行文件路径，否则使用新的可执行文件重启时会被当做参数传递
+    p   := gproc.NewProcess(path, os.Args[1:], os.Environ())
     // 创建新的服务进程，子进程自动从父进程复制文件描述来监听同样的端口
     sfm := getServerFdMap()
     // 将sfm中的fd按照子进程创建时的文件描述符顺序进行整理，以便子进程获取到正确的fd
@@ -209,7 +234,7 @@ func forkRestartProcess(newExeFilePath...string) error {
     os.Unsetenv(gADMIN_ACTION_RELOAD_ENVKEY)
     env := os.Environ()
     env  = append(env, gADMIN_ACTION_RESTART_ENVKEY + "=1")
-    p   := gproc.NewProcess(path, os.Args, env)
+    p   := gproc.NewProcess(path, os.Args[1:], env)
     if _, err := p.Start(); err != nil {
         glog.Errorfln("%d: fork process failed, error:%s", gproc.Pid(), err.Error())
         return err
@@ -321,6 +346,49 @@ func handleProcessMessage() {
                 doneChan <- struct{}{}
                 return
             }
+            handleRunnerMessage(msg.Data)
         }
     }
-}
\ No newline at end of file
+}
+
+// 路由列表页面模板
+var adminRoutesTemplate = template.Must(template.New("routes").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>gf ghttp admin - routes</title>
+    <style>
+        body  {font-family: Arial, sans-serif; margin: 20px; color: #333;}
+        h2    {font-size: 16px; border-bottom: 1px solid #ddd; padding-bottom: 5px;}
+        table {border-collapse: collapse; font-size: 13px; margin-bottom: 20px;}
+        th,td {border: 1px solid #ddd; padding: 4px 8px; text-align: left;}
+        th    {background: #f6f6f6;}
+    </style>
+</head>
+<body>
+    <h2>Routes</h2>
+    <table>
+        <tr><th>Domain</th><th>Method</th><th>Route</th><th>Type</th><th>Handler</th><th>Source</th></tr>
+        {{range .routes}}<tr><td>{{.Domain}}</td><td>{{.Method}}</td><td>{{.Route}}</td><td>{{.Type}}</td><td>{{.Handler}}</td><td>{{.Source}}</td></tr>
+        {{end}}
+    </table>
+    <h2>Hooks</h2>
+    <table>
+        <tr><th>Domain</th><th>Hook</th><th>Priority</th><th>Method</th><th>Route</th><th>Handler</th><th>Source</th></tr>
+        {{range .hooks}}<tr><td>{{.Domain}}</td><td>{{.Hook}}</td><td>{{.Priority}}</td><td>{{.Method}}</td><td>{{.Route}}</td><td>{{.Handler}}</td><td>{{.Source}}</td></tr>
+        {{end}}
+    </table>
+    <h2>Rules</h2>
+    <table>
+        <tr><th>Domain</th><th>Rule</th><th>Priority</th><th>Method</th><th>Route</th><th>Source</th></tr>
+        {{range .rules}}<tr><td>{{.Domain}}</td><td>{{.Rule}}</td><td>{{.Priority}}</td><td>{{.Method}}</td><td>{{.Route}}</td><td>{{.Source}}</td></tr>
+        {{end}}
+    </table>
+    <h2>Status Handlers</h2>
+    <table>
+        <tr><th>Domain</th><th>Status</th><th>Handler</th></tr>
+        {{range .status}}<tr><td>{{.Domain}}</td><td>{{.Status}}</td><td>{{.Handler}}</td></tr>
+        {{end}}
+    </table>
+</body>
+</html>`))
@@ -0,0 +1,111 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 图形验证码服务.
+
+package ghttp
+
+import (
+    "time"
+    "strings"
+    "net/http"
+    "gitee.com/johng/gf/g/os/gtime"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/util/gcaptcha"
+)
+
+const (
+    gCAPTCHA_SESSION_PREFIX = "gcaptcha." // Session中保存验证码答案的键名前缀
+    gCAPTCHA_SESSION_ID     = "default"   // Session模式下的验证码ID
+    gCAPTCHA_ID_MAX_LENGTH  = 64          // ID模式下验证码ID的最大长度
+)
+
+// 基于Session的验证码答案存储(每个Session同时只保存一个验证码)
+type sessionCaptchaStore struct {
+    session *Session
+}
+
+// 绑定图形验证码处理方法，访问时输出PNG格式的验证码图片，c参数为自定义的验证码对象，默认使用gcaptcha默认验证码对象；
+// 默认将答案保存在Session中，通过r.VerifyCaptcha校验；
+// 请求带有id参数时(如：/captcha?id=xxx)将答案保存到验证码对象的存储中，可以通过gcaptcha.Verify校验，
+// 或者通过gvalid.SetCaptchaVerifier(gcaptcha.Verify)设置后使用gvalid的captcha:field规则校验；
+// id只能由字母、数字、"_"及"-"组成，并且长度不超过64，否则返回400状态码；
+// 由于id由客户端指定，gcaptcha默认的内存存储限制了最大答案数量(超过后按照LRU淘汰)，使用自定义存储时需要自行限制存储大小。
+func (s *Server) BindCaptcha(pattern string, c...*gcaptcha.Captcha) error {
+    return s.BindHandler(pattern, func(r *Request) {
+        captcha := gcaptcha.Instance()
+        if len(c) > 0 && c[0] != nil {
+            captcha = c[0]
+        }
+        id := r.GetQueryString("id")
+        if id == "" {
+            id      = gCAPTCHA_SESSION_ID
+            captcha = captcha.WithStore(&sessionCaptchaStore{r.Session})
+        } else if !isValidCaptchaId(id) {
+            r.Response.WriteStatus(http.StatusBadRequest)
+            return
+        }
+        _, image, err := captcha.Generate(id)
+        if err != nil {
+            r.Response.WriteStatus(http.StatusInternalServerError, err.Error())
+            return
+        }
+        r.Response.Header().Set("Content-Type",  "image/png")
+        r.Response.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+        r.Response.Write(image)
+    })
+}
+
+// 域名下绑定图形验证码处理方法
+func (d *Domain) BindCaptcha(pattern string, c...*gcaptcha.Captcha) error {
+    for domain, _ := range d.m {
+        if err := d.s.BindCaptcha(pattern + "@" + domain, c...); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// 校验保存在Session中的图形验证码，不区分大小写，无论校验是否成功，验证码都会被删除(只能校验一次)
+func (r *Request) VerifyCaptcha(answer string) bool {
+    return gcaptcha.Instance().WithStore(&sessionCaptchaStore{r.Session}).Verify(gCAPTCHA_SESSION_ID, answer)
+}
+
+// 判断客户端提交的验证码ID是否合法，防止使用任意内容作为存储键名
+func isValidCaptchaId(id string) bool {
+    if len(id) > gCAPTCHA_ID_MAX_LENGTH {
+        return false
+    }
+    for i := 0; i < len(id); i++ {
+        c := id[i]
+        if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+            return false
+        }
+    }
+    return true
+}
+
+// 答案及过期时间(毫秒)使用"|"连接保存
+func (s *sessionCaptchaStore) Set(id, answer string, expire time.Duration) error {
+    s.session.Set(gCAPTCHA_SESSION_PREFIX + id, answer + "|" + gconv.String(gtime.Millisecond() + int64(expire/time.Millisecond)))
+    return nil
+}
+
+// 获取及删除在Session数据的锁内完成，防止同一Session的并发请求重复校验同一个答案
+func (s *sessionCaptchaStore) Get(id string, clear bool) string {
+    key   := gCAPTCHA_SESSION_PREFIX + id
+    value := ""
+    s.session.data.LockFunc(func(m map[string]interface{}) {
+        value = gconv.String(m[key])
+        if clear {
+            delete(m, key)
+        }
+    })
+    pos := strings.LastIndexByte(value, '|')
+    if pos < 0 || gconv.Int64(value[pos + 1:]) < gtime.Millisecond() {
+        return ""
+    }
+    return value[:pos]
+}
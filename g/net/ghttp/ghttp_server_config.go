@@ -8,6 +8,7 @@
 package ghttp
 
 import (
+    "crypto/tls"
     "time"
     "net/http"
     "strconv"
@@ -28,10 +29,14 @@ const (
 // HTTP Server 设置结构体，静态配置
 type ServerConfig struct {
     // 底层http对象配置
-    Addr             string        // 监听IP和端口，监听本地所有IP使用":端口"(支持多个地址，使用","号分隔)
+    Addr             string        // 监听IP和端口，监听本地所有IP使用":端口"(支持多个地址，使用","号分隔)，也支持unix socket，例如：unix:/run/app.sock
     HTTPSAddr        string        // HTTPS服务监听地址(支持多个地址，使用","号分隔)
     HTTPSCertPath    string        // HTTPS证书文件路径
     HTTPSKeyPath     string        // HTTPS签名文件路径
+    TLSMinVersion    uint16        // HTTPS最低TLS版本，例如：tls.VersionTLS12，为0时使用默认值
+    TLSCipherSuites  []uint16      // HTTPS允许的加密套件，为空时使用默认值
+    TLSClientAuth    tls.ClientAuthType // HTTPS客户端证书校验方式，例如：tls.RequireAndVerifyClientCert
+    TLSClientCAPath  string        // 校验客户端证书的CA证书文件路径(PEM格式，可以包含多个证书)
     Handler          http.Handler  // 默认的处理函数
     ReadTimeout      time.Duration
     WriteTimeout     time.Duration
@@ -42,21 +47,30 @@ type ServerConfig struct {
     IndexFolder      bool          // 如果访问目录是否显示目录列表
     ServerAgent      string        // server agent
     ServerRoot       string        // 服务器服务的本地目录根路径
+    CacheControl     map[string]string // 静态文件按照扩展名设置的Cache-Control，如：{".js" : "max-age=86400"}，键名"*"表示默认值
     // 日志配置
     LogPath          string       // 存放日志的目录路径
     LogHandler       func(r *Request, error ... interface{})  // 自定义日志处理回调方法
     ErrorLogEnabled  bool         // 是否开启error log
     AccessLogEnabled bool         // 是否开启access log
+    AccessLogFormat  string       // access log格式：default(默认)、combined、json，或者自定义模板，具体见SetAccessLogFormat
+    ErrorMode        string       // 错误页面模式：prod(默认)、dev(输出包含错误堆栈的调试页面)
     // COOKIE
     CookieMaxAge     int          // Cookie有效期
+    CookieSecret     string       // 签名及加密Cookie使用的密钥
+    CookieOldSecrets []string     // 轮换下来的旧密钥，使用旧密钥签名或者加密的Cookie仍然可以被读取
     // SESSION
     SessionMaxAge    int          // Session有效期
     SessionIdName    string       // SessionId名称
+    // 文件上传
+    UploadConfig     UploadConfig // 默认的文件上传配置，可通过SetUploadConfig按照路由单独设置
     // 其他设置
     NameToUriType    int          // 服务注册时对象和方法名称转换为URI时的规则
+    Debug            bool         // 调试模式，开启后服务启动时打印所有注册的路由、事件回调及状态码回调
     // ip访问控制
-    DenyIps          []string     // 不允许访问的ip列表，支持ip前缀过滤，如: 10 将不允许10开头的ip访问
-    AllowIps         []string     // 仅允许访问的ip列表，支持ip前缀过滤，如: 10 将仅允许10开头的ip访问
+    DenyIps          []string     // 不允许访问的ip列表，支持IPv4/IPv6地址、CIDR网段(如: 10.0.0.0/8、fd00::/8)及ip前缀(如: 10 将不允许10开头的ip访问)
+    AllowIps         []string     // 仅允许访问的ip列表，格式同DenyIps，DenyIps优先
+    TrustedProxies   []string     // 可信代理列表，格式同DenyIps，只有来自可信代理的请求才会使用X-Forwarded-For/X-Real-IP中的客户端ip
     // 路由访问控制
     DenyRoutes       []string     // 不允许访问的路由规则列表
     // Gzip压缩文件类型
@@ -84,6 +98,8 @@ var defaultServerConfig = ServerConfig {
     ErrorLogEnabled  : true,
 
     GzipContentTypes : defaultGzipContentTypes,
+
+    UploadConfig     : defaultUploadConfig,
 }
 
 // 获取默认的http server设置
@@ -98,7 +114,7 @@ func (s *Server)SetConfig(c ServerConfig) {
         glog.Error("cannot be changed while running")
     }
     if c.Handler == nil {
-        c.Handler = http.HandlerFunc(s.defaultHttpHandle)
+        c.Handler = s
     }
     s.config = c
     // 需要处理server root最后的目录分隔符号
@@ -120,10 +136,15 @@ func (s *Server)SetConfig(c ServerConfig) {
     s.SetLogHandler(c.LogHandler)
     s.SetErrorLogEnabled(c.ErrorLogEnabled)
     s.SetAccessLogEnabled(c.AccessLogEnabled)
+    s.SetAccessLogFormat(c.AccessLogFormat)
+    s.SetErrorMode(c.ErrorMode)
 
     if c.CookieMaxAge > 0 {
         s.SetCookieMaxAge(c.CookieMaxAge)
     }
+    if len(c.CookieSecret) > 0 {
+        s.SetCookieSecret(c.CookieSecret, c.CookieOldSecrets...)
+    }
     if c.SessionMaxAge > 0 {
         s.SetSessionMaxAge(c.SessionMaxAge)
     }
@@ -182,7 +203,8 @@ func (s *Server)SetHTTPSPort(port...int) {
     }
 }
 
-// 开启HTTPS支持，但是必须提供Cert和Key文件
+// 开启HTTPS支持，但是必须提供Cert和Key文件，该证书作为默认证书，
+// 证书文件变化时会自动重载，按照域名设置证书请使用s.Domain(domains).EnableHTTPS
 func (s *Server)EnableHTTPS(certFile, keyFile string) {
     if s.Status() == SERVER_STATUS_RUNNING {
         glog.Error("cannot be changed while running")
@@ -246,6 +268,14 @@ func (s *Server)SetIndexFolder(index bool) {
     
 }
 
+// 设置调试模式，开启后服务启动时打印所有注册的路由、事件回调及状态码回调
+func (s *Server)SetDebug(debug bool) {
+    if s.Status() == SERVER_STATUS_RUNNING {
+        glog.Error("cannot be changed while running")
+    }
+    s.config.Debug = debug
+}
+
 // 设置http server参数 - ServerAgent
 func (s *Server)SetServerAgent(agent string) {
     if s.Status() == SERVER_STATUS_RUNNING {
@@ -269,19 +299,34 @@ func (s *Server)SetServerRoot(root string) {
 }
 
 func (s *Server) SetDenyIps(ips []string) {
-    if s.Status() == SERVER_STATUS_RUNNING {
+    // IP访问控制规则在请求处理初始化时解析，此后请求协程并发读取，不能再修改
+    if s.isServing() {
         glog.Error("cannot be changed while running")
+        return
     }
     s.config.DenyIps = ips
 }
 
 func (s *Server) SetAllowIps(ips []string) {
-    if s.Status() == SERVER_STATUS_RUNNING {
+    // IP访问控制规则在请求处理初始化时解析，此后请求协程并发读取，不能再修改
+    if s.isServing() {
         glog.Error("cannot be changed while running")
+        return
     }
     s.config.AllowIps = ips
 }
 
+// 设置可信代理列表(格式同DenyIps)，只有直接连接的客户端为可信代理时，
+// GetClientIp才会从X-Forwarded-For/X-Real-IP中获取真实的客户端IP
+func (s *Server) SetTrustedProxies(proxies []string) {
+    // IP访问控制规则在请求处理初始化时解析，此后请求协程并发读取，不能再修改
+    if s.isServing() {
+        glog.Error("cannot be changed while running")
+        return
+    }
+    s.config.TrustedProxies = proxies
+}
+
 func (s *Server) SetDenyRoutes(routes []string) {
     if s.Status() == SERVER_STATUS_RUNNING {
         glog.Error("cannot be changed while running")
@@ -332,6 +377,22 @@ func (s *Server)SetAccessLogEnabled(enabled bool) {
     s.accessLogEnabled.Set(enabled)
 }
 
+// 设置access log日志格式，可选值：
+// default : 默认格式；
+// combined: Apache combined格式；
+// json    : 每行一条JSON格式的日志；
+// 其他值作为自定义模板，模板中的{字段名}将被替换为对应的值，可用字段：
+// {time}、{request_id}、{method}、{host}、{uri}、{proto}、{status}、{bytes}、{latency}(微秒)、{ip}、{referer}、{agent}、{route}，
+// 例如："{time} {request_id} {method} {uri} {status} {latency}us {route}"
+func (s *Server)SetAccessLogFormat(format string) {
+    if format == "" {
+        format = ACCESS_LOG_FORMAT_DEFAULT
+    }
+    s.accessLogFormat.Set(format)
+    // 结构化日志及combined格式自带时间字段，不需要日志对象输出的时间头信息
+    s.accessLogger.SetHeaderPrint(format == ACCESS_LOG_FORMAT_DEFAULT)
+}
+
 // 设置是否开启error log日志功能
 func (s *Server)SetErrorLogEnabled(enabled bool) {
     s.errorLogEnabled.Set(enabled)
@@ -370,6 +431,11 @@ func (s *Server)IsAccessLogEnabled() bool {
     return s.accessLogEnabled.Val()
 }
 
+// 获取access log日志格式
+func (s *Server)GetAccessLogFormat() string {
+    return s.accessLogFormat.Val()
+}
+
 // error log日志功能是否开启
 func (s *Server)IsErrorLogEnabled() bool {
     return s.errorLogEnabled.Val()
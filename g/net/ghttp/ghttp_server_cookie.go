@@ -29,15 +29,28 @@ type Cookie struct {
 // cookie项
 type CookieItem struct {
     value    string
-    domain   string // 有效域名
-    path     string // 有效路径
-    expire   int    // 过期时间
+    domain   string        // 有效域名
+    path     string        // 有效路径
+    expire   int           // 过期时间
     httpOnly bool
+    secure   bool          // 是否只在HTTPS下发送
+    sameSite http.SameSite // 跨站请求时是否发送
+}
+
+// 设置cookie时的详细参数，未设置的参数使用默认值
+type CookieOptions struct {
+    Domain   string        // 有效域名，默认为当前请求的域名
+    Path     string        // 有效路径，默认为"/"
+    MaxAge   int           // 有效期(秒)，默认为Server的CookieMaxAge，小于0表示删除cookie
+    Expires  time.Time     // 过期时间，设置后MaxAge无效
+    HttpOnly bool          // 是否禁止客户端脚本访问
+    Secure   bool          // 是否只在HTTPS下发送
+    SameSite http.SameSite // 跨站请求时是否发送，如：http.SameSiteLaxMode、http.SameSiteStrictMode
 }
 
 // 获取或者创建一个cookie对象，与传入的请求对应
 func GetCookie(r *Request) *Cookie {
-    if v := r.Server.cookies.Get(r.Id); v != nil {
+    if v := r.Server.cookies.Get(r.id); v != nil {
         return v.(*Cookie)
     }
     c := &Cookie {
@@ -48,7 +61,7 @@ func GetCookie(r *Request) *Cookie {
         response : r.Response,
     }
     c.init()
-    r.Server.cookies.Set(r.Id, c)
+    r.Server.cookies.Set(r.id, c)
     return c
 }
 
@@ -56,7 +69,11 @@ func GetCookie(r *Request) *Cookie {
 func (c *Cookie) init() {
     for _, v := range c.request.Cookies() {
         c.data[v.Name] = CookieItem {
-            v.Value, v.Domain, v.Path, v.Expires.Second(), v.HttpOnly,
+            value    : v.Value,
+            domain   : v.Domain,
+            path     : v.Path,
+            expire   : v.Expires.Second(),
+            httpOnly : v.HttpOnly,
         }
     }
 }
@@ -89,18 +106,40 @@ func (c *Cookie) SetSessionId(sessionid string)  {
 
 // 设置cookie，使用默认参数
 func (c *Cookie) Set(key, value string) {
-    c.SetCookie(key, value, c.domain, gDEFAULT_COOKIE_PATH, c.server.GetCookieMaxAge())
+    c.SetCookie(key, value)
 }
 
-// 设置cookie，带详细cookie参数
-func (c *Cookie) SetCookie(key, value, domain, path string, maxAge int, httpOnly ... bool) {
-    c.mu.Lock()
-    isHttpOnly := false
-    if len(httpOnly) > 0 {
-        isHttpOnly = httpOnly[0]
+// 设置cookie，带详细cookie参数，例如：
+// c.SetCookie("token", value, CookieOptions{MaxAge : 86400, HttpOnly : true, SameSite : http.SameSiteLaxMode})
+func (c *Cookie) SetCookie(key, value string, options...CookieOptions) {
+    option := CookieOptions{}
+    if len(options) > 0 {
+        option = options[0]
+    }
+    if option.Domain == "" {
+        option.Domain = c.domain
     }
+    if option.Path == "" {
+        option.Path = gDEFAULT_COOKIE_PATH
+    }
+    expire := 0
+    if !option.Expires.IsZero() {
+        expire = int(option.Expires.Unix())
+    } else {
+        if option.MaxAge == 0 {
+            option.MaxAge = c.server.GetCookieMaxAge()
+        }
+        expire = int(gtime.Second()) + option.MaxAge
+    }
+    c.mu.Lock()
     c.data[key] = CookieItem {
-        value, domain, path, int(gtime.Second()) + maxAge, isHttpOnly,
+        value    : value,
+        domain   : option.Domain,
+        path     : option.Path,
+        expire   : expire,
+        httpOnly : option.HttpOnly,
+        secure   : option.Secure,
+        sameSite : option.SameSite,
     }
     c.mu.Unlock()
 }
@@ -122,12 +161,12 @@ func (c *Cookie) Get(key string) string {
 // 标记该cookie在对应的域名和路径失效
 // 删除cookie的重点是需要通知浏览器客户端cookie已过期
 func (c *Cookie) Remove(key, domain, path string) {
-    c.SetCookie(key, "", domain, path, -86400)
+    c.SetCookie(key, "", CookieOptions{Domain : domain, Path : path, MaxAge : -86400})
 }
 
 // 请求完毕后删除已经存在的Cookie对象
 func (c *Cookie) Close() {
-    c.server.cookies.Remove(c.request.Id)
+    c.server.cookies.Remove(c.request.id)
 }
 
 // 输出到客户端
@@ -147,6 +186,8 @@ func (c *Cookie) Output() {
                 Path     : v.path,
                 Expires  : time.Unix(int64(v.expire), 0),
                 HttpOnly : v.httpOnly,
+                Secure   : v.secure,
+                SameSite : v.sameSite,
             },
         )
     }
@@ -0,0 +1,124 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 签名及加密Cookie(防篡改的"记住我"令牌、保存在客户端的少量状态数据等).
+
+package ghttp
+
+import (
+    "errors"
+    "strings"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/base64"
+    "gitee.com/johng/gf/g/crypto/gaes"
+)
+
+var (
+    ErrCookieSecretNotSet = errors.New("cookie secret is not set")
+)
+
+// cookie密钥(由Server的cookie secret派生，签名和加密使用不同的密钥)
+type cookieSecret struct {
+    signKey    []byte // HMAC签名密钥
+    encryptKey []byte // AES-GCM加密密钥(32字节，AES-256)
+}
+
+// 设置签名及加密cookie使用的密钥，secret为当前使用的密钥，
+// oldSecrets为已经轮换下来的旧密钥，使用旧密钥签名或者加密的cookie仍然可以被正确读取，
+// 新设置的cookie只使用当前密钥，旧的cookie全部过期后可以将旧密钥移除。
+func (s *Server) SetCookieSecret(secret string, oldSecrets...string) {
+    s.config.CookieSecret     = secret
+    s.config.CookieOldSecrets = oldSecrets
+    secrets := make([]cookieSecret, 0, len(oldSecrets) + 1)
+    for _, v := range append([]string{secret}, oldSecrets...) {
+        if v == "" {
+            continue
+        }
+        signKey    := sha256.Sum256([]byte("sign:" + v))
+        encryptKey := sha256.Sum256([]byte("encrypt:" + v))
+        secrets = append(secrets, cookieSecret{signKey[:], encryptKey[:]})
+    }
+    s.cookieSecrets.Set(secrets)
+}
+
+// 获取cookie密钥列表，第一个为当前密钥
+func (s *Server) getCookieSecrets() []cookieSecret {
+    if v := s.cookieSecrets.Val(); v != nil {
+        return v.([]cookieSecret)
+    }
+    return nil
+}
+
+// 设置签名cookie，cookie值本身不加密(客户端可见)，但是被修改后将无法通过GetSigned读取，
+// options参数同SetCookie
+func (c *Cookie) SetSigned(key, value string, options...CookieOptions) error {
+    secrets := c.server.getCookieSecrets()
+    if len(secrets) == 0 {
+        return ErrCookieSecretNotSet
+    }
+    content := base64.RawURLEncoding.EncodeToString([]byte(value))
+    c.SetCookie(key, content + "." + signCookie(secrets[0].signKey, key, content), options...)
+    return nil
+}
+
+// 获取签名cookie的值，cookie不存在、签名不正确(被篡改)时返回空字符串
+func (c *Cookie) GetSigned(key string) string {
+    value := c.Get(key)
+    pos   := strings.LastIndexByte(value, '.')
+    if pos <= 0 {
+        return ""
+    }
+    content, signature := value[:pos], value[pos + 1:]
+    for _, secret := range c.server.getCookieSecrets() {
+        if hmac.Equal([]byte(signature), []byte(signCookie(secret.signKey, key, content))) {
+            if b, err := base64.RawURLEncoding.DecodeString(content); err == nil {
+                return string(b)
+            }
+            return ""
+        }
+    }
+    return ""
+}
+
+// 设置加密cookie(AES-GCM)，客户端无法查看及修改cookie值，options参数同SetCookie
+func (c *Cookie) SetEncrypted(key, value string, options...CookieOptions) error {
+    secrets := c.server.getCookieSecrets()
+    if len(secrets) == 0 {
+        return ErrCookieSecretNotSet
+    }
+    // 将cookie名称作为附加认证数据，防止将加密值复制到其他cookie中使用
+    b, err := gaes.EncryptGCM([]byte(value), secrets[0].encryptKey, []byte(key))
+    if err != nil {
+        return err
+    }
+    c.SetCookie(key, base64.RawURLEncoding.EncodeToString(b), options...)
+    return nil
+}
+
+// 获取加密cookie的值，cookie不存在、无法解密(被篡改或者密钥已移除)时返回空字符串
+func (c *Cookie) GetEncrypted(key string) string {
+    value := c.Get(key)
+    if value == "" {
+        return ""
+    }
+    b, err := base64.RawURLEncoding.DecodeString(value)
+    if err != nil {
+        return ""
+    }
+    for _, secret := range c.server.getCookieSecrets() {
+        if plainText, err := gaes.DecryptGCM(b, secret.encryptKey, []byte(key)); err == nil {
+            return string(plainText)
+        }
+    }
+    return ""
+}
+
+// 计算cookie签名，签名内容包含cookie名称，防止将签名值复制到其他cookie中使用
+func signCookie(signKey []byte, key, content string) string {
+    mac := hmac.New(sha256.New, signKey)
+    mac.Write([]byte(key + "=" + content))
+    return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
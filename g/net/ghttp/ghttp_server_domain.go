@@ -21,7 +21,9 @@ type Domain struct {
 // 域名对象表，用以存储和检索域名(支持多域名)与域名对象之间的关联关系
 var domainMap = gmap.NewStringInterfaceMap()
 
-// 生成一个域名对象
+// 生成一个域名对象，多个域名使用","分隔，支持域名模式(只匹配一级域名标签)：
+// 通配符，例如：*.example.com；命名参数，例如：{tenant}.example.com，可通过r.GetRouterString("tenant")获取；
+// 路由检索顺序为：默认域名、完全相同的域名、域名模式(固定字符越多优先级越高，相同时按照注册顺序)。
 func (s *Server) Domain(domains string) *Domain {
     if r := domainMap.Get(domains); r != nil {
         return r.(*Domain)
@@ -32,16 +34,18 @@ func (s *Server) Domain(domains string) *Domain {
     }
     result := strings.Split(domains, ",")
     for _, v := range result {
-        d.m[strings.TrimSpace(v)] = true
+        v = strings.TrimSpace(v)
+        d.m[v] = true
+        s.addDomainPattern(v)
     }
     domainMap.Set(domains, d)
     return d
 }
 
 // 注意该方法是直接绑定方法的内存地址，执行的时候直接执行该方法，不会存在初始化新的控制器逻辑
-func (d *Domain) BindHandler(pattern string, handler HandlerFunc) error {
+func (d *Domain) BindHandler(pattern string, handler HandlerFunc, name...string) error {
     for domain, _ := range d.m {
-        if err := d.s.BindHandler(pattern + "@" + domain, handler); err != nil {
+        if err := d.s.BindHandler(pattern + "@" + domain, handler, name...); err != nil {
             return err
         }
     }
@@ -114,6 +118,16 @@ func (d *Domain) BindControllerRest(pattern string, c Controller) error {
     return nil
 }
 
+// 绑定WebSocket服务
+func (d *Domain) BindWebSocket(pattern string, handler WebSocketHandler) error {
+    for domain, _ := range d.m {
+        if err := d.s.BindWebSocket(pattern + "@" + domain, handler); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
 // 绑定指定的hook回调函数, hook参数的值由ghttp server设定，参数不区分大小写
 // 目前hook支持：Init/Shut
 func (d *Domain)BindHookHandler(pattern string, hook string, handler HandlerFunc) error {
@@ -10,6 +10,7 @@ import (
     "os"
     "fmt"
     "net"
+    "strings"
     "context"
     "net/http"
     "crypto/tls"
@@ -69,8 +70,7 @@ func (s *gracefulServer) ListenAndServe() error {
 // 获得文件描述符
 func (s *gracefulServer) Fd() uintptr {
     if s.rawListener != nil {
-        file, err := s.rawListener.(*net.TCPListener).File()
-        if err == nil {
+        if file, err := listenerFile(s.rawListener); err == nil {
             return file.Fd()
         }
     }
@@ -82,27 +82,13 @@ func (s *gracefulServer) setFd(fd int) {
     s.fd = uintptr(fd)
 }
 
-// 执行HTTPS监听
-func (s *gracefulServer) ListenAndServeTLS(certFile, keyFile string) error {
-    addr   := s.httpServer.Addr
-    config := &tls.Config{}
-    if s.httpServer.TLSConfig != nil {
-        *config = *s.httpServer.TLSConfig
-    }
-    if config.NextProtos == nil {
-        config.NextProtos = []string{"http/1.1"}
-    }
-    var err error
-    config.Certificates         = make([]tls.Certificate, 1)
-    config.Certificates[0], err = tls.LoadX509KeyPair(certFile, keyFile)
-    if err != nil {
-        return err
-    }
+// 执行HTTPS监听，证书通过config.GetCertificate动态获取，证书重载时不需要重启服务
+func (s *gracefulServer) ListenAndServeTLS(config *tls.Config) error {
+    addr    := s.httpServer.Addr
     ln, err := s.getNetListener(addr)
     if err != nil {
         return err
     }
-
     s.listener    = tls.NewListener(ln, config)
     s.rawListener = ln
     return s.doServe()
@@ -120,7 +106,8 @@ func (s *gracefulServer) getProto() string {
 // 开始执行Web Server服务处理
 func (s *gracefulServer) doServe() error {
     action := "started"
-    if s.fd != 0 {
+    // systemd socket activation同样会传递文件描述符，只有平滑重启的子进程才是重载
+    if s.fd != 0 && gproc.IsChild() {
         action = "reloaded"
     }
     glog.Printfln("%d: %s server %s listening on [%s]", gproc.Pid(), s.getProto(), action, s.addr)
@@ -134,6 +121,10 @@ func (s *gracefulServer) doServe() error {
 func (s *gracefulServer) getNetListener(addr string) (net.Listener, error) {
     var ln net.Listener
     var err error
+    if s.rawListener != nil {
+        // 开发者通过SetListener设置的监听对象
+        return s.rawListener, nil
+    }
     if s.fd > 0 {
         f      := os.NewFile(s.fd, "")
         ln, err = net.FileListener(f)
@@ -144,7 +135,11 @@ func (s *gracefulServer) getNetListener(addr string) (net.Listener, error) {
     } else {
         // 如果监听失败，1秒后重试，最多重试3次
         for i := 0; i < 3; i++ {
-            ln, err = net.Listen("tcp", addr)
+            if strings.HasPrefix(addr, gUNIX_SOCKET_PREFIX) {
+                ln, err = listenUnixSocket(addr[len(gUNIX_SOCKET_PREFIX):])
+            } else {
+                ln, err = net.Listen("tcp", addr)
+            }
             if err != nil {
                 err = fmt.Errorf("%d: net.Listen error: %v", gproc.Pid(), err)
                 time.Sleep(time.Second)
@@ -20,8 +20,14 @@ import (
     "gitee.com/johng/gf/g/encoding/ghtml"
 )
 
-// 默认HTTP Server处理入口，http包底层默认使用了gorutine异步处理请求，所以这里不再异步执行
-func (s *Server)defaultHttpHandle(w http.ResponseWriter, r *http.Request) {
+// 默认HTTP Server处理入口(实现http.Handler接口)，http包底层默认使用了gorutine异步处理请求，所以这里不再异步执行；
+// Server作为http.Handler挂载到其他http.Server时没有调用Start，因此这里需要完成请求处理的初始化，
+// 初始化失败(例如IP访问控制规则错误)时所有请求返回500
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    if err := s.prepareServe(); err != nil {
+        http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+        return
+    }
     s.handleRequest(w, r)
 }
 
@@ -37,45 +43,46 @@ func (s *Server)handleRequest(w http.ResponseWriter, r *http.Request) {
 
     // 创建请求处理对象
     request := newRequest(s, r, w)
+    // 请求标识处理(开启请求标识中间件时有效)
+    request.initRequestId()
+    // 监控指标统计对象(开启监控指标时有效)
+    metricsItem := (*metricsRoute)(nil)
 
     defer func() {
         if request.LeaveTime == 0 {
             request.LeaveTime = gtime.Microsecond()
         }
-        // access log
-        s.handleAccessLog(request)
-        // error log使用recover进行判断
+        // 请求结束时关闭SSE(服务执行产生panic时)，防止异步推送在请求结束后继续写入
+        if request.Response.sse != nil {
+            request.Response.sse.Close()
+        }
+        // panic恢复处理(错误日志及错误页面)
         if e := recover(); e != nil {
-            s.handleErrorLog(e, request)
+            s.handleRecovery(e, request)
         }
+        // access log(需要在错误处理之后，以便记录最终的状态码)
+        s.handleAccessLog(request)
+        // 监控指标统计(需要在错误处理之后，以便记录最终的状态码)
+        s.metricsEnd(metricsItem, request)
         // 将Request对象指针丢到队列中异步关闭
         s.closeQueue.PushBack(request)
     }()
 
-    // 优先执行静态文件检索
-    filePath := s.paths.Search(r.URL.Path)
-    if filePath != "" {
-        if gfile.IsDir(filePath) {
-            // 如果是目录需要处理index files
-            if len(s.config.IndexFiles) > 0 {
-                for _, file := range s.config.IndexFiles {
-                    fpath := s.paths.Search(filePath + gfile.Separator + file)
-                    if fpath != "" {
-                        filePath              = fpath
-                        request.isFileRequest = true
-                        break
-                    }
-                }
-            }
-        } else {
-            request.isFileRequest = true
-        }
+    // 优先执行静态文件检索(SPA回退文件的优先级低于服务路由)
+    filePath, isSpaFile := s.searchStaticFile(r.URL.Path)
+    if filePath != "" && !isSpaFile && !gfile.IsDir(filePath) {
+        request.isFileRequest = true
+    }
+
+    // 域名模式参数(例如：{tenant}.example.com)，可被同名的路由参数覆盖
+    for k, v := range request.getDomainVars() {
+        request.routerVars[k] = v
     }
 
     // 其次进行服务路由信息检索
     handler := (*handlerItem)(nil)
     if !request.IsFileRequest() {
-        if parsedItem := s.getServeHandlerWithCache(request); parsedItem != nil {
+        if parsedItem := s.getServeHandler(request); parsedItem != nil {
             handler = parsedItem.handler
             for k, v := range parsedItem.values {
                 request.routerVars[k] = v
@@ -83,9 +90,24 @@ func (s *Server)handleRequest(w http.ResponseWriter, r *http.Request) {
             request.Router = parsedItem.handler.router
         }
     }
-
-    // 事件 - BeforeServe
-    s.callHookHandler(HOOK_BEFORE_SERVE, request)
+    metricsItem = s.metricsBegin(request)
+
+    // IP访问控制(服务及域名级别)，不允许访问的请求不再执行BeforeServe事件
+    if !s.isIpAllowed(request) {
+        request.Response.WriteStatus(http.StatusForbidden)
+        request.Exit()
+    } else if runnerBuildError.Val() != "" {
+        // 开发环境热编译(grun)失败时，所有请求都输出编译错误页面，直到编译成功后重启
+        s.serveBuildError(request)
+        request.Exit()
+    } else {
+        // 路由规则(IP访问控制、JWT认证、上传配置等)，被规则终止的请求不再执行BeforeServe事件
+        s.callRouteRules(request)
+        // 事件 - BeforeServe
+        if !request.IsExited() {
+            s.callHookHandler(HOOK_BEFORE_SERVE, request)
+        }
+    }
 
     // 执行静态文件服务/回调控制器/执行对象/方法
     if !request.exit.Val() {
@@ -100,6 +122,11 @@ func (s *Server)handleRequest(w http.ResponseWriter, r *http.Request) {
         }
     }
 
+    // 服务执行结束后立即关闭SSE，停止心跳等异步推送，防止与之后的header及缓冲区输出并发执行
+    if request.Response.sse != nil {
+        request.Response.sse.Close()
+    }
+
     // 事件 - AfterServe
     s.callHookHandler(HOOK_AFTER_SERVE, request)
 
@@ -164,8 +191,8 @@ func (s *Server)serveFile(r *Request, path string) {
             r.Response.WriteStatus(http.StatusForbidden)
         }
     } else {
-        // 读取文件内容返回, no buffer
-        http.ServeContent(r.Response.Writer, &r.Request, info.Name(), info.ModTime(), f)
+        // 读取文件内容返回
+        s.serveStaticFile(r, path, f, info)
     }
 }
 
@@ -202,6 +229,8 @@ func (s *Server) startCloseQueueLoop() {
                 r.Cookie.Close()
                 // 更新Session会话超时时间
                 r.Session.UpdateExpire()
+                // 删除上传文件产生的临时文件
+                r.removeUploadTmpFiles()
                 s.callHookHandler(HOOK_AFTER_CLOSE, r)
             }
         }
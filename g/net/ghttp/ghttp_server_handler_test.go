@@ -0,0 +1,45 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package ghttp
+
+import (
+    "testing"
+    "net/http"
+    "net/http/httptest"
+)
+
+func Test_Server_ServeHTTP(t *testing.T) {
+    s := GetServer("handler-serve-http")
+    s.BindHandler("/hello", func(r *Request) {
+        r.Response.Write("hello")
+    })
+    w := httptest.NewRecorder()
+    s.ServeHTTP(w, httptest.NewRequest("GET", "/hello", nil))
+    if w.Code != http.StatusOK || w.Body.String() != "hello" {
+        t.Error("unexpected response:", w.Code, w.Body.String())
+    }
+    // 作为http.Handler处理请求后不能再注册路由
+    if err := s.BindHandler("/world", func(r *Request) {}); err == nil {
+        t.Error("route registered while serving")
+    }
+}
+
+func Test_Server_ServeHTTP_PrepareError(t *testing.T) {
+    s := GetServer("handler-prepare-error")
+    s.SetDenyIps([]string{"invalid-ip/99"})
+    s.BindHandler("/hello", func(r *Request) {
+        r.Response.Write("hello")
+    })
+    w := httptest.NewRecorder()
+    s.ServeHTTP(w, httptest.NewRequest("GET", "/hello", nil))
+    if w.Code != http.StatusInternalServerError {
+        t.Error("expected 500, got:", w.Code)
+    }
+    if _, err := NewTestClient(s).R().Get("/hello"); err == nil {
+        t.Error("expected prepare error from test client")
+    }
+}
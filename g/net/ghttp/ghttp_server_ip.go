@@ -0,0 +1,257 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// IP访问控制(服务、域名及路由级别的黑白名单)及可信代理.
+
+package ghttp
+
+import (
+    "net"
+    "errors"
+    "strings"
+    "strconv"
+    "net/http"
+    "gitee.com/johng/gf/g/net/gipv4"
+    "gitee.com/johng/gf/g/net/gipv6"
+)
+
+// IP访问控制规则，deny优先于allow，allow不为空时仅允许allow中的IP访问
+type ipAccessList struct {
+    allow   []*net.IPNet // 仅允许访问的IP网段
+    deny    []*net.IPNet // 不允许访问的IP网段
+    denyAll bool         // 是否拒绝所有访问(规则解析失败时)
+}
+
+// 创建IP访问控制规则，规则格式见parseIpNet
+func newIpAccessList(allowIps, denyIps []string) (*ipAccessList, error) {
+    allow, err := parseIpNets(allowIps)
+    if err != nil {
+        return nil, err
+    }
+    deny, err := parseIpNets(denyIps)
+    if err != nil {
+        return nil, err
+    }
+    return &ipAccessList {
+        allow : allow,
+        deny  : deny,
+    }, nil
+}
+
+// 判断给定的IP是否允许访问，无法解析的IP(例如unix socket的客户端)在设置了allow规则时不允许访问
+func (l *ipAccessList) isAllowed(ip string) bool {
+    if l == nil {
+        return true
+    }
+    if l.denyAll {
+        return false
+    }
+    parsed := net.ParseIP(ip)
+    if parsed != nil && ipNetsContain(l.deny, parsed) {
+        return false
+    }
+    if len(l.allow) > 0 {
+        return parsed != nil && ipNetsContain(l.allow, parsed)
+    }
+    return true
+}
+
+// 判断IP是否在给定的网段列表中
+func ipNetsContain(nets []*net.IPNet, ip net.IP) bool {
+    for _, n := range nets {
+        if n.Contains(ip) {
+            return true
+        }
+    }
+    return false
+}
+
+// 批量解析IP规则
+func parseIpNets(ips []string) ([]*net.IPNet, error) {
+    nets := make([]*net.IPNet, 0, len(ips))
+    for _, v := range ips {
+        if strings.TrimSpace(v) == "" {
+            continue
+        }
+        n, err := parseIpNet(v)
+        if err != nil {
+            return nil, err
+        }
+        nets = append(nets, n)
+    }
+    return nets, nil
+}
+
+// 解析单条IP规则，支持以下格式：
+// 1. CIDR网段，例如：10.0.0.0/8、192.168.1.0/24、fd00::/8；
+// 2. 单个IPv4/IPv6地址，例如：192.168.1.1、::1；
+// 3. IPv4前缀(兼容旧的前缀过滤规则)，例如：10 等同于10.0.0.0/8，192.168 等同于192.168.0.0/16。
+func parseIpNet(rule string) (*net.IPNet, error) {
+    rule = strings.TrimSpace(rule)
+    if strings.Contains(rule, "/") {
+        _, n, err := net.ParseCIDR(rule)
+        return n, err
+    }
+    if gipv4.Validate(rule) {
+        return &net.IPNet{IP : net.ParseIP(rule).To4(), Mask : net.CIDRMask(32, 32)}, nil
+    }
+    if gipv6.Validate(rule) {
+        return &net.IPNet{IP : net.ParseIP(rule), Mask : net.CIDRMask(128, 128)}, nil
+    }
+    // IPv4前缀，按照段数补齐为CIDR网段
+    segments := strings.Split(strings.TrimRight(rule, "."), ".")
+    if len(segments) < 4 {
+        for _, v := range segments {
+            if i, err := strconv.Atoi(v); err != nil || i < 0 || i > 255 {
+                return nil, errors.New("invalid ip rule: " + rule)
+            }
+        }
+        bits := len(segments)*8
+        for len(segments) < 4 {
+            segments = append(segments, "0")
+        }
+        if ip := strings.Join(segments, "."); gipv4.Validate(ip) {
+            return &net.IPNet{IP : net.ParseIP(ip).To4(), Mask : net.CIDRMask(bits, 32)}, nil
+        }
+    }
+    return nil, errors.New("invalid ip rule: " + rule)
+}
+
+// 设置域名级别的不允许访问的IP列表，规则格式同Server的DenyIps
+func (d *Domain) SetDenyIps(ips []string) error {
+    return d.setIpAccess(nil, ips)
+}
+
+// 设置域名级别的仅允许访问的IP列表，规则格式同Server的AllowIps
+func (d *Domain) SetAllowIps(ips []string) error {
+    return d.setIpAccess(ips, nil)
+}
+
+// 设置域名级别的IP访问控制规则，参数为nil时保留原有的设置
+func (d *Domain) setIpAccess(allowIps, denyIps []string) error {
+    if d.s.isServing() {
+        return errors.New("cannot set ip rules while server running")
+    }
+    allow, err := parseIpNets(allowIps)
+    if err != nil {
+        return err
+    }
+    deny, err := parseIpNets(denyIps)
+    if err != nil {
+        return err
+    }
+    for domain, _ := range d.m {
+        domain = strings.ToLower(domain)
+        list   := &ipAccessList{}
+        if v := d.s.domainIpAccess.Get(domain); v != nil {
+            list = v.(*ipAccessList)
+        }
+        if allowIps != nil {
+            list.allow = allow
+        }
+        if denyIps != nil {
+            list.deny = deny
+        }
+        d.s.domainIpAccess.Set(domain, list)
+    }
+    return nil
+}
+
+// 设置路由级别的IP访问控制规则，pattern参数同BindHandler，
+// 规则不匹配的请求返回403状态码(可通过BindStatusHandler自定义)，例如：
+// s.BindIpFilter("/admin/*", []string{"10.0.0.0/8", "::1"}, nil)；
+// 多个规则匹配同一请求时需要全部通过，相同的pattern重复设置时进行替换，规则在BeforeServe事件回调之前执行。
+func (s *Server) BindIpFilter(pattern string, allowIps, denyIps []string) error {
+    list, err := newIpAccessList(allowIps, denyIps)
+    if err != nil {
+        return err
+    }
+    return s.setRouteRule(gROUTE_RULE_IP_FILTER, pattern, func(r *Request) {
+        if !list.isAllowed(r.GetClientIp()) {
+            r.Response.WriteStatus(http.StatusForbidden)
+            r.Exit()
+        }
+    })
+}
+
+// 域名下的路由级别IP访问控制规则
+func (d *Domain) BindIpFilter(pattern string, allowIps, denyIps []string) error {
+    for domain, _ := range d.m {
+        if err := d.s.BindIpFilter(pattern + "@" + domain, allowIps, denyIps); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// 解析服务级别的IP访问控制规则及可信代理列表并返回解析错误；
+// 访问控制规则错误时拒绝所有请求，可信代理规则错误时不信任任何代理，不能因为规则错误而放开访问。
+func (s *Server) prepareIpAccess() error {
+    list, err := newIpAccessList(s.config.AllowIps, s.config.DenyIps)
+    if err != nil {
+        s.ipAccess = &ipAccessList{denyAll : true}
+        return err
+    }
+    if len(list.allow) > 0 || len(list.deny) > 0 {
+        s.ipAccess = list
+    }
+    nets, err := parseIpNets(s.config.TrustedProxies)
+    if err != nil {
+        return err
+    }
+    s.trustedProxies = nets
+    return nil
+}
+
+// 判断请求是否允许访问(服务及域名级别的IP访问控制)
+func (s *Server) isIpAllowed(r *Request) bool {
+    ip := r.GetClientIp()
+    if !s.ipAccess.isAllowed(ip) {
+        return false
+    }
+    // 域名级别的规则按照请求域名、匹配的域名模式的顺序，使用第一个设置的规则
+    if s.domainIpAccess.Size() > 0 {
+        for _, item := range r.getDomainMatches() {
+            if v := s.domainIpAccess.Get(strings.ToLower(item.domain)); v != nil {
+                return v.(*ipAccessList).isAllowed(ip)
+            }
+        }
+    }
+    return true
+}
+
+// 判断给定的IP是否为可信代理
+func (s *Server) isTrustedProxy(ip string) bool {
+    if len(s.trustedProxies) == 0 {
+        return false
+    }
+    parsed := net.ParseIP(ip)
+    return parsed != nil && ipNetsContain(s.trustedProxies, parsed)
+}
+
+// 从代理Header中解析客户端IP，remoteIp为直接连接的可信代理IP；
+// X-Forwarded-For从右往左查找第一个非可信代理的IP，都为可信代理时使用最左边的IP，
+// 没有X-Forwarded-For时使用X-Real-IP，都没有或者无效时返回remoteIp。
+func (r *Request) getForwardedIp(remoteIp string) string {
+    ip := remoteIp
+    if values := r.Header["X-Forwarded-For"]; len(values) > 0 {
+        hops := strings.Split(strings.Join(values, ","), ",")
+        for i := len(hops) - 1; i >= 0; i-- {
+            hop := strings.TrimSpace(hops[i])
+            if net.ParseIP(hop) == nil {
+                break
+            }
+            ip = hop
+            if !r.Server.isTrustedProxy(hop) {
+                break
+            }
+        }
+        return ip
+    }
+    if realIp := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIp) != nil {
+        return realIp
+    }
+    return ip
+}
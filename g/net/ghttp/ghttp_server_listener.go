@@ -0,0 +1,160 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 自定义监听对象(unix socket、systemd socket activation、开发者设置的net.Listener).
+
+package ghttp
+
+import (
+    "os"
+    "net"
+    "sync"
+    "errors"
+    "strings"
+    "strconv"
+    "gitee.com/johng/gf/g/os/gfile"
+    "gitee.com/johng/gf/g/os/gproc"
+)
+
+const (
+    gUNIX_SOCKET_PREFIX       = "unix:"          // unix socket监听地址前缀，例如：unix:/run/app.sock
+    gSYSTEMD_LISTEN_PID       = "LISTEN_PID"     // systemd socket activation: 接收监听文件描述符的进程ID
+    gSYSTEMD_LISTEN_FDS       = "LISTEN_FDS"     // systemd socket activation: 传递的监听文件描述符数量
+    gSYSTEMD_LISTEN_FDNAMES   = "LISTEN_FDNAMES" // systemd socket activation: 文件描述符名称(FileDescriptorName)，使用":"分隔
+    gSYSTEMD_LISTEN_FDS_START = 3                // systemd socket activation: 第一个文件描述符
+)
+
+// systemd传递的监听文件描述符
+type systemdListener struct {
+    fd   int      // 文件描述符
+    name string   // 文件描述符名称
+    file *os.File // 文件描述符对象(保持引用，防止被回收时关闭文件描述符)
+    used bool     // 是否已被Server使用
+}
+
+var (
+    // 进程中systemd传递的监听文件描述符，只解析一次，每个文件描述符只会被一个Server使用
+    systemdListeners     []*systemdListener
+    systemdListenersOnce sync.Once
+    systemdListenersMu   sync.Mutex
+)
+
+// 设置自定义的监听对象(HTTP)，服务启动时使用该监听对象提供服务，
+// 设置后未配置监听地址时不会再监听默认的80端口；平滑重启时子进程会继承该监听对象的文件描述符，
+// 因此子进程中该方法设置的监听对象将被忽略。
+func (s *Server) SetListener(listeners...net.Listener) error {
+    if s.Status() == SERVER_STATUS_RUNNING {
+        return errors.New("cannot set listener while server running")
+    }
+    for _, ln := range listeners {
+        if ln == nil {
+            return errors.New("listener cannot be nil")
+        }
+    }
+    s.listeners = append(s.listeners, listeners...)
+    return nil
+}
+
+// 获取监听对象的地址，unix socket使用unix:前缀
+func listenerAddr(ln net.Listener) string {
+    if ln.Addr().Network() == "unix" {
+        return gUNIX_SOCKET_PREFIX + ln.Addr().String()
+    }
+    return ln.Addr().String()
+}
+
+// 获取监听对象的文件描述符(只支持TCP及unix socket)，注意返回的文件描述符为复制的新文件描述符
+func listenerFile(ln net.Listener) (*os.File, error) {
+    if v, ok := ln.(interface{ File() (*os.File, error) }); ok {
+        return v.File()
+    }
+    return nil, errors.New("listener does not support file descriptor")
+}
+
+// 创建unix socket监听，如果socket文件已存在并且没有进程在监听，那么删除后重新创建
+func listenUnixSocket(path string) (net.Listener, error) {
+    if gfile.Exists(path) {
+        if conn, err := net.Dial("unix", path); err == nil {
+            conn.Close()
+            return nil, errors.New("unix socket is already in use: " + path)
+        }
+        if err := os.Remove(path); err != nil {
+            return nil, err
+        }
+    }
+    ln, err := net.Listen("unix", path)
+    if err != nil {
+        return nil, err
+    }
+    // 平滑重启时子进程继承socket文件描述符，父进程关闭时不能删除socket文件
+    ln.(*net.UnixListener).SetUnlinkOnClose(false)
+    // 允许其他用户的进程(例如nginx)连接
+    if err := os.Chmod(path, 0666); err != nil {
+        ln.Close()
+        return nil, err
+    }
+    return ln, nil
+}
+
+// 获取systemd socket activation传递的文件描述符，构造成平滑重启使用的fdMap格式，没有时返回nil。
+// 文件描述符按照名称(FileDescriptorName)分配：https或者"Server名称-https"作为HTTPS监听，
+// 名称为http、Server名称、"Server名称-http"或者未命名的作为HTTP监听；通用名称的文件描述符由第一个启动的Server使用。
+func (s *Server) getSystemdFdMap() listenerFdMap {
+    systemdListenersOnce.Do(parseSystemdListeners)
+    systemdListenersMu.Lock()
+    defer systemdListenersMu.Unlock()
+    fdMap := listenerFdMap{}
+    for _, v := range systemdListeners {
+        if v.used {
+            continue
+        }
+        proto := ""
+        switch v.name {
+            case "", "unknown", "http", s.name, s.name + "-http":
+                proto = "http"
+            case "https", s.name + "-https":
+                proto = "https"
+            default:
+                continue
+        }
+        addr := "systemd:" + strconv.Itoa(v.fd)
+        if v.file == nil {
+            v.file = os.NewFile(uintptr(v.fd), "")
+        }
+        if ln, err := net.FileListener(v.file); err == nil {
+            addr = listenerAddr(ln)
+            ln.Close()
+        }
+        if len(fdMap[proto]) > 0 {
+            fdMap[proto] += ","
+        }
+        fdMap[proto] += addr + "#" + strconv.Itoa(v.fd)
+        v.used = true
+    }
+    if len(fdMap) == 0 {
+        return nil
+    }
+    return fdMap
+}
+
+// 解析systemd socket activation环境变量，只有LISTEN_PID为当前进程时才有效
+func parseSystemdListeners() {
+    if pid, _ := strconv.Atoi(os.Getenv(gSYSTEMD_LISTEN_PID)); pid != gproc.Pid() {
+        return
+    }
+    count, _ := strconv.Atoi(os.Getenv(gSYSTEMD_LISTEN_FDS))
+    names    := strings.Split(os.Getenv(gSYSTEMD_LISTEN_FDNAMES), ":")
+    for i := 0; i < count; i++ {
+        item := &systemdListener{fd : gSYSTEMD_LISTEN_FDS_START + i}
+        if i < len(names) {
+            item.name = names[i]
+        }
+        systemdListeners = append(systemdListeners, item)
+    }
+    // 防止子进程误用
+    os.Unsetenv(gSYSTEMD_LISTEN_PID)
+    os.Unsetenv(gSYSTEMD_LISTEN_FDS)
+    os.Unsetenv(gSYSTEMD_LISTEN_FDNAMES)
+}
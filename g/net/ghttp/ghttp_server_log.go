@@ -9,10 +9,35 @@ package ghttp
 
 import (
     "fmt"
+    "time"
+    "strings"
+    "encoding/json"
     "gitee.com/johng/gf/g/util/gconv"
-    "net/http"
 )
 
+const (
+    ACCESS_LOG_FORMAT_DEFAULT  = "default"  // 默认的access log格式
+    ACCESS_LOG_FORMAT_COMBINED = "combined" // Apache combined格式
+    ACCESS_LOG_FORMAT_JSON     = "json"     // JSON格式，每行一条日志
+)
+
+// access log日志字段，JSON格式及自定义模板格式使用，耗时单位为微秒
+type accessLogEntry struct {
+    Time      string `json:"time"`
+    RequestId string `json:"request_id"`
+    Method    string `json:"method"`
+    Host      string `json:"host"`
+    Uri       string `json:"uri"`
+    Proto     string `json:"proto"`
+    Status    int    `json:"status"`
+    Bytes     int    `json:"bytes"`
+    Latency   int64  `json:"latency"`
+    Ip        string `json:"ip"`
+    Referer   string `json:"referer"`
+    Agent     string `json:"agent"`
+    Route     string `json:"route"`
+}
+
 // 处理服务错误信息，主要是panic，http请求的status由access log进行管理
 func (s *Server) handleAccessLog(r *Request) {
     if !s.IsAccessLogEnabled() {
@@ -23,20 +48,104 @@ func (s *Server) handleAccessLog(r *Request) {
         v(r)
         return
     }
+    switch format := s.GetAccessLogFormat(); format {
+        case ACCESS_LOG_FORMAT_DEFAULT, "":
+        case ACCESS_LOG_FORMAT_COMBINED:
+            s.accessLogger.Println(formatCombinedAccessLog(r))
+            return
+        case ACCESS_LOG_FORMAT_JSON:
+            if content, err := json.Marshal(newAccessLogEntry(r)); err == nil {
+                s.accessLogger.Println(string(content))
+            } else {
+                s.errorLogger.Error(err)
+            }
+            return
+        default:
+            s.accessLogger.Println(formatTemplateAccessLog(format, r))
+            return
+    }
     content := fmt.Sprintf(`"%s %s %s %s" %s %s`,
         r.Method, r.Host, r.URL.String(), r.Proto,
         gconv.String(r.Response.Status),
-        gconv.String(r.Response.BufferLength()),
+        gconv.String(r.Response.OutputSize() + r.Response.BufferLength()),
     )
     content += fmt.Sprintf(` %.3f`, float64(r.LeaveTime - r.EnterTime)/1000)
     content += fmt.Sprintf(`, %s, "%s", "%s"`, r.GetClientIp(), r.Referer(), r.UserAgent())
     s.accessLogger.Println(content)
 }
 
+// 获取access log的日志字段
+func newAccessLogEntry(r *Request) *accessLogEntry {
+    route := ""
+    if r.Router != nil {
+        route = r.Router.Uri
+        if r.Router.Domain != gDEFAULT_DOMAIN {
+            route += "@" + r.Router.Domain
+        }
+    }
+    return &accessLogEntry {
+        Time      : time.Unix(0, r.EnterTime*1000).Format(time.RFC3339),
+        RequestId : r.Id(),
+        Method    : r.Method,
+        Host      : r.Host,
+        Uri       : r.URL.String(),
+        Proto     : r.Proto,
+        Status    : r.Response.Status,
+        Bytes     : r.Response.OutputSize() + r.Response.BufferLength(),
+        Latency   : r.LeaveTime - r.EnterTime,
+        Ip        : r.GetClientIp(),
+        Referer   : r.Referer(),
+        Agent     : r.UserAgent(),
+        Route     : route,
+    }
+}
+
+// Apache combined格式: %h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-agent}i"
+func formatCombinedAccessLog(r *Request) string {
+    entry := newAccessLogEntry(r)
+    bytes := "-"
+    if entry.Bytes > 0 {
+        bytes = gconv.String(entry.Bytes)
+    }
+    referer, agent := "-", "-"
+    if entry.Referer != "" {
+        referer = entry.Referer
+    }
+    if entry.Agent != "" {
+        agent = entry.Agent
+    }
+    user := "-"
+    if r.URL.User != nil && r.URL.User.Username() != "" {
+        user = r.URL.User.Username()
+    }
+    return fmt.Sprintf(`%s - %s [%s] "%s %s %s" %d %s "%s" "%s"`,
+        entry.Ip, user, time.Unix(0, r.EnterTime*1000).Format("02/Jan/2006:15:04:05 -0700"),
+        entry.Method, r.URL.RequestURI(), entry.Proto, entry.Status, bytes, referer, agent,
+    )
+}
+
+// 自定义模板格式，模板中的{字段名}替换为对应的字段值
+func formatTemplateAccessLog(format string, r *Request) string {
+    entry := newAccessLogEntry(r)
+    return strings.NewReplacer(
+        "{time}",       entry.Time,
+        "{request_id}", entry.RequestId,
+        "{method}",     entry.Method,
+        "{host}",       entry.Host,
+        "{uri}",        entry.Uri,
+        "{proto}",      entry.Proto,
+        "{status}",     gconv.String(entry.Status),
+        "{bytes}",      gconv.String(entry.Bytes),
+        "{latency}",    gconv.String(entry.Latency),
+        "{ip}",         entry.Ip,
+        "{referer}",    entry.Referer,
+        "{agent}",      entry.Agent,
+        "{route}",      entry.Route,
+    ).Replace(format)
+}
+
 // 处理服务错误信息，主要是panic，http请求的status由access log进行管理
 func (s *Server) handleErrorLog(error interface{}, r *Request) {
-    r.Response.WriteStatus(http.StatusInternalServerError)
-
     if !s.IsErrorLogEnabled() {
         return
     }
@@ -48,6 +157,6 @@ func (s *Server) handleErrorLog(error interface{}, r *Request) {
 
     content := fmt.Sprintf(`%v, "%s %s %s %s"`, error, r.Method, r.Host, r.URL.String(), r.Proto)
     content += fmt.Sprintf(` %.3f`, float64(r.LeaveTime - r.EnterTime)/1000)
-    content += fmt.Sprintf(`, %s, "%s", "%s"`,  r.GetClientIp(), r.Referer(), r.UserAgent())
+    content += fmt.Sprintf(`, %s, "%s", "%s", %s`,  r.GetClientIp(), r.Referer(), r.UserAgent(), r.Id())
     s.errorLogger.Error(content)
 }
@@ -0,0 +1,288 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// Prometheus格式的服务监控指标.
+
+package ghttp
+
+import (
+    "fmt"
+    "sort"
+    "sync"
+    "bytes"
+    "strings"
+    "runtime"
+    "strconv"
+    "net/http"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+const (
+    gMETRICS_ROUTE_STATIC    = "<static>"    // 静态文件请求的路由标签
+    gMETRICS_ROUTE_UNMATCHED = "<unmatched>" // 未匹配到任何路由的请求的路由标签
+    gMETRICS_METHOD_OTHER    = "OTHER"       // 非标准HTTP Method的请求方法标签
+)
+
+var (
+    // 请求耗时直方图分布(秒)
+    metricsLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+    // 返回内容大小直方图分布(byte)
+    metricsSizeBuckets    = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+)
+
+// 服务监控指标管理对象
+type metrics struct {
+    mu       sync.RWMutex
+    enabled  *gtype.Bool              // 是否开启指标统计
+    inFlight *gtype.Int               // 正在处理的请求数
+    routes   map[string]*metricsRoute // 按照路由及请求方法统计的指标，键名为：路由#方法
+}
+
+// 单个路由的统计指标
+type metricsRoute struct {
+    mu       sync.Mutex
+    route    string            // 路由规则(注册时的pattern)，而不是请求的原始路径，防止指标数量膨胀
+    method   string            // 请求方法
+    inFlight *gtype.Int        // 正在处理的请求数
+    status   map[int]uint64    // 按照状态码统计的请求数
+    latency  *metricsHistogram // 请求耗时(秒)
+    size     *metricsHistogram // 返回内容大小(byte)
+}
+
+// 直方图统计
+type metricsHistogram struct {
+    buckets []float64 // 分布区间上限
+    counts  []uint64  // 各区间的数量(不累加)
+    sum     float64   // 总和
+    count   uint64    // 总数量
+}
+
+func newMetrics() *metrics {
+    return &metrics {
+        enabled  : gtype.NewBool(),
+        inFlight : gtype.NewInt(),
+        routes   : make(map[string]*metricsRoute),
+    }
+}
+
+// 开启Prometheus格式的监控指标页面，默认地址为/metrics，
+// 指标包含按照路由统计的请求数、耗时、状态码、返回内容大小、正在处理的请求数，以及服务进程的状态信息。
+func (s *Server) EnableMetrics(pattern...string) error {
+    p := "/metrics"
+    if len(pattern) > 0 && pattern[0] != "" {
+        p = pattern[0]
+    }
+    if err := s.BindHandler(p, s.metricsHandler); err != nil {
+        return err
+    }
+    s.metrics.enabled.Set(true)
+    return nil
+}
+
+// 请求开始处理(路由检索完成之后调用)，返回的统计对象需要在请求结束时传递给metricsEnd
+func (s *Server) metricsBegin(r *Request) *metricsRoute {
+    if !s.metrics.enabled.Val() {
+        return nil
+    }
+    route := gMETRICS_ROUTE_UNMATCHED
+    if r.Router != nil {
+        route = r.Router.Uri
+        if r.Router.Domain != gDEFAULT_DOMAIN {
+            route += "@" + r.Router.Domain
+        }
+    } else if r.IsFileRequest() {
+        route = gMETRICS_ROUTE_STATIC
+    }
+    // 请求方法由客户端决定，非标准的方法统一使用OTHER标签，防止客户端通过任意方法名称无限增加统计项
+    method := r.Method
+    if _, ok := s.methodsMap[method]; !ok {
+        method = gMETRICS_METHOD_OTHER
+    }
+    item := s.metrics.getRoute(route, method)
+    item.inFlight.Add(1)
+    s.metrics.inFlight.Add(1)
+    return item
+}
+
+// 请求处理结束，记录状态码、耗时及返回内容大小
+func (s *Server) metricsEnd(item *metricsRoute, r *Request) {
+    if item == nil {
+        return
+    }
+    item.inFlight.Add(-1)
+    s.metrics.inFlight.Add(-1)
+    status := r.Response.Status
+    if status == 0 {
+        status = http.StatusOK
+    }
+    item.mu.Lock()
+    item.status[status]++
+    item.latency.observe(float64(r.LeaveTime - r.EnterTime)/1000000)
+    item.size.observe(float64(r.Response.OutputSize() + r.Response.BufferLength()))
+    item.mu.Unlock()
+}
+
+// 获取/创建路由统计对象
+func (m *metrics) getRoute(route, method string) *metricsRoute {
+    key := route + "#" + method
+    m.mu.RLock()
+    item, ok := m.routes[key]
+    m.mu.RUnlock()
+    if ok {
+        return item
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if item, ok = m.routes[key]; !ok {
+        item = &metricsRoute {
+            route    : route,
+            method   : method,
+            inFlight : gtype.NewInt(),
+            status   : make(map[int]uint64),
+            latency  : newMetricsHistogram(metricsLatencyBuckets),
+            size     : newMetricsHistogram(metricsSizeBuckets),
+        }
+        m.routes[key] = item
+    }
+    return item
+}
+
+func newMetricsHistogram(buckets []float64) *metricsHistogram {
+    return &metricsHistogram {
+        buckets : buckets,
+        counts  : make([]uint64, len(buckets)),
+    }
+}
+
+// 记录直方图数据
+func (h *metricsHistogram) observe(value float64) {
+    for i, v := range h.buckets {
+        if value <= v {
+            h.counts[i]++
+            break
+        }
+    }
+    h.sum += value
+    h.count++
+}
+
+// 输出直方图数据，labels为已格式化的标签内容
+func (h *metricsHistogram) write(buffer *bytes.Buffer, name, labels string) {
+    cumulative := uint64(0)
+    for i, v := range h.buckets {
+        cumulative += h.counts[i]
+        fmt.Fprintf(buffer, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, formatMetricsFloat(v), cumulative)
+    }
+    fmt.Fprintf(buffer, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+    fmt.Fprintf(buffer, "%s_sum{%s} %s\n",   name, labels, formatMetricsFloat(h.sum))
+    fmt.Fprintf(buffer, "%s_count{%s} %d\n", name, labels, h.count)
+}
+
+// 指标页面输出(Prometheus text format 0.0.4)
+func (s *Server) metricsHandler(r *Request) {
+    buffer := bytes.NewBuffer(nil)
+    routes := make([]*metricsRoute, 0)
+    s.metrics.mu.RLock()
+    for _, v := range s.metrics.routes {
+        routes = append(routes, v)
+    }
+    s.metrics.mu.RUnlock()
+    sort.Slice(routes, func(i, j int) bool {
+        if routes[i].route != routes[j].route {
+            return routes[i].route < routes[j].route
+        }
+        return routes[i].method < routes[j].method
+    })
+    labels := func(item *metricsRoute) string {
+        return fmt.Sprintf(`route="%s",method="%s"`, escapeMetricsLabel(item.route), escapeMetricsLabel(item.method))
+    }
+
+    buffer.WriteString("# HELP ghttp_requests_total Total number of HTTP requests by route, method and status code.\n")
+    buffer.WriteString("# TYPE ghttp_requests_total counter\n")
+    for _, item := range routes {
+        item.mu.Lock()
+        codes := make([]int, 0, len(item.status))
+        for code := range item.status {
+            codes = append(codes, code)
+        }
+        sort.Ints(codes)
+        for _, code := range codes {
+            fmt.Fprintf(buffer, "ghttp_requests_total{%s,status=\"%d\"} %d\n", labels(item), code, item.status[code])
+        }
+        item.mu.Unlock()
+    }
+
+    buffer.WriteString("# HELP ghttp_request_duration_seconds HTTP request latency in seconds by route and method.\n")
+    buffer.WriteString("# TYPE ghttp_request_duration_seconds histogram\n")
+    for _, item := range routes {
+        item.mu.Lock()
+        item.latency.write(buffer, "ghttp_request_duration_seconds", labels(item))
+        item.mu.Unlock()
+    }
+
+    buffer.WriteString("# HELP ghttp_response_size_bytes HTTP response size in bytes by route and method.\n")
+    buffer.WriteString("# TYPE ghttp_response_size_bytes histogram\n")
+    for _, item := range routes {
+        item.mu.Lock()
+        item.size.write(buffer, "ghttp_response_size_bytes", labels(item))
+        item.mu.Unlock()
+    }
+
+    buffer.WriteString("# HELP ghttp_requests_in_flight Number of HTTP requests currently being served by route and method.\n")
+    buffer.WriteString("# TYPE ghttp_requests_in_flight gauge\n")
+    for _, item := range routes {
+        fmt.Fprintf(buffer, "ghttp_requests_in_flight{%s} %d\n", labels(item), item.inFlight.Val())
+    }
+
+    // 服务进程状态
+    memStats := runtime.MemStats{}
+    runtime.ReadMemStats(&memStats)
+    gauges := []struct{
+        name  string
+        help  string
+        value interface{}
+    }{
+        {"ghttp_server_requests_in_flight",    "Number of HTTP requests currently being served.",                           s.metrics.inFlight.Val()},
+        {"ghttp_server_close_queue_size",      "Number of finished requests waiting in the close queue.",                   s.closeQueue.Size()},
+        {"ghttp_server_sessions",              "Number of sessions in the session cache.",                                  s.sessions.Size()},
+        {"ghttp_server_cookies",               "Number of cookie objects of requests being served.",                        s.cookies.Size()},
+        {"ghttp_server_static_etag_cache",     "Number of entries in the static file ETag cache.",                          s.staticEtags.Size()},
+        {"ghttp_server_websocket_connections", "Number of WebSocket connections managed by the server hub.",                s.wsHub.Size()},
+        {"go_goroutines",                      "Number of goroutines that currently exist.",                                runtime.NumGoroutine()},
+        {"go_memstats_alloc_bytes",            "Number of bytes allocated and still in use.",                               memStats.Alloc},
+        {"go_memstats_sys_bytes",              "Number of bytes obtained from system.",                                     memStats.Sys},
+    }
+    buffer.WriteString("# HELP ghttp_server_requests_served_total Number of HTTP requests served since the server started.\n")
+    buffer.WriteString("# TYPE ghttp_server_requests_served_total counter\n")
+    fmt.Fprintf(buffer, "ghttp_server_requests_served_total %d\n", s.servedCount.Val())
+    for _, v := range gauges {
+        fmt.Fprintf(buffer, "# HELP %s %s\n# TYPE %s gauge\n%s %v\n", v.name, v.help, v.name, v.name, v.value)
+    }
+    // HTTPS证书过期时间
+    if expiries := s.GetCertificateExpiries(); len(expiries) > 0 {
+        domains := make([]string, 0, len(expiries))
+        for domain := range expiries {
+            domains = append(domains, domain)
+        }
+        sort.Strings(domains)
+        buffer.WriteString("# HELP ghttp_server_tls_certificate_expiry_timestamp_seconds Expiry time of the HTTPS certificate by domain in unix seconds.\n")
+        buffer.WriteString("# TYPE ghttp_server_tls_certificate_expiry_timestamp_seconds gauge\n")
+        for _, domain := range domains {
+            fmt.Fprintf(buffer, "ghttp_server_tls_certificate_expiry_timestamp_seconds{domain=\"%s\"} %d\n", escapeMetricsLabel(domain), expiries[domain].Unix())
+        }
+    }
+    r.Response.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+    r.Response.Write(buffer.Bytes())
+}
+
+// 标签值转义
+func escapeMetricsLabel(value string) string {
+    return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// 格式化浮点数
+func formatMetricsFloat(value float64) string {
+    return strconv.FormatFloat(value, 'g', -1, 64)
+}
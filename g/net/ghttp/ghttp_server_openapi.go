@@ -0,0 +1,650 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// OpenAPI 3 接口文档生成.
+
+package ghttp
+
+import (
+    "time"
+    "sort"
+    "strconv"
+    "reflect"
+    "strings"
+    "runtime"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/util/gregex"
+    "gitee.com/johng/gf/g/container/gmap"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+// 接口文档基本信息
+type ApiInfo struct {
+    Title       string // 文档标题
+    Version     string // 接口版本
+    Description string // 文档描述
+}
+
+// 单个接口的文档信息，Request/Response为请求参数及返回数据的结构体对象(或者指针)
+type ApiDoc struct {
+    Summary     string      // 接口简介
+    Description string      // 接口详细描述
+    Tags        []string    // 接口分组标签，为空时使用控制器/对象名称
+    Request     interface{} // 请求参数结构体
+    Response    interface{} // 返回数据结构体
+}
+
+// 通过BindObject/BindController注册的对象(控制器)实现该接口时，生成文档时按照方法名称获取接口文档信息，
+// 不依赖请求执行，例如：func (u *User) ApiDocs() map[string]ghttp.ApiDoc { return map[string]ghttp.ApiDoc{"Show" : {...}} }
+type ApiDocProvider interface {
+    ApiDocs() map[string]ApiDoc // 键名为方法名称
+}
+
+// 接口文档记录项(按照路由记录)
+type apiDocItem struct {
+    doc      *ApiDoc          // SetApiDoc设置的文档信息
+    request  *gtype.Interface // 请求执行时通过参数绑定记录的请求结构体类型(reflect.Type)
+    response *gtype.Interface // 请求执行时通过WriteJson/WriteXml记录的返回结构体类型(reflect.Type)
+}
+
+// 接口文档管理对象
+type openApi struct {
+    enabled *gtype.Bool              // 是否开启文档生成(开启后才会在请求执行时记录结构体类型)
+    info    *gtype.Interface         // 文档基本信息(ApiInfo)
+    assets  *gtype.String            // 文档UI页面的swagger-ui-dist资源地址
+    items   *gmap.StringInterfaceMap // 接口文档记录项，键名为路由键名(method:uri@domain)
+    ignores *gmap.StringInterfaceMap // 文档及UI页面自身的路由，不写入文档
+}
+
+// OpenAPI Schema
+type apiSchema map[string]interface{}
+
+// 路径参数
+type apiPathParam struct {
+    name   string    // 参数名称
+    schema apiSchema // 参数Schema(根据路由约束规则生成)
+}
+
+const (
+    // 默认的swagger-ui-dist资源地址(固定版本)，内网/离线环境可通过SetApiUiAssets设置为本地静态资源地址
+    gOPENAPI_UI_ASSETS = "https://unpkg.com/swagger-ui-dist@3.52.5"
+)
+
+// 文档UI页面模板，{assets}为swagger-ui-dist资源地址，{url}为文档JSON地址
+const gOPENAPI_UI_TEMPLATE = `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>API Document</title>
+    <link rel="stylesheet" href="{assets}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{assets}/swagger-ui-bundle.js"></script>
+<script>
+    window.onload = function() {
+        SwaggerUIBundle({url: "{url}", dom_id: "#swagger-ui"});
+    };
+</script>
+</body>
+</html>`
+
+var (
+    // 特殊类型的Schema
+    timeType       = reflect.TypeOf(time.Time{})
+    uploadFileType = reflect.TypeOf(UploadFile{})
+)
+
+func newOpenApi() *openApi {
+    api := &openApi {
+        enabled : gtype.NewBool(),
+        info    : gtype.NewInterface(),
+        assets  : gtype.NewString(gOPENAPI_UI_ASSETS),
+        items   : gmap.NewStringInterfaceMap(),
+        ignores : gmap.NewStringInterfaceMap(),
+    }
+    api.info.Set(ApiInfo{Title : "API Document", Version : "1.0.0"})
+    return api
+}
+
+// 开启OpenAPI 3接口文档，pattern为文档JSON的访问地址，默认为/swagger.json；
+// 参数uiPattern为可选的文档UI页面访问地址，例如：s.EnableOpenApi("/swagger.json", "/swagger")。
+// 接口的请求参数及返回数据结构按照以下优先级获取：
+// 1、SetApiDoc设置的文档信息；
+// 2、注册对象(控制器)实现ApiDocProvider接口返回的文档信息；
+// 3、请求执行时参数绑定(GetRequestToStruct等)及WriteJson/WriteXml使用的结构体。
+// 其中第3种方式只是尽力而为的补充：只有在服务启动后被请求过的接口才会记录，因此文档内容与请求情况有关，
+// 需要稳定一致的文档时请使用前两种方式。
+func (s *Server) EnableOpenApi(pattern string, uiPattern...string) error {
+    if pattern == "" {
+        pattern = "/swagger.json"
+    }
+    if err := s.BindHandler(pattern, s.openApiJsonHandler); err != nil {
+        return err
+    }
+    s.openApi.ignores.Set(s.openApiRouterKey(pattern), nil)
+    if len(uiPattern) > 0 && uiPattern[0] != "" {
+        _, _, url, _ := s.parsePattern(pattern)
+        err          := s.BindHandler(uiPattern[0], func(r *Request) {
+            content := strings.NewReplacer("{assets}", s.openApi.assets.Val(), "{url}", url).Replace(gOPENAPI_UI_TEMPLATE)
+            r.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+            r.Response.Write(content)
+        })
+        if err != nil {
+            return err
+        }
+        s.openApi.ignores.Set(s.openApiRouterKey(uiPattern[0]), nil)
+    }
+    s.openApi.enabled.Set(true)
+    return nil
+}
+
+// 设置接口文档基本信息
+func (s *Server) SetApiInfo(info ApiInfo) {
+    s.openApi.info.Set(info)
+}
+
+// 设置文档UI页面加载的swagger-ui-dist资源地址(目录地址，包含swagger-ui.css及swagger-ui-bundle.js)，
+// 默认从unpkg.com加载固定版本，内网/离线环境可以将资源放到静态目录后设置，例如：s.SetApiUiAssets("/assets/swagger-ui")
+func (s *Server) SetApiUiAssets(url string) {
+    s.openApi.assets.Set(strings.TrimRight(url, "/"))
+}
+
+// 设置指定路由的接口文档信息，pattern格式同BindHandler，例如：s.SetApiDoc("POST:/user", ghttp.ApiDoc{...})
+func (s *Server) SetApiDoc(pattern string, doc ApiDoc) {
+    s.getApiDocItem(s.openApiRouterKey(pattern)).doc = &doc
+}
+
+// 获取/创建接口文档记录项
+func (s *Server) getApiDocItem(key string) *apiDocItem {
+    s.openApi.items.LockFunc(func(m map[string]interface{}) {
+        if _, ok := m[key]; !ok {
+            m[key] = &apiDocItem {
+                request  : gtype.NewInterface(),
+                response : gtype.NewInterface(),
+            }
+        }
+    })
+    return s.openApi.items.Get(key).(*apiDocItem)
+}
+
+// 根据pattern生成文档路由键名
+func (s *Server) openApiRouterKey(pattern string) string {
+    domain, method, uri, _ := s.parsePattern(pattern)
+    return routerApiKey(&Router{Uri : uri, Method : method, Domain : domain})
+}
+
+// 根据路由对象生成文档路由键名
+func routerApiKey(router *Router) string {
+    return strings.ToUpper(router.Method) + ":" + router.Uri + "@" + router.Domain
+}
+
+// 请求执行时记录参数绑定的结构体类型(仅在开启文档时记录)
+func (r *Request) recordApiRequest(object interface{}) {
+    if r.Router == nil || !r.Server.openApi.enabled.Val() {
+        return
+    }
+    if t := apiStructType(reflect.TypeOf(object)); t != nil {
+        r.Server.getApiDocItem(routerApiKey(r.Router)).request.Set(t)
+    }
+}
+
+// 请求执行时记录返回数据的结构体类型(仅在开启文档时记录)
+func (r *Response) recordApiResponse(content interface{}) {
+    if r.request == nil || r.request.Router == nil || !r.Server.openApi.enabled.Val() {
+        return
+    }
+    t := reflect.TypeOf(content)
+    for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+        t = t.Elem()
+    }
+    if t != nil && t.Kind() == reflect.Struct {
+        r.Server.getApiDocItem(routerApiKey(r.request.Router)).response.Set(reflect.TypeOf(content))
+    }
+}
+
+// 获得指针指向的结构体类型，非结构体返回nil
+func apiStructType(t reflect.Type) reflect.Type {
+    for t != nil && t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    if t == nil || t.Kind() != reflect.Struct {
+        return nil
+    }
+    return t
+}
+
+// 文档JSON输出
+func (s *Server) openApiJsonHandler(r *Request) {
+    if err := r.Response.WriteJson(s.buildOpenApi()); err != nil {
+        glog.Error(err)
+    }
+}
+
+// 获取所有注册的服务路由项
+func (s *Server) getServeHandlerItems() []*handlerItem {
+    items := make([]*handlerItem, 0)
+    for _, tree := range s.serveTree {
+        for e := tree.items.Front(); e != nil; e = e.Next() {
+            items = append(items, e.Value.(*handlerItem))
+        }
+    }
+    sort.Slice(items, func(i, j int) bool {
+        if items[i].router.Uri != items[j].router.Uri {
+            return items[i].router.Uri < items[j].router.Uri
+        }
+        return items[i].router.Method < items[j].router.Method
+    })
+    return items
+}
+
+// 生成OpenAPI 3文档
+func (s *Server) buildOpenApi() map[string]interface{} {
+    info    := s.openApi.info.Val().(ApiInfo)
+    paths   := make(map[string]map[string]interface{})
+    schemas := make(map[string]interface{})
+    for _, item := range s.getServeHandlerItems() {
+        key := routerApiKey(item.router)
+        if s.openApi.ignores.Contains(key) {
+            continue
+        }
+        doc      := registeredApiDoc(item)
+        reqType  := reflect.Type(nil)
+        respType := reflect.Type(nil)
+        if v := s.openApi.items.Get(key); v != nil {
+            docItem := v.(*apiDocItem)
+            if docItem.doc != nil {
+                doc = *docItem.doc
+            }
+            if t, ok := docItem.request.Val().(reflect.Type); ok {
+                reqType = t
+            }
+            if t, ok := docItem.response.Val().(reflect.Type); ok {
+                respType = t
+            }
+        }
+        if doc.Request != nil {
+            reqType = apiStructType(reflect.TypeOf(doc.Request))
+        }
+        if doc.Response != nil {
+            respType = reflect.TypeOf(doc.Response)
+        }
+        path, pathParams := apiPathFromUri(item.router.Uri)
+        if _, ok := paths[path]; !ok {
+            paths[path] = make(map[string]interface{})
+        }
+        methods := []string{strings.ToLower(item.router.Method)}
+        if strings.EqualFold(item.router.Method, gDEFAULT_METHOD) {
+            methods = []string{"get", "post"}
+        }
+        name := handlerItemName(item)
+        for _, method := range methods {
+            operation := map[string]interface{} {
+                "operationId" : apiOperationId(method, path),
+                "responses"   : map[string]interface{} {
+                    "200" : apiResponse(respType, schemas),
+                },
+            }
+            if doc.Summary != "" {
+                operation["summary"] = doc.Summary
+            }
+            if doc.Description != "" {
+                operation["description"] = doc.Description
+            }
+            if len(doc.Tags) > 0 {
+                operation["tags"] = doc.Tags
+            } else if array := strings.Split(name, "."); len(array) > 1 {
+                operation["tags"] = []string{array[0]}
+            }
+            if item.router.Domain != gDEFAULT_DOMAIN {
+                operation["x-domain"] = item.router.Domain
+            }
+            parameters := make([]interface{}, 0)
+            for _, p := range pathParams {
+                parameters = append(parameters, map[string]interface{} {
+                    "name"     : p.name,
+                    "in"       : "path",
+                    "required" : true,
+                    "schema"   : p.schema,
+                })
+            }
+            if reqType != nil {
+                if method == "get" || method == "delete" || method == "head" {
+                    parameters = append(parameters, apiQueryParameters(reqType, pathParams, schemas)...)
+                } else {
+                    operation["requestBody"] = apiRequestBody(reqType, schemas)
+                }
+            }
+            if len(parameters) > 0 {
+                operation["parameters"] = parameters
+            }
+            paths[path][method] = operation
+        }
+    }
+    document := map[string]interface{} {
+        "openapi" : "3.0.0",
+        "info"    : map[string]interface{} {
+            "title"       : info.Title,
+            "version"     : info.Version,
+            "description" : info.Description,
+        },
+        "paths"   : paths,
+    }
+    if len(schemas) > 0 {
+        document["components"] = map[string]interface{} {
+            "schemas" : schemas,
+        }
+    }
+    return document
+}
+
+// 获取注册对象(控制器)通过ApiDocProvider接口提供的文档信息，不存在时返回空的文档信息
+func registeredApiDoc(item *handlerItem) ApiDoc {
+    if item.ctype == nil {
+        return ApiDoc{}
+    }
+    if provider, ok := reflect.New(item.ctype).Interface().(ApiDocProvider); ok {
+        return provider.ApiDocs()[item.fname]
+    }
+    return ApiDoc{}
+}
+
+// 将路由URI转换为OpenAPI路径格式，返回路径及路径参数，路由段的解析规则同路由注册(parseRouterSegment)，例如：
+// /user/:id/*path -> /user/{id}/{path}，/post/:id<int> -> /post/{id}，/page/{page:\d+}.html -> /page/{page}.html
+func apiPathFromUri(uri string) (path string, params []apiPathParam) {
+    array := splitRouterPath(uri)
+    for k, v := range array {
+        segment := parseRouterSegment(v)
+        switch segment.kind {
+            case gROUTER_SEGMENT_PARAM, gROUTER_SEGMENT_ANY:
+                array[k] = apiAddPathParam(&params, segment.names[0], "")
+
+            case gROUTER_SEGMENT_REGEX:
+                if v[0] == ':' {
+                    // :name<rule>
+                    name       := v[1:]
+                    pos        := strings.Index(name, "<")
+                    constraint := name[pos + 1 : len(name) - 1]
+                    array[k]    = apiAddPathParam(&params, name[0 : pos], constraint)
+                    continue
+                }
+                // 包含{field}、{field:rule}或者*通配符的路由段
+                part := ""
+                for i := 0; i < len(v); {
+                    if v[i] == '{' {
+                        if name, constraint, end := parseRouterField(v, i); end != -1 {
+                            part += apiAddPathParam(&params, name, constraint)
+                            i     = end + 1
+                            continue
+                        }
+                    }
+                    if v[i] == '*' {
+                        part += apiAddPathParam(&params, "", "")
+                    } else {
+                        part += v[i : i + 1]
+                    }
+                    i++
+                }
+                array[k] = part
+        }
+    }
+    return "/" + strings.Join(array, "/"), params
+}
+
+// 添加路径参数，返回参数在路径中的表示形式；匿名参数自动命名为paramN，约束规则转换为参数的Schema
+func apiAddPathParam(params *[]apiPathParam, name, constraint string) string {
+    if name == "" {
+        name = "param" + strconv.Itoa(len(*params))
+    }
+    schema := apiSchema{"type" : "string"}
+    switch constraint {
+        case "":
+        case "int", "uint":
+            schema = apiSchema{"type" : "integer"}
+        case "float":
+            schema = apiSchema{"type" : "number"}
+        default:
+            schema["pattern"] = "^" + routerConstraintRule(constraint) + "$"
+    }
+    *params = append(*params, apiPathParam{name, schema})
+    return "{" + name + "}"
+}
+
+// 生成接口的operationId，例如：get /user/{id} -> get_user_id
+func apiOperationId(method, path string) string {
+    id, _ := gregex.ReplaceString(`[^\w]+`, "_", method + path)
+    return strings.Trim(id, "_")
+}
+
+// 获取路由项的处理方法名称，例如：User.Show，普通函数返回空字符串
+func handlerItemName(item *handlerItem) string {
+    if item.ctype != nil {
+        return item.ctype.Name() + "." + item.fname
+    }
+    if item.faddr == nil {
+        return ""
+    }
+    // 通过BindHandler绑定的对象方法：package.(*User).Show-fm
+    name := runtime.FuncForPC(reflect.ValueOf(item.faddr).Pointer()).Name()
+    if !strings.HasSuffix(name, "-fm") {
+        return ""
+    }
+    name = strings.TrimSuffix(name[strings.LastIndex(name, "/") + 1:], "-fm")
+    if array := strings.Split(name, "."); len(array) == 3 {
+        return strings.Trim(array[1], "(*)") + "." + array[2]
+    }
+    return ""
+}
+
+// 生成返回数据描述
+func apiResponse(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+    response := map[string]interface{} {
+        "description" : "OK",
+    }
+    if t != nil {
+        response["content"] = map[string]interface{} {
+            "application/json" : map[string]interface{} {
+                "schema" : apiTypeSchema(t, schemas),
+            },
+        }
+    }
+    return response
+}
+
+// 生成请求体描述，包含上传文件属性时使用multipart/form-data
+func apiRequestBody(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+    schema  := apiTypeSchema(t, schemas)
+    content := make(map[string]interface{})
+    if apiHasUploadField(t) {
+        content["multipart/form-data"] = map[string]interface{}{"schema" : schema}
+    } else {
+        content["application/json"]                  = map[string]interface{}{"schema" : schema}
+        content["application/x-www-form-urlencoded"] = map[string]interface{}{"schema" : schema}
+    }
+    return map[string]interface{} {
+        "content" : content,
+    }
+}
+
+// 将结构体属性转换为query参数，已作为路径参数的属性将被忽略
+func apiQueryParameters(t reflect.Type, pathParams []apiPathParam, schemas map[string]interface{}) []interface{} {
+    parameters := make([]interface{}, 0)
+    apiStructFields(t, func(field reflect.StructField, name string, required bool) {
+        for _, p := range pathParams {
+            if strings.EqualFold(p.name, name) {
+                return
+            }
+        }
+        parameter := map[string]interface{} {
+            "name"     : name,
+            "in"       : "query",
+            "required" : required,
+            "schema"   : apiTypeSchema(field.Type, schemas),
+        }
+        if doc := field.Tag.Get("doc"); doc != "" {
+            parameter["description"] = doc
+        }
+        parameters = append(parameters, parameter)
+    })
+    return parameters
+}
+
+// 判断结构体是否包含上传文件属性
+func apiHasUploadField(t reflect.Type) bool {
+    has := false
+    apiStructFields(t, func(field reflect.StructField, name string, required bool) {
+        ft := field.Type
+        for ft.Kind() == reflect.Ptr || ft.Kind() == reflect.Slice {
+            ft = ft.Elem()
+        }
+        if ft == uploadFileType {
+            has = true
+        }
+    })
+    return has
+}
+
+// 遍历结构体的公开属性(匿名结构体属性展开)，参数名称按照params标签、json标签、属性名称的优先级获取，
+// 当gvalid标签中包含required规则时表示该参数必需
+func apiStructFields(t reflect.Type, f func(field reflect.StructField, name string, required bool)) {
+    t = apiStructType(t)
+    if t == nil {
+        return
+    }
+    for i := 0; i < t.NumField(); i++ {
+        field := t.Field(i)
+        if field.Anonymous && apiStructType(field.Type) != nil {
+            apiStructFields(field.Type, f)
+            continue
+        }
+        if field.PkgPath != "" {
+            continue
+        }
+        name := field.Name
+        if tag := field.Tag.Get("params"); tag != "" {
+            name = strings.TrimSpace(strings.Split(tag, ",")[0])
+        } else if tag := field.Tag.Get("json"); tag != "" {
+            if tag = strings.Split(tag, ",")[0]; tag == "-" {
+                continue
+            } else if tag != "" {
+                name = tag
+            }
+        }
+        required := false
+        if tag := field.Tag.Get("gvalid"); tag != "" {
+            // 校验规则格式：name@rule1|rule2#msg1|msg2
+            rules := tag
+            if pos := strings.Index(rules, "@"); pos != -1 {
+                rules = rules[pos + 1:]
+            }
+            if pos := strings.Index(rules, "#"); pos != -1 {
+                rules = rules[0 : pos]
+            }
+            for _, rule := range strings.Split(rules, "|") {
+                if strings.TrimSpace(rule) == "required" {
+                    required = true
+                }
+            }
+        }
+        f(field, name, required)
+    }
+}
+
+// 根据反射类型生成Schema，命名结构体写入components/schemas并通过$ref引用
+func apiTypeSchema(t reflect.Type, schemas map[string]interface{}) apiSchema {
+    for t.Kind() == reflect.Ptr {
+        t = t.Elem()
+    }
+    switch t {
+        case timeType:
+            return apiSchema{"type" : "string", "format" : "date-time"}
+        case uploadFileType:
+            return apiSchema{"type" : "string", "format" : "binary"}
+    }
+    switch t.Kind() {
+        case reflect.Bool:
+            return apiSchema{"type" : "boolean"}
+        case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+            return apiSchema{"type" : "integer"}
+        case reflect.Int32, reflect.Uint32:
+            return apiSchema{"type" : "integer", "format" : "int32"}
+        case reflect.Int64, reflect.Uint64:
+            return apiSchema{"type" : "integer", "format" : "int64"}
+        case reflect.Float32:
+            return apiSchema{"type" : "number", "format" : "float"}
+        case reflect.Float64:
+            return apiSchema{"type" : "number", "format" : "double"}
+        case reflect.String:
+            return apiSchema{"type" : "string"}
+        case reflect.Slice, reflect.Array:
+            if t.Elem().Kind() == reflect.Uint8 {
+                return apiSchema{"type" : "string", "format" : "byte"}
+            }
+            return apiSchema{"type" : "array", "items" : apiTypeSchema(t.Elem(), schemas)}
+        case reflect.Map:
+            return apiSchema{"type" : "object", "additionalProperties" : apiTypeSchema(t.Elem(), schemas)}
+        case reflect.Struct:
+            if t.Name() != "" {
+                name := apiSchemaName(t)
+                ref  := apiSchema{"$ref" : "#/components/schemas/" + name}
+                if _, ok := schemas[name]; ok {
+                    return ref
+                }
+                // 先占位，防止结构体自引用时无限递归
+                schemas[name] = apiSchema{}
+                schemas[name] = apiStructSchema(t, schemas)
+                return ref
+            }
+            return apiStructSchema(t, schemas)
+    }
+    return apiSchema{}
+}
+
+// 命名结构体在components/schemas中的名称，使用包路径及类型名称，防止不同包的同名结构体相互覆盖；
+// 名称只能包含字母、数字及"."、"-"、"_"，例如：github.com/foo/bar.User => github.com.foo.bar.User
+func apiSchemaName(t reflect.Type) string {
+    name := t.Name()
+    if t.PkgPath() != "" {
+        name = t.PkgPath() + "." + name
+    }
+    return strings.Map(func(r rune) rune {
+        switch {
+            case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+                return r
+            case r == '/':
+                return '.'
+        }
+        return '_'
+    }, name)
+}
+
+// 生成结构体的Schema
+func apiStructSchema(t reflect.Type, schemas map[string]interface{}) apiSchema {
+    properties := make(map[string]interface{})
+    required   := make([]string, 0)
+    apiStructFields(t, func(field reflect.StructField, name string, isRequired bool) {
+        schema := apiTypeSchema(field.Type, schemas)
+        if doc := field.Tag.Get("doc"); doc != "" {
+            if _, ok := schema["$ref"]; ok {
+                // $ref不能与其他属性并存
+                schema = apiSchema{"allOf" : []interface{}{schema}, "description" : doc}
+            } else {
+                schema["description"] = doc
+            }
+        }
+        properties[name] = schema
+        if isRequired {
+            required = append(required, name)
+        }
+    })
+    schema := apiSchema {
+        "type"       : "object",
+        "properties" : properties,
+    }
+    if len(required) > 0 {
+        schema["required"] = required
+    }
+    return schema
+}
@@ -0,0 +1,55 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package ghttp
+
+import (
+    "fmt"
+    "reflect"
+    "testing"
+)
+
+func Test_OpenApi_Path(t *testing.T) {
+    cases := []struct {
+        uri    string // 路由规则
+        path   string // 期望的OpenAPI路径
+        params string // 期望的路径参数(名称:Schema)
+    }{
+        {"/",                         "/",                          "[]"},
+        {"/user/list",                "/user/list",                 "[]"},
+        {"/user/:id/*path",           "/user/{id}/{path}",          "[id:map[type:string] path:map[type:string]]"},
+        {"/post/:id<int>",            "/post/{id}",                 "[id:map[type:integer]]"},
+        {`/user/{id:\d+}`,            "/user/{id}",                 `[id:map[pattern:^\d+$ type:string]]`},
+        {"/page/{page}.html",         "/page/{page}.html",          "[page:map[type:string]]"},
+        {"/list/{type}-{page:uint}",  "/list/{type}-{page}",        "[type:map[type:string] page:map[type:integer]]"},
+        {"/file/:<alpha>/*",          "/file/{param0}/{param1}",    "[param0:map[pattern:^[a-zA-Z]+$ type:string] param1:map[type:string]]"},
+    }
+    for _, c := range cases {
+        path, params := apiPathFromUri(c.uri)
+        array        := make([]string, 0)
+        for _, p := range params {
+            array = append(array, fmt.Sprintf("%s:%v", p.name, p.schema))
+        }
+        if path != c.path || fmt.Sprint(array) != c.params {
+            t.Errorf("%s: expect %s %s, got %s %v", c.uri, c.path, c.params, path, array)
+        }
+    }
+}
+
+func Test_OpenApi_SchemaName(t *testing.T) {
+    type Item struct {
+        Name string
+    }
+    schemas := make(map[string]interface{})
+    schema  := apiTypeSchema(reflect.TypeOf(struct{ A Item; B UploadFile }{}), schemas)
+    if _, ok := schemas["gitee.com.johng.gf.g.net.ghttp.Item"]; !ok || len(schemas) != 1 {
+        t.Error("unexpected component schemas:", schemas)
+    }
+    // 匿名结构体直接内联
+    if schema["type"] != "object" {
+        t.Error("unexpected anonymous struct schema:", schema)
+    }
+}
@@ -0,0 +1,271 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 请求处理panic恢复及错误页面(开发模式调试页面、生产模式统一错误输出).
+
+package ghttp
+
+import (
+    "fmt"
+    "bytes"
+    "runtime"
+    "strings"
+    "net/http"
+    "io/ioutil"
+    "html/template"
+    "encoding/json"
+    "net/http/httputil"
+)
+
+const (
+    ERROR_MODE_PROD = "prod" // 生产模式：输出统一格式的错误信息，不暴露内部细节
+    ERROR_MODE_DEV  = "dev"  // 开发模式：输出包含错误堆栈、请求内容及源码片段的调试页面
+    gERROR_SOURCE_LINES = 5  // 调试页面中panic位置前后显示的源码行数
+)
+
+// 自定义panic恢复处理方法，设置后替代默认的错误页面输出
+type RecoveryHandlerFunc func(r *Request, err interface{})
+
+// panic调用栈中的单个调用
+type errorStackFrame struct {
+    Func string `json:"func"`
+    File string `json:"file"`
+    Line int    `json:"line"`
+}
+
+// 错误输出内容(JSON格式输出及页面模板使用)
+type errorEntity struct {
+    Code      int               `json:"code"`
+    Message   string            `json:"message"`
+    RequestId string            `json:"request_id"`
+    Error     string            `json:"error,omitempty"`
+    Stack     []errorStackFrame `json:"stack,omitempty"`
+    Request   string            `json:"-"`
+    Source    []errorSourceLine `json:"-"`
+}
+
+// 源码片段中的一行
+type errorSourceLine struct {
+    Line    int
+    Content string
+    Current bool
+}
+
+// 设置错误页面模式：prod(默认)或者dev，开发模式下panic时输出调试页面，请勿在生产环境中开启
+func (s *Server) SetErrorMode(mode string) {
+    if mode != ERROR_MODE_DEV {
+        mode = ERROR_MODE_PROD
+    }
+    s.config.ErrorMode = mode
+    s.errorMode.Set(mode)
+}
+
+// 获取错误页面模式
+func (s *Server) GetErrorMode() string {
+    return s.errorMode.Val()
+}
+
+// 设置panic错误到HTTP状态码的转换方法，返回值<=0时使用默认的500状态码，例如：
+// s.SetErrorStatusFunc(func(err interface{}) int {
+//     if err == ErrNotFound { return 404 }
+//     return 0
+// })
+func (s *Server) SetErrorStatusFunc(f func(err interface{}) int) {
+    s.errorStatusFunc.Set(f)
+}
+
+// 设置自定义的panic恢复处理方法，设置后不再输出默认的错误页面，
+// 处理方法中没有设置状态码时使用转换后的状态码(默认为500)
+func (s *Server) SetRecoveryHandler(f RecoveryHandlerFunc) {
+    s.recoveryHandler.Set(f)
+}
+
+// 获取请求处理过程中产生的panic错误，一般在状态码回调或者事件回调中使用，没有错误时返回nil
+func (r *Request) GetError() interface{} {
+    return r.error
+}
+
+// panic恢复处理：记录错误日志，并按照错误页面模式输出错误信息；
+// 需要在defer的recover之后直接调用，以便获取panic时的调用栈。
+func (s *Server) handleRecovery(err interface{}, r *Request) {
+    stack := getPanicStack()
+    r.error = err
+    s.handleErrorLog(err, r)
+    // 已经输出到客户端的内容无法撤回
+    if r.Response.flushed {
+        return
+    }
+    status := http.StatusInternalServerError
+    if v := s.errorStatusFunc.Val(); v != nil {
+        if code := v.(func(err interface{}) int)(err); code > 0 {
+            status = code
+        }
+    }
+    r.Response.ClearBuffer()
+    if v := s.recoveryHandler.Val(); v != nil {
+        v.(RecoveryHandlerFunc)(r, err)
+        if r.Response.Status == http.StatusOK {
+            r.Response.WriteHeader(status)
+        }
+    } else if s.getStatusHandler(status, r) != nil {
+        r.Response.WriteStatus(status)
+    } else {
+        s.writeErrorEntity(r, status, err, stack)
+    }
+    r.Response.OutputBuffer()
+}
+
+// 输出默认的错误信息，客户端请求JSON时输出JSON，否则输出HTML页面
+func (s *Server) writeErrorEntity(r *Request, status int, err interface{}, stack []errorStackFrame) {
+    entity := &errorEntity {
+        Code      : status,
+        Message   : http.StatusText(status),
+        RequestId : r.Id(),
+    }
+    // 客户端错误的错误信息一般是给用户看的，服务端错误不暴露内部细节
+    if status < http.StatusInternalServerError {
+        entity.Message = fmt.Sprintf("%v", err)
+    }
+    dev := s.GetErrorMode() == ERROR_MODE_DEV
+    if dev {
+        entity.Error   = fmt.Sprintf("%v", err)
+        entity.Stack   = stack
+        entity.Request = dumpErrorRequest(r)
+        if len(stack) > 0 {
+            entity.Source = readErrorSource(stack[0].File, stack[0].Line)
+        }
+    }
+    r.Response.Header().Set("X-Content-Type-Options", "nosniff")
+    if isJsonRequest(r) {
+        b, _ := json.Marshal(entity)
+        r.Response.Header().Set("Content-Type", "application/json")
+        r.Response.Write(b)
+    } else {
+        tpl    := errorProdTemplate
+        if dev {
+            tpl = errorDevTemplate
+        }
+        buffer := bytes.NewBuffer(nil)
+        tpl.Execute(buffer, entity)
+        r.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+        r.Response.Write(buffer.Bytes())
+    }
+    r.Response.WriteHeader(status)
+}
+
+// 判断客户端是否期望JSON格式的返回(AJAX请求、Accept或者请求内容为JSON)
+func isJsonRequest(r *Request) bool {
+    if r.IsAjaxRequest() || getBodyFormat(r.Header.Get("Content-Type")) == ENTITY_FORMAT_JSON {
+        return true
+    }
+    for _, item := range parseAccept(r.Header.Get("Accept")) {
+        if item.q == 0 {
+            continue
+        }
+        switch entityMimeFormats[item.mime] {
+            case ENTITY_FORMAT_JSON:
+                return item.mime != "*/*" && item.mime != "application/*"
+            case "":
+                if item.mime == "text/html" {
+                    return false
+                }
+        }
+    }
+    return false
+}
+
+// 获取panic位置开始的调用栈，去掉recover处理及runtime内部的调用
+func getPanicStack() []errorStackFrame {
+    pcs    := make([]uintptr, 64)
+    n      := runtime.Callers(2, pcs)
+    frames := runtime.CallersFrames(pcs[:n])
+    stack  := make([]errorStackFrame, 0)
+    found  := false
+    for {
+        frame, more := frames.Next()
+        if found {
+            if !strings.HasPrefix(frame.Function, "runtime.") {
+                stack = append(stack, errorStackFrame{frame.Function, frame.File, frame.Line})
+            }
+        } else if frame.Function == "runtime.gopanic" {
+            found = true
+        }
+        if !more {
+            break
+        }
+    }
+    return stack
+}
+
+// 获取请求内容(header及已经读取的请求体)
+func dumpErrorRequest(r *Request) string {
+    b, err := httputil.DumpRequest(&r.Request, false)
+    if err != nil {
+        return ""
+    }
+    if r.rawRead {
+        b = append(b, r.rawBody...)
+    }
+    return string(b)
+}
+
+// 读取源码文件中指定行前后的内容
+func readErrorSource(file string, line int) []errorSourceLine {
+    content, err := ioutil.ReadFile(file)
+    if err != nil {
+        return nil
+    }
+    lines  := strings.Split(string(content), "\n")
+    result := make([]errorSourceLine, 0, gERROR_SOURCE_LINES*2 + 1)
+    for i := line - gERROR_SOURCE_LINES; i <= line + gERROR_SOURCE_LINES; i++ {
+        if i < 1 || i > len(lines) {
+            continue
+        }
+        result = append(result, errorSourceLine{i, lines[i - 1], i == line})
+    }
+    return result
+}
+
+// 生产模式错误页面
+var errorProdTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Code}} {{.Message}}</title></head>
+<body>
+    <h1>{{.Code}} {{.Message}}</h1>
+    <p>Request Id: {{.RequestId}}</p>
+</body>
+</html>`))
+
+// 开发模式调试页面
+var errorDevTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>{{.Code}} {{.Error}}</title>
+    <style>
+        body {font-family: Arial, sans-serif; margin: 20px; color: #333;}
+        h1   {color: #c00; font-size: 20px;}
+        h2   {font-size: 16px; border-bottom: 1px solid #ddd; padding-bottom: 5px;}
+        pre  {background: #f6f6f6; padding: 10px; overflow: auto; font-size: 13px;}
+        .current {background: #fdd;}
+        .func    {color: #06c;}
+    </style>
+</head>
+<body>
+    <h1>{{.Code}} {{.Error}}</h1>
+    <p>Request Id: {{.RequestId}}</p>
+    {{if .Source}}
+    <h2>Source</h2>
+    <pre>{{range .Source}}<span{{if .Current}} class="current"{{end}}>{{printf "%5d" .Line}}  {{.Content}}</span>
+{{end}}</pre>
+    {{end}}
+    <h2>Stack</h2>
+    <pre>{{range .Stack}}<span class="func">{{.Func}}</span>
+    {{.File}}:{{.Line}}
+{{end}}</pre>
+    <h2>Request</h2>
+    <pre>{{.Request}}</pre>
+</body>
+</html>`))
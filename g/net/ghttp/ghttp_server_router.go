@@ -9,13 +9,13 @@ package ghttp
 
 import (
     "errors"
+    "regexp"
     "strings"
-    "container/list"
     "gitee.com/johng/gf/g/util/gregex"
-    "gitee.com/johng/gf/g/util/gstr"
     "gitee.com/johng/gf/g/os/glog"
     "fmt"
     "runtime"
+    "path/filepath"
 )
 
 
@@ -24,11 +24,11 @@ func (s *Server)parsePattern(pattern string) (domain, method, uri string, err er
     uri    = pattern
     domain = gDEFAULT_DOMAIN
     method = gDEFAULT_METHOD
-    if array, err := gregex.MatchString(`([a-zA-Z]+):(.+)`, pattern); len(array) > 1 && err == nil {
+    if array, err := gregex.MatchString(`^([a-zA-Z]+):(.+)`, pattern); len(array) > 1 && err == nil {
         method = array[1]
         uri    = array[2]
     }
-    if array, err := gregex.MatchString(`(.+)@([\w\.\-]+)`, uri); len(array) > 1 && err == nil {
+    if array, err := gregex.MatchString(`(.+)@([\w\.\-\*\{\}]+)$`, uri); len(array) > 1 && err == nil {
         uri     = array[1]
         domain  = array[2]
     }
@@ -42,14 +42,19 @@ func (s *Server)parsePattern(pattern string) (domain, method, uri string, err er
     return
 }
 
-// 获得服务注册的文件地址信息
-func (s *Server) getHandlerRegisterCallerLine(handler *handlerItem) string {
-    skip := 5
-    if handler.rtype == gROUTE_REGISTER_HANDLER {
-       skip = 4
-    }
-    if _, cfile, cline, ok := runtime.Caller(skip); ok {
-        return fmt.Sprintf("%s:%d", cfile, cline)
+// 获得服务注册的文件地址信息，即调用栈中第一个ghttp包以外(或者ghttp包测试文件)的调用位置，
+// 不同注册方式(服务方法、执行对象、控制器、事件回调、域名注册等)的调用层级不同，因此不能使用固定的层级
+func (s *Server) getHandlerRegisterCallerLine() string {
+    _, pfile, _, _ := runtime.Caller(0)
+    pdir := filepath.Dir(pfile)
+    for i := 1; ; i++ {
+        _, cfile, cline, ok := runtime.Caller(i)
+        if !ok {
+            break
+        }
+        if filepath.Dir(cfile) != pdir || strings.HasSuffix(cfile, "_test.go") {
+            return fmt.Sprintf("%s:%d", cfile, cline)
+        }
     }
     return ""
 }
@@ -58,18 +63,24 @@ func (s *Server) getHandlerRegisterCallerLine(handler *handlerItem) string {
 // 如果带有hook参数，表示是回调注册方法，否则为普通路由执行方法。
 func (s *Server) setHandler(pattern string, handler *handlerItem, hook ... string) (resultErr error) {
     // Web Server正字运行时无法动态注册路由方法
-    if s.Status() == SERVER_STATUS_RUNNING {
+    if s.isServing() {
         return errors.New("cannot bind handler while server running")
     }
-    caller := s.getHandlerRegisterCallerLine(handler)
-    if line, ok := s.routesMap[pattern]; ok {
+    caller   := s.getHandlerRegisterCallerLine()
+    routeKey := pattern
+    handler.caller = caller
+    // 事件回调与服务回调的路由注册分开记录，同一个pattern可以同时注册服务方法及事件回调
+    if len(hook) > 0 {
+        routeKey = hook[0] + "#" + pattern
+    }
+    if line, ok := s.routesMap[routeKey]; ok {
         s := fmt.Sprintf(`duplicated route registry "%s" in %s , former in %s`, pattern, caller, line)
-        glog.Errorfln(s)
+        glog.Error(s)
         return errors.New(s)
     } else {
         defer func() {
             if resultErr == nil {
-                s.routesMap[pattern] = caller
+                s.routesMap[routeKey] = caller
             }
         }()
     }
@@ -78,118 +89,53 @@ func (s *Server) setHandler(pattern string, handler *handlerItem, hook ... strin
     if len(hook) > 0 {
         hookName = hook[0]
     }
-    domain, method, uri, err := s.parsePattern(pattern)
+    router, err := s.newRouter(pattern)
     if err != nil {
-        return errors.New("invalid pattern")
+        return err
     }
+    domain := router.Domain
+    handler.router = router
 
-    // 路由对象
-    handler.router = &Router {
-        Uri      : uri,
-        Domain   : domain,
-        Method   : method,
-        Priority : strings.Count(uri[1:], "/"),
-    }
-    handler.router.RegRule, handler.router.RegNames = s.patternToRegRule(uri)
-
-    // 动态注册，首先需要判断是否是动态注册，如果不是那么就没必要添加到动态注册记录变量中。
-    // 非叶节点为哈希表检索节点，按照URI注册的层级进行高效检索，直至到叶子链表节点；
-    // 叶子节点是链表，按照优先级进行排序，优先级高的排前面，按照遍历检索，按照哈希表层级检索后的叶子链表数据量不会很大，所以效率比较高；
-    tree := (map[string]interface{})(nil)
+    // 每个域名(及每个事件)对应一棵路由前缀树，按照路由段逐级检索；
+    // 服务回调相同的路由注册项(相同的method及uri)会进行替换，事件回调则按照注册顺序追加。
     if len(hookName) == 0 {
-        tree = s.serveTree
-    } else {
-        tree = s.hooksTree
-    }
-    if _, ok := tree[domain]; !ok {
-        tree[domain] = make(map[string]interface{})
-    }
-    // 用于遍历的指针
-    p := tree[domain]
-    if len(hookName) > 0 {
-        if _, ok := p.(map[string]interface{})[hookName]; !ok {
-            p.(map[string]interface{})[hookName] = make(map[string]interface{})
+        if _, ok := s.serveTree[domain]; !ok {
+            s.serveTree[domain] = newRouterTree()
         }
-        p = p.(map[string]interface{})[hookName]
-    }
-    // 当前节点的规则链表
-    lists := make([]*list.List, 0)
-    array := ([]string)(nil)
-    if strings.EqualFold("/", uri) {
-        array = []string{"/"}
+        return s.serveTree[domain].add(handler, true)
     } else {
-        array = strings.Split(uri[1:], "/")
-    }
-    // 键名"*fuzz"代表模糊匹配节点，其下会有一个链表；
-    // 键名"*list"代表链表，叶子节点和模糊匹配节点都有该属性；
-    for k, v := range array {
-        if len(v) == 0 {
-            continue
-        }
-        // 判断是否模糊匹配规则
-        if gregex.IsMatchString(`^[:\*]|\{[\w\.\-]+\}|\*`, v) {
-            v = "*fuzz"
-            // 由于是模糊规则，因此这里会有一个*list，用以将后续的路由规则加进来，
-            // 检索会从叶子节点的链表往根节点按照优先级进行检索
-            if v, ok := p.(map[string]interface{})["*list"]; !ok {
-                p.(map[string]interface{})["*list"] = list.New()
-                lists = append(lists, p.(map[string]interface{})["*list"].(*list.List))
-            } else {
-                lists = append(lists, v.(*list.List))
-            }
+        if _, ok := s.hooksTree[domain]; !ok {
+            s.hooksTree[domain] = make(map[string]*routerTree)
         }
-        // 属性层级数据写入
-        if _, ok := p.(map[string]interface{})[v]; !ok {
-            p.(map[string]interface{})[v] = make(map[string]interface{})
-        }
-        p = p.(map[string]interface{})[v]
-        // 到达叶子节点，往list中增加匹配规则(条件 v != "*fuzz" 是因为模糊节点的话在前面已经添加了*list链表)
-        if k == len(array) - 1 && v != "*fuzz" {
-            if v, ok := p.(map[string]interface{})["*list"]; !ok {
-                p.(map[string]interface{})["*list"] = list.New()
-                lists = append(lists, p.(map[string]interface{})["*list"].(*list.List))
-            } else {
-                lists = append(lists, v.(*list.List))
-            }
+        if _, ok := s.hooksTree[domain][hookName]; !ok {
+            s.hooksTree[domain][hookName] = newRouterTree()
         }
+        return s.hooksTree[domain][hookName].add(handler, false)
     }
-    // 得到的lists是该路由规则一路匹配下来相关的模糊匹配链表(注意不是这棵树所有的链表)，
-    // 从头开始遍历每个节点的模糊匹配链表，将该路由项插入进去(按照优先级高的放在前面)
-    item := (*handlerItem)(nil)
-    for _, l := range lists {
-        pushed  := false
-        for e := l.Front(); e != nil; e = e.Next() {
-            item = e.Value.(*handlerItem)
-            // 判断是否已存在相同的路由注册项，是则进行替换
-            if len(hookName) == 0 {
-                if strings.EqualFold(handler.router.Domain, item.router.Domain) &&
-                    strings.EqualFold(handler.router.Method, item.router.Method) &&
-                    strings.EqualFold(handler.router.Uri, item.router.Uri) {
-                    e.Value = handler
-                    pushed  = true
-                    break
-                }
-            }
-            if s.compareRouterPriority(handler.router, item.router) {
-                l.InsertBefore(handler, e)
-                pushed = true
-                break
-            }
-        }
-        if !pushed {
-            l.PushBack(handler)
-        }
+}
+
+// 根据pattern创建路由对象，域名模式(例如：*.example.com、{tenant}.example.com)同时进行预先编译
+func (s *Server) newRouter(pattern string) (*Router, error) {
+    domain, method, uri, err := s.parsePattern(pattern)
+    if err != nil {
+        return nil, errors.New("invalid pattern")
+    }
+    s.addDomainPattern(domain)
+    router := &Router {
+        Uri      : uri,
+        Domain   : domain,
+        Method   : method,
+        Priority : strings.Count(uri[1:], "/"),
     }
-    //gutil.Dump(s.serveTree)
-    //gutil.Dump(s.hooksTree)
-    return nil
+    router.RegRule, router.RegNames = s.patternToRegRule(uri)
+    return router, nil
 }
 
 // 对比两个handlerItem的优先级，需要非常注意的是，注意新老对比项的参数先后顺序。
 // 优先级比较规则：
 // 1、层级越深优先级越高(对比/数量)；
 // 2、模糊规则优先级：{xxx} > :xxx > *xxx；
-func (s *Server) compareRouterPriority(newRouter, oldRouter *Router) bool {
+func compareRouterPriority(newRouter, oldRouter *Router) bool {
     if newRouter.Priority > oldRouter.Priority {
         return true
     }
@@ -197,32 +143,10 @@ func (s *Server) compareRouterPriority(newRouter, oldRouter *Router) bool {
         return false
     }
     // 精准匹配比模糊匹配规则优先级高，例如：/name/act 比 /{name}/:act 优先级高
-    var fuzzyCountFieldNew, fuzzyCountFieldOld int
-    var fuzzyCountNameNew,  fuzzyCountNameOld  int
-    var fuzzyCountAnyNew,   fuzzyCountAnyOld   int
-    var fuzzyCountTotalNew, fuzzyCountTotalOld int
-    for _, v := range newRouter.Uri {
-        switch v {
-            case '{':
-                fuzzyCountFieldNew++
-            case ':':
-                fuzzyCountNameNew++
-            case '*':
-                fuzzyCountAnyNew++
-        }
-    }
-    for _, v := range oldRouter.Uri {
-        switch v {
-            case '{':
-                fuzzyCountFieldOld++
-            case ':':
-                fuzzyCountNameOld++
-            case '*':
-                fuzzyCountAnyOld++
-        }
-    }
-    fuzzyCountTotalNew = fuzzyCountFieldNew + fuzzyCountNameNew + fuzzyCountAnyNew
-    fuzzyCountTotalOld = fuzzyCountFieldOld + fuzzyCountNameOld + fuzzyCountAnyOld
+    fuzzyCountFieldNew, fuzzyCountNameNew, fuzzyCountAnyNew, constraintCountNew := routerFuzzyCounts(newRouter.Uri)
+    fuzzyCountFieldOld, fuzzyCountNameOld, fuzzyCountAnyOld, constraintCountOld := routerFuzzyCounts(oldRouter.Uri)
+    fuzzyCountTotalNew := fuzzyCountFieldNew + fuzzyCountNameNew + fuzzyCountAnyNew
+    fuzzyCountTotalOld := fuzzyCountFieldOld + fuzzyCountNameOld + fuzzyCountAnyOld
     if fuzzyCountTotalNew < fuzzyCountTotalOld {
         return true
     }
@@ -246,13 +170,22 @@ func (s *Server) compareRouterPriority(newRouter, oldRouter *Router) bool {
     if fuzzyCountNameNew < fuzzyCountNameOld {
         return false
     }
-    // 比较HTTP METHOD，更精准的优先级更高
-    if newRouter.Method != gDEFAULT_METHOD {
+    // 带有约束规则的参数越多优先级越高，例如：/user/{id:\d+} 比 /user/{name} 优先级高
+    if constraintCountNew > constraintCountOld {
         return true
     }
-    if oldRouter.Method != gDEFAULT_METHOD {
+    if constraintCountNew < constraintCountOld {
+        return false
+    }
+    // 比较HTTP METHOD，更精准的优先级更高，例如：GET:/user 比 /user 优先级高
+    newIsDefault := strings.EqualFold(newRouter.Method, gDEFAULT_METHOD)
+    oldIsDefault := strings.EqualFold(oldRouter.Method, gDEFAULT_METHOD)
+    if !newIsDefault && oldIsDefault {
         return true
     }
+    if newIsDefault && !oldIsDefault {
+        return false
+    }
     // 模糊规则数量相等，后续不用再判断*规则的数量比较了，
     // 这种情况下新的规则比旧的规则优先级更高
     return true
@@ -264,51 +197,32 @@ func (s *Server) patternToRegRule(rule string) (regrule string, names []string)
         return rule, nil
     }
     regrule = "^"
-    array  := strings.Split(rule[1:], "/")
-    for _, v := range array {
-        if len(v) == 0 {
-            continue
-        }
-        switch v[0] {
-            case ':':
-                if len(v) > 1 {
+    for _, v := range splitRouterPath(rule) {
+        segment := parseRouterSegment(v)
+        switch segment.kind {
+            case gROUTER_SEGMENT_PARAM:
+                if segment.names[0] != "" {
                     regrule += `/([\w\.\-]+)`
-                    names    = append(names, v[1:])
-                    break
                 } else {
                     regrule += `/[\w\.\-]+`
-                    break
                 }
-                fallthrough
-            case '*':
-                if len(v) > 1 {
+            case gROUTER_SEGMENT_ANY:
+                if segment.names[0] != "" {
                     regrule += `/{0,1}(.*)`
-                    names    = append(names, v[1:])
-                    break
                 } else {
                     regrule += `/{0,1}.*`
-                    break
                 }
-                fallthrough
+            case gROUTER_SEGMENT_REGEX:
+                regrule += "/" + segment.rule[1 : len(segment.rule) - 1]
             default:
-                // 特殊字符替换
-                v = gstr.ReplaceByMap(v, map[string]string{
-                    `.` : `\.`,
-                    `+` : `\+`,
-                    `*` : `.*`,
-                })
-                s, _ := gregex.ReplaceStringFunc(`\{[\w\.\-]+\}`, v, func(s string) string {
-                    names = append(names, s[1 : len(s) - 1])
-                    return `([\w\.\-]+)`
-                })
-                if strings.EqualFold(s, v) {
-                    regrule += "/" + v
-                } else {
-                    regrule += "/" + s
-                }
+                regrule += "/" + regexp.QuoteMeta(v)
+        }
+        for _, name := range segment.names {
+            if name != "" {
+                names = append(names, name)
+            }
         }
     }
     regrule += `$`
     return
 }
-
@@ -0,0 +1,132 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 域名模式(通配符及命名参数)路由控制.
+
+package ghttp
+
+import (
+    "regexp"
+    "strings"
+    "gitee.com/johng/gf/g/os/glog"
+)
+
+// 域名模式中的通配符(*)及命名参数({name})，均只匹配一级域名标签
+var domainPatternTokenRegex = regexp.MustCompile(`\{(\w+)\}|\*`)
+
+// 域名模式，例如：*.example.com、{tenant}.example.com、api.{tenant}.example.com
+type domainPattern struct {
+    domain  string         // 注册时的域名模式，同时作为路由树及状态码回调的键名
+    regex   *regexp.Regexp // 域名匹配正则表达式(不区分大小写)
+    names   []string       // 正则子匹配对应的参数名称，通配符为空
+    literal int            // 固定字符数量，越多优先级越高
+}
+
+// 请求域名匹配到的域名模式及解析的参数
+type domainMatchItem struct {
+    domain string              // 匹配的域名模式
+    values map[string][]string // 域名命名参数
+}
+
+// 判断给定的域名是否为域名模式
+func isDomainPattern(domain string) bool {
+    return strings.ContainsAny(domain, "*{")
+}
+
+// 添加域名模式，非域名模式或者已经添加的域名模式将被忽略；
+// 域名模式按照优先级排序：固定字符越多优先级越高，相同时按照注册顺序。
+func (s *Server) addDomainPattern(domain string) {
+    if !isDomainPattern(domain) {
+        return
+    }
+    for _, v := range s.domainPatterns {
+        if v.domain == domain {
+            return
+        }
+    }
+    p := &domainPattern {
+        domain : domain,
+    }
+    expr   := "(?i)^"
+    offset := 0
+    for _, loc := range domainPatternTokenRegex.FindAllStringSubmatchIndex(domain, -1) {
+        expr      += regexp.QuoteMeta(domain[offset : loc[0]])
+        p.literal += loc[0] - offset
+        expr      += `([^\.]+)`
+        if loc[2] != -1 {
+            p.names = append(p.names, domain[loc[2] : loc[3]])
+        } else {
+            p.names = append(p.names, "")
+        }
+        offset = loc[1]
+    }
+    expr      += regexp.QuoteMeta(domain[offset:]) + "$"
+    p.literal += len(domain) - offset
+    regex, err := regexp.Compile(expr)
+    if err != nil {
+        glog.Error("invalid domain pattern \"" + domain + "\": " + err.Error())
+        return
+    }
+    p.regex = regex
+    // 插入排序，保证相同优先级的域名模式按照注册顺序
+    index := len(s.domainPatterns)
+    for i, v := range s.domainPatterns {
+        if p.literal > v.literal {
+            index = i
+            break
+        }
+    }
+    s.domainPatterns = append(s.domainPatterns, nil)
+    copy(s.domainPatterns[index + 1:], s.domainPatterns[index:])
+    s.domainPatterns[index] = p
+}
+
+// 获取请求域名需要检索的域名列表(不包括默认域名)：
+// 首先是与请求域名完全相同的域名，其次是匹配的域名模式(按照优先级排序)，结果在请求内缓存。
+func (r *Request) getDomainMatches() []*domainMatchItem {
+    if r.domainMatches != nil {
+        return r.domainMatches
+    }
+    host    := r.GetHost()
+    matches := make([]*domainMatchItem, 0, 1)
+    if !strings.EqualFold(gDEFAULT_DOMAIN, host) {
+        matches = append(matches, &domainMatchItem{domain : host})
+    }
+    for _, p := range r.Server.domainPatterns {
+        array := p.regex.FindStringSubmatch(host)
+        if array == nil {
+            continue
+        }
+        item := &domainMatchItem{domain : p.domain}
+        for i, name := range p.names {
+            if name == "" {
+                continue
+            }
+            if item.values == nil {
+                item.values = make(map[string][]string)
+            }
+            item.values[name] = append(item.values[name], array[i + 1])
+        }
+        matches = append(matches, item)
+    }
+    r.domainMatches = matches
+    return matches
+}
+
+// 获取请求域名匹配的域名模式参数(例如：{tenant}.example.com 中的tenant)，
+// 多个域名模式存在同名参数时，优先级高的域名模式优先
+func (r *Request) getDomainVars() map[string][]string {
+    vars    := (map[string][]string)(nil)
+    matches := r.getDomainMatches()
+    for i := len(matches) - 1; i >= 0; i-- {
+        for k, v := range matches[i].values {
+            if vars == nil {
+                vars = make(map[string][]string)
+            }
+            vars[k] = v
+        }
+    }
+    return vars
+}
@@ -0,0 +1,226 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 路由、事件回调、路由规则及状态码回调的注册信息查询.
+
+package ghttp
+
+import (
+    "fmt"
+    "sort"
+    "bytes"
+    "reflect"
+    "runtime"
+    "strings"
+    "text/tabwriter"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/os/gproc"
+    "gitee.com/johng/gf/g/util/gconv"
+)
+
+// 路由注册信息
+type RouteInfo struct {
+    Domain   string `json:"domain"`         // 注册的域名
+    Method   string `json:"method"`         // 注册的HTTP Method
+    Route    string `json:"route"`          // 注册的路由规则(不包含method及domain)
+    Hook     string `json:"hook,omitempty"` // 事件名称(事件回调有效)
+    Rule     string `json:"rule,omitempty"` // 路由规则类型(路由规则有效)：IpFilter、JwtAuth、Upload
+    Type     string `json:"type"`           // 注册方式：handler、object、controller、hook、rule
+    Handler  string `json:"handler"`        // 执行的方法名称
+    Source   string `json:"source"`         // 注册时的文件地址(文件:行号)
+    Priority int    `json:"priority"`       // 在同一域名(及事件)下的匹配优先级排名，值越小优先级越高
+}
+
+// 状态码回调注册信息
+type StatusHandlerInfo struct {
+    Domain  string `json:"domain"`  // 注册的域名
+    Status  int    `json:"status"`  // HTTP状态码
+    Handler string `json:"handler"` // 执行的方法名称
+}
+
+// 事件回调按照执行顺序展示
+var hookNames = []string {
+    HOOK_BEFORE_SERVE,
+    HOOK_AFTER_SERVE,
+    HOOK_BEFORE_OUTPUT,
+    HOOK_AFTER_OUTPUT,
+    HOOK_BEFORE_CLOSE,
+    HOOK_AFTER_CLOSE,
+}
+
+// 获取所有注册的服务路由，按照域名、路由规则及Method排序
+func (s *Server) GetRoutes() []RouteInfo {
+    routes := make([]RouteInfo, 0)
+    for _, tree := range s.serveTree {
+        for e := tree.items.Front(); e != nil; e = e.Next() {
+            routes = append(routes, newRouteInfo(e.Value.(*handlerItem), ""))
+        }
+    }
+    sort.Slice(routes, func(i, j int) bool {
+        if routes[i].Domain != routes[j].Domain {
+            return routes[i].Domain < routes[j].Domain
+        }
+        if routes[i].Route != routes[j].Route {
+            return routes[i].Route < routes[j].Route
+        }
+        return routes[i].Method < routes[j].Method
+    })
+    return routes
+}
+
+// 获取所有注册的事件回调，按照域名、事件执行顺序及匹配优先级排序
+func (s *Server) GetHookRoutes() []RouteInfo {
+    routes := make([]RouteInfo, 0)
+    for _, trees := range s.hooksTree {
+        for hook, tree := range trees {
+            for e := tree.items.Front(); e != nil; e = e.Next() {
+                routes = append(routes, newRouteInfo(e.Value.(*handlerItem), hook))
+            }
+        }
+    }
+    sort.Slice(routes, func(i, j int) bool {
+        if routes[i].Domain != routes[j].Domain {
+            return routes[i].Domain < routes[j].Domain
+        }
+        if x, y := hookIndex(routes[i].Hook), hookIndex(routes[j].Hook); x != y {
+            return x < y
+        }
+        if routes[i].Hook != routes[j].Hook {
+            return routes[i].Hook < routes[j].Hook
+        }
+        return routes[i].Priority < routes[j].Priority
+    })
+    return routes
+}
+
+// 获取所有注册的路由规则(IP访问控制、JWT认证、上传配置等)，按照域名、规则执行顺序及匹配优先级排序
+func (s *Server) GetRuleRoutes() []RouteInfo {
+    routes := make([]RouteInfo, 0)
+    for _, trees := range s.rulesTree {
+        for kind, tree := range trees {
+            for e := tree.items.Front(); e != nil; e = e.Next() {
+                info     := newRouteInfo(e.Value.(*handlerItem), "")
+                info.Rule = kind
+                info.Type = "rule"
+                routes    = append(routes, info)
+            }
+        }
+    }
+    sort.Slice(routes, func(i, j int) bool {
+        if routes[i].Domain != routes[j].Domain {
+            return routes[i].Domain < routes[j].Domain
+        }
+        if x, y := ruleIndex(routes[i].Rule), ruleIndex(routes[j].Rule); x != y {
+            return x < y
+        }
+        return routes[i].Priority < routes[j].Priority
+    })
+    return routes
+}
+
+// 获取所有注册的状态码回调，按照域名及状态码排序
+func (s *Server) GetStatusHandlers() []StatusHandlerInfo {
+    handlers := make([]StatusHandlerInfo, 0)
+    s.hsmu.RLock()
+    for key, handler := range s.statusHandlerMap {
+        pos := strings.LastIndex(key, "#")
+        handlers = append(handlers, StatusHandlerInfo {
+            Domain  : key[:pos],
+            Status  : gconv.Int(key[pos + 1:]),
+            Handler : funcName(handler),
+        })
+    }
+    s.hsmu.RUnlock()
+    sort.Slice(handlers, func(i, j int) bool {
+        if handlers[i].Domain != handlers[j].Domain {
+            return handlers[i].Domain < handlers[j].Domain
+        }
+        return handlers[i].Status < handlers[j].Status
+    })
+    return handlers
+}
+
+// 打印所有注册的路由、事件回调、路由规则及状态码回调(调试模式下服务启动时自动打印)
+func (s *Server) DumpRoutes() {
+    glog.Printfln("%d: routes of server [%s]:\n%s", gproc.Pid(), s.name, s.formatRoutes())
+}
+
+// 将注册信息格式化为文本表格
+func (s *Server) formatRoutes() string {
+    buffer := bytes.NewBuffer(nil)
+    writer := tabwriter.NewWriter(buffer, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(writer, "DOMAIN\tMETHOD\tROUTE\tHOOK\tHANDLER\tSOURCE")
+    for _, v := range append(s.GetRoutes(), s.GetHookRoutes()...) {
+        fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", v.Domain, v.Method, v.Route, v.Hook, v.Handler, v.Source)
+    }
+    for _, v := range s.GetRuleRoutes() {
+        fmt.Fprintf(writer, "%s\t%s\t%s\tRule:%s\t%s\t%s\n", v.Domain, v.Method, v.Route, v.Rule, v.Handler, v.Source)
+    }
+    for _, v := range s.GetStatusHandlers() {
+        fmt.Fprintf(writer, "%s\t\t%d\tStatus\t%s\t\n", v.Domain, v.Status, v.Handler)
+    }
+    writer.Flush()
+    return buffer.String()
+}
+
+// 生成路由注册信息
+func newRouteInfo(item *handlerItem, hook string) RouteInfo {
+    info := RouteInfo {
+        Domain   : item.router.Domain,
+        Method   : item.router.Method,
+        Route    : item.router.Uri,
+        Hook     : hook,
+        Source   : item.caller,
+        Priority : item.rank,
+    }
+    switch item.rtype {
+        case gROUTE_REGISTER_OBJECT:     info.Type = "object"
+        case gROUTE_REGISTER_CONTROLLER: info.Type = "controller"
+        default:
+            if hook != "" {
+                info.Type = "hook"
+            } else {
+                info.Type = "handler"
+            }
+    }
+    // 对象及控制器注册方式使用"包路径.类型.方法"命名
+    if item.ctype != nil {
+        info.Handler = fmt.Sprintf("%s.%s.%s", item.ctype.PkgPath(), item.ctype.Name(), item.fname)
+    } else {
+        info.Handler = funcName(item.faddr)
+    }
+    return info
+}
+
+// 获取事件的执行顺序，自定义事件排在最后
+func hookIndex(hook string) int {
+    for i, v := range hookNames {
+        if strings.EqualFold(v, hook) {
+            return i
+        }
+    }
+    return len(hookNames)
+}
+
+// 获取路由规则的执行顺序
+func ruleIndex(kind string) int {
+    for i, v := range routeRuleKinds {
+        if v.name == kind {
+            return i
+        }
+    }
+    return len(routeRuleKinds)
+}
+
+// 获取方法的完整名称
+func funcName(f HandlerFunc) string {
+    if f == nil {
+        return ""
+    }
+    if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+        return fn.Name()
+    }
+    return ""
+}
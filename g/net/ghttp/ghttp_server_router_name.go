@@ -0,0 +1,126 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 命名路由及URL反向生成.
+
+package ghttp
+
+import (
+    "errors"
+    "strings"
+    "net/url"
+    "gitee.com/johng/gf/g/util/gconv"
+)
+
+// 设置路由名称，用于通过s.URL反向生成URL地址，pattern格式同BindHandler，例如：
+// s.SetRouteName("/user/{id:\d+}", "user.show")
+func (s *Server) SetRouteName(pattern string, name string) error {
+    if s.isServing() {
+        return errors.New("cannot set route name while server running")
+    }
+    _, _, uri, err := s.parsePattern(pattern)
+    if err != nil {
+        return err
+    }
+    // 多域名注册时同一名称会重复设置，因此只有URI不同时才认为是重复的名称
+    if v, ok := s.routeNames[name]; ok && v != uri {
+        return errors.New(`duplicated route name "` + name + `" for "` + uri + `", former "` + v + `"`)
+    }
+    s.routeNames[name] = uri
+    return nil
+}
+
+// 根据路由名称及参数生成URL地址(不包含域名)，路由规则中未使用的参数作为GET参数追加到URL末尾，
+// 例如路由"/user/{id}"的名称为"user.show"，s.URL("user.show", g.Map{"id" : 1, "tab" : "info"})返回"/user/1?tab=info"；
+// 路由名称不存在时返回空字符串。
+func (s *Server) URL(name string, params...map[string]interface{}) string {
+    uri, ok := s.routeNames[name]
+    if !ok {
+        return ""
+    }
+    values := make(map[string]string)
+    if len(params) > 0 {
+        for k, v := range params[0] {
+            values[k] = gconv.String(v)
+        }
+    }
+    path := ""
+    for _, v := range splitRouterPath(uri) {
+        segment := parseRouterSegment(v)
+        switch segment.kind {
+            case gROUTER_SEGMENT_STATIC:
+                path += "/" + v
+            case gROUTER_SEGMENT_ANY:
+                // 模糊匹配参数可以包含多个路由段
+                if value := takeRouterValue(values, segment.names[0]); value != "" {
+                    array := strings.Split(value, "/")
+                    for i, p := range array {
+                        array[i] = url.PathEscape(p)
+                    }
+                    path += "/" + strings.Join(array, "/")
+                }
+            default:
+                if v[0] == ':' {
+                    path += "/" + url.PathEscape(takeRouterValue(values, segment.names[0]))
+                    break
+                }
+                // 替换路由段中的{field}参数，*通配符替换为空
+                part := ""
+                for i := 0; i < len(v); {
+                    if v[i] == '{' {
+                        if name, _, end := parseRouterField(v, i); end != -1 {
+                            part += url.PathEscape(takeRouterValue(values, name))
+                            i     = end + 1
+                            continue
+                        }
+                    }
+                    if v[i] != '*' {
+                        part += v[i : i + 1]
+                    }
+                    i++
+                }
+                path += "/" + part
+        }
+    }
+    if path == "" {
+        path = "/"
+    }
+    if len(values) > 0 {
+        query := url.Values{}
+        for k, v := range values {
+            query.Set(k, v)
+        }
+        path += "?" + query.Encode()
+    }
+    return path
+}
+
+// 获取并删除路由参数值
+func takeRouterValue(values map[string]string, name string) string {
+    value := values[name]
+    delete(values, name)
+    return value
+}
+
+// 模板内置函数: url，参数为路由名称及成对的参数名称与参数值，例如：{{url "user.show" "id" 1}}，
+// 也可以直接传递一个参数map，例如：{{url "user.show" .params}}
+func (r *Response) funcUrl(name string, params...interface{}) string {
+    m := make(map[string]interface{})
+    if len(params) == 1 {
+        switch v := params[0].(type) {
+            case map[string]interface{}:
+                m = v
+            case map[string]string:
+                for k, value := range v {
+                    m[k] = value
+                }
+        }
+    } else {
+        for i := 0; i + 1 < len(params); i += 2 {
+            m[gconv.String(params[i])] = params[i + 1]
+        }
+    }
+    return r.Server.URL(name, m)
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 路由规则(IP访问控制、JWT认证、上传配置等框架内置的路由级别配置)控制.
+// 路由规则与事件回调分开存储，不占用开发者的事件回调注册，同一个pattern可以同时注册事件回调及各类型的路由规则。
+
+package ghttp
+
+import (
+    "errors"
+)
+
+const (
+    gROUTE_RULE_IP_FILTER = "IpFilter" // IP访问控制
+    gROUTE_RULE_JWT_AUTH  = "JwtAuth"  // JWT认证
+    gROUTE_RULE_UPLOAD    = "Upload"   // 文件上传配置
+)
+
+// 路由规则类型
+type routeRuleKind struct {
+    name  string // 规则类型名称
+    first bool   // 是否只执行优先级最高的匹配规则，否则按照优先级执行所有匹配的规则
+}
+
+// 路由规则类型，请求时在BeforeServe事件回调之前按照数组顺序执行
+var routeRuleKinds = []routeRuleKind {
+    {gROUTE_RULE_IP_FILTER, false},
+    {gROUTE_RULE_JWT_AUTH,  false},
+    {gROUTE_RULE_UPLOAD,    true},
+}
+
+// 注册路由规则，pattern参数同BindHandler；相同类型下相同的pattern(相同的method及uri)重复注册时进行替换
+func (s *Server) setRouteRule(kind string, pattern string, handler HandlerFunc) error {
+    if s.isServing() {
+        return errors.New("cannot bind route rule while server running")
+    }
+    router, err := s.newRouter(pattern)
+    if err != nil {
+        return err
+    }
+    item := &handlerItem {
+        faddr  : handler,
+        router : router,
+        caller : s.getHandlerRegisterCallerLine(),
+    }
+    if _, ok := s.rulesTree[router.Domain]; !ok {
+        s.rulesTree[router.Domain] = make(map[string]*routerTree)
+    }
+    if _, ok := s.rulesTree[router.Domain][kind]; !ok {
+        s.rulesTree[router.Domain][kind] = newRouterTree()
+    }
+    return s.rulesTree[router.Domain][kind].add(item, true)
+}
+
+// 执行请求匹配的路由规则，请求被规则终止(Exit)后不再执行后续规则.
+// 路由前缀树在Server运行期间不会改变，因此可以并发读.
+func (s *Server) callRouteRules(r *Request) {
+    if len(s.rulesTree) == 0 {
+        return
+    }
+    domains := []string{ gDEFAULT_DOMAIN }
+    for _, v := range r.getDomainMatches() {
+        domains = append(domains, v.domain)
+    }
+    for _, kind := range routeRuleKinds {
+        for _, item := range s.searchRouteRules(kind, r.Method, r.URL.Path, domains) {
+            if r.IsExited() {
+                return
+            }
+            item.handler.faddr(r)
+        }
+    }
+}
+
+// 路由规则检索，域名顺序同searchServeHandler
+func (s *Server) searchRouteRules(kind routeRuleKind, method, path string, domains []string) []*handlerParsedItem {
+    parsedItems := ([]*handlerParsedItem)(nil)
+    for _, domain := range domains {
+        tree, ok := s.rulesTree[domain][kind.name]
+        if !ok {
+            continue
+        }
+        items := tree.search(method, path, kind.first)
+        if kind.first && len(items) > 0 {
+            return items
+        }
+        parsedItems = append(parsedItems, items...)
+    }
+    return parsedItems
+}
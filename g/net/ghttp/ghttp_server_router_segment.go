@@ -0,0 +1,177 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 路由段解析(路由参数及约束规则).
+
+package ghttp
+
+import (
+    "regexp"
+    "strings"
+)
+
+const (
+    gROUTER_SEGMENT_STATIC = iota // 静态路由段，例如：list
+    gROUTER_SEGMENT_PARAM         // 命名匹配路由段，例如：:id
+    gROUTER_SEGMENT_ANY           // 模糊匹配路由段，例如：*path
+    gROUTER_SEGMENT_REGEX         // 正则匹配路由段，例如：{id}、{id:\d+}、:id<int>、{page}.html、file*
+)
+
+// 路由参数约束规则的内置类型，可用于{id:int}或者:id<int>
+var routerParamTypes = map[string]string {
+    "int"   : `-?\d+`,
+    "uint"  : `\d+`,
+    "float" : `-?\d+(?:\.\d+)?`,
+    "alpha" : `[a-zA-Z]+`,
+    "alnum" : `[a-zA-Z0-9]+`,
+    "word"  : `\w+`,
+    "uuid"  : `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`,
+}
+
+// 路由段解析结果
+type routerSegment struct {
+    kind        int      // 路由段类型
+    value       string   // 原始路由段
+    rule        string   // 路由段正则表达式(正则匹配路由段有效，包含^$)
+    names       []string // 路由参数名称(匿名参数为空字符串)
+    fields      int      // {field}参数数量
+    params      int      // :name参数数量
+    anys        int      // *any及*通配符数量
+    constraints int      // 带有约束规则的参数数量
+}
+
+// 解析路由段，支持的格式：
+// :name、:name<rule>、*any、{field}、{field:rule}，以及包含{field}/*通配符的路由段(例如：{page}.html、file*)；
+// 其中rule可以为正则表达式或者内置类型名称(int、uint、float、alpha、alnum、word、uuid)，约束规则中不能包含"/"。
+func parseRouterSegment(v string) routerSegment {
+    segment := routerSegment{kind : gROUTER_SEGMENT_STATIC, value : v}
+    switch v[0] {
+        case ':':
+            name := v[1:]
+            if pos := strings.Index(name, "<"); pos != -1 && strings.HasSuffix(name, ">") {
+                rule := routerConstraintRule(name[pos + 1 : len(name) - 1])
+                name  = name[0 : pos]
+                segment.kind        = gROUTER_SEGMENT_REGEX
+                segment.constraints = 1
+                if name != "" {
+                    segment.rule  = "^(" + rule + ")$"
+                    segment.names = []string{name}
+                } else {
+                    // 匿名参数不需要捕获匹配值
+                    segment.rule  = "^(?:" + rule + ")$"
+                }
+            } else {
+                segment.kind  = gROUTER_SEGMENT_PARAM
+                segment.names = []string{name}
+            }
+            segment.params = 1
+            return segment
+
+        case '*':
+            segment.kind  = gROUTER_SEGMENT_ANY
+            segment.names = []string{v[1:]}
+            segment.anys  = 1
+            return segment
+    }
+    rule := ""
+    for i := 0; i < len(v); {
+        switch v[i] {
+            case '{':
+                if name, constraint, end := parseRouterField(v, i); end != -1 {
+                    rule          += "(" + routerConstraintRule(constraint) + ")"
+                    segment.names  = append(segment.names, name)
+                    segment.fields++
+                    if constraint != "" {
+                        segment.constraints++
+                    }
+                    i = end + 1
+                    continue
+                }
+                rule += `\{`
+            case '*':
+                rule += `.*`
+                segment.anys++
+            default:
+                rule += regexp.QuoteMeta(v[i : i + 1])
+        }
+        i++
+    }
+    if segment.fields > 0 || segment.anys > 0 {
+        segment.kind = gROUTER_SEGMENT_REGEX
+        segment.rule = "^" + rule + "$"
+    }
+    return segment
+}
+
+// 解析路由段中从位置i开始的{field}或者{field:rule}参数，返回参数名称、约束规则及结束位置，不是合法参数时结束位置为-1
+func parseRouterField(v string, i int) (name, constraint string, end int) {
+    // 查找对应的"}"，约束规则中可能包含{}，例如：{year:\d{4}}
+    depth := 0
+    end    = -1
+    for j := i; j < len(v); j++ {
+        if v[j] == '{' {
+            depth++
+        } else if v[j] == '}' {
+            if depth--; depth == 0 {
+                end = j
+                break
+            }
+        }
+    }
+    if end == -1 {
+        return "", "", -1
+    }
+    name = v[i + 1 : end]
+    if pos := strings.Index(name, ":"); pos != -1 {
+        name, constraint = name[0 : pos], name[pos + 1:]
+    }
+    if !isRouterParamValue(name) {
+        return "", "", -1
+    }
+    return
+}
+
+// 获取路由参数约束规则对应的正则表达式，为空时使用默认规则；
+// 约束规则中的分组会被转换为非捕获分组，以保证路由参数与匹配结果一一对应。
+func routerConstraintRule(constraint string) string {
+    if constraint == "" {
+        return `[\w\.\-]+`
+    }
+    if rule, ok := routerParamTypes[constraint]; ok {
+        return rule
+    }
+    rule    := make([]byte, 0, len(constraint))
+    escaped := false
+    class   := false
+    for i := 0; i < len(constraint); i++ {
+        c := constraint[i]
+        rule = append(rule, c)
+        switch {
+            case escaped:
+                escaped = false
+            case c == '\\':
+                escaped = true
+            case c == '[':
+                class   = true
+            case c == ']':
+                class   = false
+            case c == '(' && !class && (i + 1 >= len(constraint) || constraint[i + 1] != '?'):
+                rule    = append(rule, '?', ':')
+        }
+    }
+    return string(rule)
+}
+
+// 统计路由规则中各类模糊匹配参数的数量，用于路由优先级比较
+func routerFuzzyCounts(uri string) (fields, params, anys, constraints int) {
+    for _, v := range splitRouterPath(uri) {
+        segment      := parseRouterSegment(v)
+        fields      += segment.fields
+        params      += segment.params
+        anys        += segment.anys
+        constraints += segment.constraints
+    }
+    return
+}
@@ -0,0 +1,241 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 路由前缀树.
+
+package ghttp
+
+import (
+    "fmt"
+    "regexp"
+    "strings"
+    "container/list"
+)
+
+// 路由前缀树，按照路由段(以"/"分隔)逐级检索，每个域名(及每个事件)对应一棵树。
+// 检索时找出所有匹配的路由项，再按照路由项的优先级排名(rank)选择，优先级规则见compareRouterPriority。
+type routerTree struct {
+    root  *routerNode // 根节点
+    items *list.List  // 所有注册的路由项，按照优先级从高到低排序
+}
+
+// 路由前缀树节点
+type routerNode struct {
+    static map[string]*routerNode // 静态路由段子节点
+    param  *routerNode            // 命名匹配子节点(:name)，匹配单个路由段
+    fields []*routerNode          // 包含{field}或者*通配符的路由段子节点，使用正则匹配单个路由段
+    any    *routerNode            // 模糊匹配子节点(*any)，匹配零个或者多个路由段
+    regex  *regexp.Regexp         // 路由段正则对象(fields子节点有效)
+    items  []*handlerItem         // 在该节点结束的路由项
+}
+
+func newRouterTree() *routerTree {
+    return &routerTree {
+        root  : newRouterNode(),
+        items : list.New(),
+    }
+}
+
+func newRouterNode() *routerNode {
+    return &routerNode {
+        static : make(map[string]*routerNode),
+    }
+}
+
+// 将URI拆分为路由段，"/"对应空数组
+func splitRouterPath(path string) []string {
+    array := make([]string, 0)
+    for _, v := range strings.Split(strings.Trim(path, "/"), "/") {
+        if v != "" {
+            array = append(array, v)
+        }
+    }
+    return array
+}
+
+// 添加路由项到前缀树中，参数replace为true时替换已存在的相同路由项(相同的method及uri)，
+// 并按照优先级重新计算所有路由项的排名
+func (t *routerTree) add(handler *handlerItem, replace bool) error {
+    segments := make([]routerSegment, 0)
+    for _, v := range splitRouterPath(handler.router.Uri) {
+        segment := parseRouterSegment(v)
+        // 路由约束规则由开发者指定，需要预先检查正则合法性
+        if segment.kind == gROUTER_SEGMENT_REGEX {
+            if _, err := regexp.Compile(segment.rule); err != nil {
+                return fmt.Errorf(`invalid route segment "%s": %s`, v, err.Error())
+            }
+        }
+        segments = append(segments, segment)
+    }
+    node  := t.root
+    names := make([]string, 0)
+    for _, segment := range segments {
+        switch segment.kind {
+            case gROUTER_SEGMENT_PARAM:
+                if node.param == nil {
+                    node.param = newRouterNode()
+                }
+                node = node.param
+
+            case gROUTER_SEGMENT_ANY:
+                if node.any == nil {
+                    node.any = newRouterNode()
+                }
+                node = node.any
+
+            case gROUTER_SEGMENT_REGEX:
+                child := (*routerNode)(nil)
+                for _, n := range node.fields {
+                    if n.regex.String() == segment.rule {
+                        child = n
+                        break
+                    }
+                }
+                if child == nil {
+                    child       = newRouterNode()
+                    child.regex = regexp.MustCompile(segment.rule)
+                    node.fields = append(node.fields, child)
+                }
+                node = child
+
+            default:
+                if _, ok := node.static[segment.value]; !ok {
+                    node.static[segment.value] = newRouterNode()
+                }
+                node = node.static[segment.value]
+        }
+        names = append(names, segment.names...)
+    }
+    handler.names = names
+    // 判断是否已存在相同的路由注册项，是则进行替换
+    replaced := false
+    if replace {
+        for i, item := range node.items {
+            if strings.EqualFold(handler.router.Method, item.router.Method) && handler.router.Uri == item.router.Uri {
+                node.items[i] = handler
+                replaced      = true
+                for e := t.items.Front(); e != nil; e = e.Next() {
+                    if e.Value.(*handlerItem) == item {
+                        t.items.Remove(e)
+                        break
+                    }
+                }
+                break
+            }
+        }
+    }
+    if !replaced {
+        node.items = append(node.items, handler)
+    }
+    // 按照优先级插入到路由项列表中(优先级高的放在前面)
+    pushed := false
+    for e := t.items.Front(); e != nil; e = e.Next() {
+        if compareRouterPriority(handler.router, e.Value.(*handlerItem).router) {
+            t.items.InsertBefore(handler, e)
+            pushed = true
+            break
+        }
+    }
+    if !pushed {
+        t.items.PushBack(handler)
+    }
+    rank := 0
+    for e := t.items.Front(); e != nil; e = e.Next() {
+        e.Value.(*handlerItem).rank = rank
+        rank++
+    }
+    return nil
+}
+
+// 检索路由，返回所有匹配的路由项(按照优先级从高到低排序)；参数first为true时只返回优先级最高的一项
+func (t *routerTree) search(method, path string, first bool) []*handlerParsedItem {
+    parsedItems := make([]*handlerParsedItem, 0)
+    t.root.match(splitRouterPath(path), 0, nil, func(item *handlerItem, values []string) {
+        if !strings.EqualFold(item.router.Method, gDEFAULT_METHOD) && !strings.EqualFold(item.router.Method, method) {
+            return
+        }
+        if first && len(parsedItems) > 0 {
+            if parsedItems[0].handler.rank < item.rank {
+                return
+            }
+            parsedItems = parsedItems[:0]
+        }
+        // 同一路由项可能通过多个模糊匹配分支匹配，只保留一次
+        for _, v := range parsedItems {
+            if v.handler == item {
+                return
+            }
+        }
+        parsedItem := &handlerParsedItem{item, nil}
+        for i, name := range item.names {
+            if name == "" || i >= len(values) {
+                continue
+            }
+            if parsedItem.values == nil {
+                parsedItem.values = make(map[string][]string)
+            }
+            // 如果存在存在同名路由参数名称，那么执行数组追加
+            parsedItem.values[name] = append(parsedItem.values[name], values[i])
+        }
+        parsedItems = append(parsedItems, parsedItem)
+    })
+    if !first && len(parsedItems) > 1 {
+        // 插入排序，匹配的路由项数量一般很少
+        for i := 1; i < len(parsedItems); i++ {
+            for j := i; j > 0 && parsedItems[j].handler.rank < parsedItems[j - 1].handler.rank; j-- {
+                parsedItems[j], parsedItems[j - 1] = parsedItems[j - 1], parsedItems[j]
+            }
+        }
+    }
+    return parsedItems
+}
+
+// 从当前节点开始匹配路由段array[index:]，匹配成功的路由项及路由参数值通过回调函数f返回
+func (n *routerNode) match(array []string, index int, values []string, f func(item *handlerItem, values []string)) {
+    if index == len(array) {
+        for _, item := range n.items {
+            f(item, values)
+        }
+    } else {
+        segment := array[index]
+        if child, ok := n.static[segment]; ok {
+            child.match(array, index + 1, values, f)
+        }
+        if n.param != nil && isRouterParamValue(segment) {
+            n.param.match(array, index + 1, appendRouterValues(values, segment), f)
+        }
+        for _, child := range n.fields {
+            if match := child.regex.FindStringSubmatch(segment); match != nil {
+                child.match(array, index + 1, appendRouterValues(values, match[1:]...), f)
+            }
+        }
+    }
+    // 模糊匹配可以匹配零个或者多个路由段，例如：/user/*action 可以匹配 /user 以及 /user/list/1
+    if n.any != nil {
+        for i := index; i <= len(array); i++ {
+            n.any.match(array, i, appendRouterValues(values, strings.Join(array[index : i], "/")), f)
+        }
+    }
+}
+
+// 复制并追加路由参数值，防止不同匹配分支之间共享底层数组
+func appendRouterValues(values []string, v...string) []string {
+    array := make([]string, len(values), len(values) + len(v))
+    copy(array, values)
+    return append(array, v...)
+}
+
+// 判断路由段是否满足命名匹配(:name)规则，即[\w\.\-]+
+func isRouterParamValue(segment string) bool {
+    if segment == "" {
+        return false
+    }
+    for _, c := range segment {
+        if !(c == '_' || c == '.' || c == '-' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')) {
+            return false
+        }
+    }
+    return true
+}
@@ -0,0 +1,223 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+// go test *.go -bench="Router"
+
+package ghttp
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+)
+
+// 路由检索测试项
+type routerSearchCase struct {
+    method string              // 请求Method
+    path   string              // 请求路径
+    route  string              // 期望匹配的路由规则(method:uri)，为空表示不匹配
+    values map[string]string   // 期望的路由参数
+}
+
+// 创建注册了测试路由的Server，处理方法为空方法
+func newRouterTestServer(name string, patterns...string) *Server {
+    s := GetServer(name)
+    for _, pattern := range patterns {
+        if err := s.BindHandler(pattern, func(r *Request) {}); err != nil {
+            panic(err)
+        }
+    }
+    return s
+}
+
+// 检查路由检索结果
+func checkRouterSearch(t *testing.T, s *Server, cases []routerSearchCase) {
+    for _, c := range cases {
+        item  := s.searchServeHandler(c.method, c.path, []string{gDEFAULT_DOMAIN})
+        route := ""
+        if item != nil {
+            route = item.handler.router.Method + ":" + item.handler.router.Uri
+        }
+        if route != c.route {
+            t.Errorf("%s %s: expect route %q, got %q", c.method, c.path, c.route, route)
+            continue
+        }
+        for k, v := range c.values {
+            if item.values[k] == nil || item.values[k][0] != v {
+                t.Errorf("%s %s: expect value %s=%q, got %v", c.method, c.path, k, v, item.values[k])
+            }
+        }
+    }
+}
+
+func Test_Router_Static(t *testing.T) {
+    s := newRouterTestServer("router-static", "/", "/user", "/user/list", "/user/list/all")
+    checkRouterSearch(t, s, []routerSearchCase {
+        {"GET", "/",               "ALL:/",              nil},
+        {"GET", "/user",           "ALL:/user",          nil},
+        {"GET", "/user/",          "ALL:/user",          nil},
+        {"GET", "/user/list",      "ALL:/user/list",     nil},
+        {"GET", "/user/list/all",  "ALL:/user/list/all", nil},
+        {"GET", "/user/list/none", "",                   nil},
+        {"GET", "/none",           "",                   nil},
+    })
+}
+
+func Test_Router_Param(t *testing.T) {
+    s := newRouterTestServer("router-param", "/user/list", "/user/:id", "/user/:id/:action", "/:name/info")
+    checkRouterSearch(t, s, []routerSearchCase {
+        // 精准匹配优先于命名匹配
+        {"GET", "/user/list",      "ALL:/user/list",         nil},
+        {"GET", "/user/100",       "ALL:/user/:id",          map[string]string{"id" : "100"}},
+        {"GET", "/user/john.g",    "ALL:/user/:id",          map[string]string{"id" : "john.g"}},
+        {"GET", "/user/100/edit",  "ALL:/user/:id/:action",  map[string]string{"id" : "100", "action" : "edit"}},
+        {"GET", "/john/info",      "ALL:/:name/info",        map[string]string{"name" : "john"}},
+        {"GET", "/user/100/edit/1", "",                      nil},
+    })
+}
+
+func Test_Router_Field(t *testing.T) {
+    s := newRouterTestServer("router-field", "/page/{page}.html", "/page/:page", "/list/{type}-{page}.html")
+    checkRouterSearch(t, s, []routerSearchCase {
+        // {field}规则优先于:name规则
+        {"GET", "/page/1.html",      "ALL:/page/{page}.html",        map[string]string{"page" : "1"}},
+        {"GET", "/page/1",           "ALL:/page/:page",              map[string]string{"page" : "1"}},
+        {"GET", "/list/news-2.html", "ALL:/list/{type}-{page}.html", map[string]string{"type" : "news", "page" : "2"}},
+        {"GET", "/list/news.htm",    "",                             nil},
+    })
+}
+
+func Test_Router_Any(t *testing.T) {
+    s := newRouterTestServer("router-any", "/static/*path", "/static/css/main.css", "/src/*path/edit")
+    checkRouterSearch(t, s, []routerSearchCase {
+        {"GET", "/static",              "ALL:/static/*path",         map[string]string{"path" : ""}},
+        {"GET", "/static/js/app.js",    "ALL:/static/*path",         map[string]string{"path" : "js/app.js"}},
+        {"GET", "/static/css/main.css", "ALL:/static/css/main.css",  nil},
+        {"GET", "/src/a/b/edit",        "ALL:/src/*path/edit",       map[string]string{"path" : "a/b"}},
+        {"GET", "/src/edit",            "ALL:/src/*path/edit",       map[string]string{"path" : ""}},
+        {"GET", "/src/a/b",             "",                          nil},
+    })
+}
+
+func Test_Router_Constraint(t *testing.T) {
+    s := newRouterTestServer("router-constraint",
+        `/user/{id:\d+}`, "/user/{name}", "/post/:id<int>", "/post/:slug", "/file/{id:uuid}",
+    )
+    checkRouterSearch(t, s, []routerSearchCase {
+        // 带有约束规则的参数优先
+        {"GET", "/user/100",  `ALL:/user/{id:\d+}`, map[string]string{"id" : "100"}},
+        {"GET", "/user/john", "ALL:/user/{name}",   map[string]string{"name" : "john"}},
+        {"GET", "/post/-1",   "ALL:/post/:id<int>", map[string]string{"id" : "-1"}},
+        {"GET", "/post/news", "ALL:/post/:slug",    map[string]string{"slug" : "news"}},
+        {"GET", "/file/123e4567-e89b-12d3-a456-426614174000", "ALL:/file/{id:uuid}", nil},
+        {"GET", "/file/123",  "",                   nil},
+    })
+    // 非法的约束规则注册失败
+    if err := GetServer("router-constraint-invalid").BindHandler(`/user/{id:[}`, func(r *Request) {}); err == nil {
+        t.Error("invalid constraint rule registered")
+    }
+}
+
+func Test_Router_Method(t *testing.T) {
+    s := newRouterTestServer("router-method", "GET:/item", "/item", "PUT:/only", "POST:/user/:id")
+    checkRouterSearch(t, s, []routerSearchCase {
+        // 指定Method的路由优先，其他Method使用ALL路由
+        {"GET",    "/item",   "GET:/item",      nil},
+        {"POST",   "/item",   "ALL:/item",      nil},
+        {"DELETE", "/item",   "ALL:/item",      nil},
+        {"PUT",    "/only",   "PUT:/only",      nil},
+        {"GET",    "/only",   "",               nil},
+        {"POST",   "/user/1", "POST:/user/:id", map[string]string{"id" : "1"}},
+        {"GET",    "/user/1", "",               nil},
+    })
+}
+
+func Test_Router_Hook(t *testing.T) {
+    s := GetServer("router-hook")
+    for _, pattern := range []string{"/*any", "/user/*any", "/user/:id", "/admin/*any"} {
+        if err := s.BindHookHandler(pattern, HOOK_BEFORE_SERVE, func(r *Request) {}); err != nil {
+            t.Fatal(err)
+        }
+    }
+    // 返回所有匹配的事件回调，按照优先级排序
+    items  := s.searchHookHandler("GET", "/user/1", []string{gDEFAULT_DOMAIN}, HOOK_BEFORE_SERVE)
+    routes := make([]string, 0)
+    for _, item := range items {
+        routes = append(routes, item.handler.router.Uri)
+    }
+    if fmt.Sprint(routes) != "[/user/:id /user/*any /*any]" {
+        t.Errorf("unexpected hook routes: %v", routes)
+    }
+    // 相同pattern的事件回调重复注册失败
+    if err := s.BindHookHandler("/user/:id", HOOK_BEFORE_SERVE, func(r *Request) {}); err == nil {
+        t.Error("duplicated hook registered")
+    }
+}
+
+func Test_Router_RuleDump(t *testing.T) {
+    s := GetServer("router-rule-dump")
+    if err := s.SetUploadConfig("/upload/*any", UploadConfig{MaxFileSize: 1024}); err != nil {
+        t.Fatal(err)
+    }
+    if err := s.BindIpFilter("/admin/*any", nil, []string{"10.0.0.1"}); err != nil {
+        t.Fatal(err)
+    }
+    // 路由规则按照执行顺序展示，并且包含注册位置
+    rules := make([]string, 0)
+    for _, v := range s.GetRuleRoutes() {
+        if v.Type != "rule" || v.Source == "" {
+            t.Errorf("unexpected rule info: %+v", v)
+        }
+        rules = append(rules, v.Rule + ":" + v.Route)
+    }
+    if fmt.Sprint(rules) != "[IpFilter:/admin/*any Upload:/upload/*any]" {
+        t.Errorf("unexpected rules: %v", rules)
+    }
+    if dump := s.formatRoutes(); !strings.Contains(dump, "Rule:Upload") || !strings.Contains(dump, "/admin/*any") {
+        t.Errorf("rules missing in dump:\n%s", dump)
+    }
+}
+
+// 基准测试使用的路由，模拟常见的REST接口
+var benchmarkRoutes = []string {
+    "/", "/user", "/user/list", "/user/:id", "/user/:id/edit", "GET:/user/:id/posts", `/post/{id:\d+}`,
+    "/post/:slug", "/page/{page}.html", "/article/:id<int>/comments", "/static/*path", "/api/v1/order/:id",
+    "/api/v1/order/list", "/api/v1/goods/{id}/stock", "/api/v2/*any",
+}
+
+func benchmarkRouterSearch(b *testing.B, method, path string) {
+    s := GetServer("router-benchmark")
+    if len(s.serveTree) == 0 {
+        for _, pattern := range benchmarkRoutes {
+            s.BindHandler(pattern, func(r *Request) {})
+        }
+    }
+    domains := []string{gDEFAULT_DOMAIN}
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        s.searchServeHandler(method, path, domains)
+    }
+}
+
+func Benchmark_Router_Static(b *testing.B) {
+    benchmarkRouterSearch(b, "GET", "/api/v1/order/list")
+}
+
+func Benchmark_Router_Param(b *testing.B) {
+    benchmarkRouterSearch(b, "GET", "/user/100/posts")
+}
+
+func Benchmark_Router_Constraint(b *testing.B) {
+    benchmarkRouterSearch(b, "GET", "/post/100")
+}
+
+func Benchmark_Router_Any(b *testing.B) {
+    benchmarkRouterSearch(b, "GET", "/static/js/lib/app.js")
+}
+
+func Benchmark_Router_NotFound(b *testing.B) {
+    benchmarkRouterSearch(b, "GET", "/none/path/to/somewhere")
+}
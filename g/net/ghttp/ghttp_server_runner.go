@@ -0,0 +1,150 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 开发环境热编译运行工具(grun)的进程通信支持.
+
+package ghttp
+
+import (
+    "bytes"
+    "strings"
+    "net/http"
+    "html/template"
+    "path/filepath"
+    "crypto/subtle"
+    "gitee.com/johng/gf/g/os/genv"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/os/gproc"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+// 以下常量需要与grun包保持一致；
+// 进程消息中的来源PID只有低16位并且由发送方自行填写，因此grun与服务进程之间使用启动时随机生成的令牌进行身份校验，
+// 消息格式：动作:令牌:内容
+const (
+    gRUNNER_PID_ENVKEY     = "GF_RUNNER_PID"    // 由grun启动时，环境变量中保存的grun进程ID
+    gRUNNER_TOKEN_ENVKEY   = "GF_RUNNER_TOKEN"  // 由grun启动时，环境变量中保存的通信令牌
+    gRUNNER_OUTPUT_ENVKEY  = "GF_RUNNER_OUTPUT" // 由grun启动时，环境变量中保存的可执行文件输出目录
+    gRUNNER_GPROC_GROUP    = "GF_GPROC_RUNNER"  // grun进程接收消息的分组名称
+    gRUNNER_MSG_STARTED    = "started:"         // 服务进程启动后通知grun，以便grun获取到平滑重启后最新的服务进程ID，格式：started:令牌:服务进程ID
+    gRUNNER_ACTION_RESTART = "restart:"         // grun编译成功后通知服务进程使用新的可执行文件平滑重启，格式：restart:令牌:可执行文件路径
+    gRUNNER_ACTION_ERROR   = "error:"           // grun编译失败后通知服务进程展示编译错误，格式：error:令牌:编译错误信息
+)
+
+// (进程级别)grun热编译失败时的编译错误信息，不为空时所有请求输出编译错误页面
+var runnerBuildError = gtype.NewString()
+
+// 如果当前进程由grun启动，那么通知grun当前的服务进程ID
+func notifyRunnerStarted() {
+    pid   := gconv.Int(genv.Get(gRUNNER_PID_ENVKEY))
+    token := genv.Get(gRUNNER_TOKEN_ENVKEY)
+    if pid > 0 && token != "" {
+        go func() {
+            data := gRUNNER_MSG_STARTED + token + ":" + gconv.String(gproc.Pid())
+            if err := gproc.Send(pid, []byte(data), gRUNNER_GPROC_GROUP); err != nil {
+                glog.Errorfln("%d: notify runner failed: %s", gproc.Pid(), err.Error())
+            }
+        }()
+    }
+}
+
+// 处理grun发送的进程消息，只有当前进程由grun启动并且消息中的令牌正确时才处理，否则返回false
+func handleRunnerMessage(data []byte) bool {
+    token := genv.Get(gRUNNER_TOKEN_ENVKEY)
+    if token == "" {
+        return false
+    }
+    if path, ok := parseRunnerMessage(data, gRUNNER_ACTION_RESTART, token); ok {
+        // 只允许使用grun输出目录中的可执行文件重启
+        if !isRunnerBinary(path) {
+            glog.Errorfln("%d: invalid runner binary: %s", gproc.Pid(), path)
+            return true
+        }
+        serverActionLocker.Lock()
+        // 编译产生的重启不需要检查操作频率，但正在重启/关闭中的服务进程不再重复执行
+        if serverProcessStatus.Val() == gADMIN_ACTION_NONE {
+            restartWebServers(false, path)
+        }
+        serverActionLocker.Unlock()
+        return true
+    }
+    if message, ok := parseRunnerMessage(data, gRUNNER_ACTION_ERROR, token); ok {
+        runnerBuildError.Set(message)
+        glog.Printfln("%d: build failed, serving build error page", gproc.Pid())
+        return true
+    }
+    return false
+}
+
+// 解析"动作:令牌:内容"格式的消息，动作或者令牌不匹配时返回false
+func parseRunnerMessage(data []byte, action, token string) (string, bool) {
+    if !bytes.HasPrefix(data, []byte(action)) {
+        return "", false
+    }
+    data = data[len(action):]
+    pos := bytes.IndexByte(data, ':')
+    if pos < 0 || subtle.ConstantTimeCompare(data[:pos], []byte(token)) != 1 {
+        return "", false
+    }
+    return string(data[pos + 1:]), true
+}
+
+// 判断可执行文件是否位于grun的输出目录中
+func isRunnerBinary(path string) bool {
+    output := genv.Get(gRUNNER_OUTPUT_ENVKEY)
+    if output == "" || path == "" {
+        return false
+    }
+    output, err := filepath.Abs(output)
+    if err != nil {
+        return false
+    }
+    path, err = filepath.Abs(path)
+    if err != nil {
+        return false
+    }
+    return strings.HasPrefix(path, output + string(filepath.Separator))
+}
+
+// 输出编译错误页面，客户端请求JSON时输出JSON格式的错误信息
+func (s *Server) serveBuildError(r *Request) {
+    message := runnerBuildError.Val()
+    r.Response.Header().Set("X-Content-Type-Options", "nosniff")
+    r.Response.Header().Set("Cache-Control",          "no-cache, no-store, must-revalidate")
+    if isJsonRequest(r) {
+        r.Response.WriteJson(map[string]interface{} {
+            "code"    : http.StatusInternalServerError,
+            "message" : "build failed",
+            "error"   : message,
+        })
+    } else {
+        buffer := bytes.NewBuffer(nil)
+        buildErrorTemplate.Execute(buffer, message)
+        r.Response.Header().Set("Content-Type", "text/html; charset=utf-8")
+        r.Response.Write(buffer.Bytes())
+    }
+    r.Response.WriteHeader(http.StatusInternalServerError)
+}
+
+// 编译错误页面模板，页面每隔2秒自动刷新，编译成功并重启后即展示正常页面
+var buildErrorTemplate = template.Must(template.New("build").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta http-equiv="refresh" content="2">
+    <title>Build Failed</title>
+    <style>
+        body {font-family: Arial, sans-serif; margin: 20px; color: #333;}
+        h1   {color: #c00; font-size: 20px;}
+        pre  {background: #f6f6f6; padding: 10px; overflow: auto; font-size: 13px;}
+    </style>
+</head>
+<body>
+    <h1>Build Failed</h1>
+    <p>The page will refresh automatically after the next successful build.</p>
+    <pre>{{.}}</pre>
+</body>
+</html>`))
@@ -0,0 +1,36 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package ghttp
+
+import (
+    "os"
+    "testing"
+)
+
+func Test_Server_RunnerMessage(t *testing.T) {
+    defer runnerBuildError.Set("")
+    // 非grun启动的进程不处理任何消息
+    os.Unsetenv(gRUNNER_TOKEN_ENVKEY)
+    if handleRunnerMessage([]byte(gRUNNER_ACTION_ERROR + ":failed")) {
+        t.Error("message handled without runner token")
+    }
+    os.Setenv(gRUNNER_TOKEN_ENVKEY, "0123456789abcdef")
+    defer os.Unsetenv(gRUNNER_TOKEN_ENVKEY)
+    // 令牌错误的消息不处理
+    if handleRunnerMessage([]byte(gRUNNER_ACTION_ERROR + "fedcba9876543210:failed")) {
+        t.Error("message handled with invalid token")
+    }
+    if runnerBuildError.Val() != "" {
+        t.Error("build error set by invalid message")
+    }
+    if !handleRunnerMessage([]byte(gRUNNER_ACTION_ERROR + "0123456789abcdef:build: failed")) {
+        t.Error("valid message not handled")
+    }
+    if runnerBuildError.Val() != "build: failed" {
+        t.Error("unexpected build error:", runnerBuildError.Val())
+    }
+}
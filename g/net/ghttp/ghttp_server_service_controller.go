@@ -34,7 +34,8 @@ func (s *Server)BindController(pattern string, c Controller, methods...string) e
         if methodMap != nil && !methodMap[mname] {
             continue
         }
-        if mname == "Init" || mname == "Shut" || mname == "Exit"  {
+        // ApiDocs为ApiDocProvider接口方法，用于提供接口文档信息，不作为路由注册
+        if mname == "Init" || mname == "Shut" || mname == "Exit" || mname == "ApiDocs" {
             continue
         }
         key   := s.mergeBuildInNameToPattern(pattern, sname, mname, true)
@@ -40,14 +40,15 @@ func (s *Server)BindObject(pattern string, obj interface{}, methods...string) er
         if methodMap != nil && !methodMap[mname] {
             continue
         }
-        if mname == "Init" || mname == "Shut" {
+        // ApiDocs为ApiDocProvider接口方法，用于提供接口文档信息，不作为路由注册
+        if mname == "Init" || mname == "Shut" || mname == "ApiDocs" {
             continue
         }
         key    := s.mergeBuildInNameToPattern(pattern, sname, mname, true)
         m[key]  = &handlerItem {
             rtype : gROUTE_REGISTER_OBJECT,
-            ctype : nil,
-            fname : "",
+            ctype : t.Elem(),
+            fname : mname,
             faddr : v.Method(i).Interface().(func(*Request)),
             finit : finit,
             fshut : fshut,
@@ -63,8 +64,8 @@ func (s *Server)BindObject(pattern string, obj interface{}, methods...string) er
             }
             m[p] = &handlerItem {
                 rtype : gROUTE_REGISTER_OBJECT,
-                ctype : nil,
-                fname : "",
+                ctype : t.Elem(),
+                fname : mname,
                 faddr : v.Method(i).Interface().(func(*Request)),
                 finit : finit,
                 fshut : fshut,
@@ -97,8 +98,8 @@ func (s *Server)BindObjectMethod(pattern string, obj interface{}, method string)
     key   := s.mergeBuildInNameToPattern(pattern, sname, mname, false)
     m[key] = &handlerItem{
         rtype : gROUTE_REGISTER_OBJECT,
-        ctype : nil,
-        fname : "",
+        ctype : t.Elem(),
+        fname : mname,
         faddr : fval.Interface().(func(*Request)),
         finit : finit,
         fshut : fshut,
@@ -130,8 +131,8 @@ func (s *Server)BindObjectRest(pattern string, obj interface{}) error {
         key   := name + ":" + pattern
         m[key] = &handlerItem {
             rtype : gROUTE_REGISTER_OBJECT,
-            ctype : nil,
-            fname : "",
+            ctype : t.Elem(),
+            fname : name,
             faddr : v.Method(i).Interface().(func(*Request)),
             finit : finit,
             fshut : fshut,
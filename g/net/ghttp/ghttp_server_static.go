@@ -0,0 +1,208 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// 静态文件服务.
+
+package ghttp
+
+import (
+    "os"
+    "io"
+    "sort"
+    "mime"
+    "errors"
+    "strings"
+    "net/http"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/os/gfile"
+    "gitee.com/johng/gf/g/os/gfsnotify"
+    "gitee.com/johng/gf/g/crypto/gmd5"
+)
+
+// 静态文件目录映射项
+type staticPathItem struct {
+    prefix string // 映射的URI前缀，例如：/assets
+    path   string // 映射的本地目录绝对路径
+    spa    bool   // 是否开启SPA回退，当文件不存在时返回该目录下的index.html
+}
+
+// 添加静态文件目录映射，将URI前缀prefix映射到本地目录path，例如：s.AddStaticPath("/assets", "/var/www/assets")。
+// 参数spa为true时，在该前缀下找不到文件(且没有匹配的服务路由)时将会返回目录下的index.html，用于单页应用(SPA)。
+// 映射目录的检索优先级高于SetServerRoot/AddSearchPath设置的目录，前缀越长优先级越高。
+func (s *Server) AddStaticPath(prefix string, path string, spa...bool) error {
+    if s.isServing() {
+        return errors.New("cannot add static path while server running")
+    }
+    realPath := gfile.RealPath(path)
+    if realPath == "" || !gfile.IsDir(realPath) {
+        return errors.New("invalid static path: " + path)
+    }
+    if prefix != "/" {
+        prefix = "/" + strings.Trim(prefix, "/")
+    }
+    item := staticPathItem {
+        prefix : prefix,
+        path   : strings.TrimRight(realPath, gfile.Separator),
+        spa    : len(spa) > 0 && spa[0],
+    }
+    // 相同的前缀进行覆盖
+    for k, v := range s.staticPaths {
+        if v.prefix == prefix {
+            s.staticPaths[k] = item
+            return nil
+        }
+    }
+    s.staticPaths = append(s.staticPaths, item)
+    // 按照前缀长度排序，前缀越长越优先匹配
+    sort.SliceStable(s.staticPaths, func(i, j int) bool {
+        return len(s.staticPaths[i].prefix) > len(s.staticPaths[j].prefix)
+    })
+    return nil
+}
+
+// 设置指定文件扩展名(例如：.js)返回的Cache-Control头信息，扩展名为"*"时表示所有静态文件的默认值
+func (s *Server) SetCacheControl(ext string, value string) {
+    if s.isServing() {
+        glog.Error("cannot be changed while running")
+        return
+    }
+    if s.config.CacheControl == nil {
+        s.config.CacheControl = make(map[string]string)
+    }
+    s.config.CacheControl[strings.ToLower(ext)] = value
+}
+
+// 根据请求的URI检索静态文件，返回文件的绝对路径。
+// 返回值spa为true时表示该文件是SPA回退文件，此时服务路由的优先级高于该文件。
+func (s *Server) searchStaticFile(uri string) (filePath string, spa bool) {
+    // 优先检索目录映射
+    for _, item := range s.staticPaths {
+        if item.prefix != "/" && uri != item.prefix && !strings.HasPrefix(uri, item.prefix + "/") {
+            continue
+        }
+        relPath := strings.TrimPrefix(uri, item.prefix)
+        if item.prefix == "/" {
+            relPath = uri
+        }
+        path := gfile.RealPath(item.path + gfile.Separator + strings.TrimLeft(relPath, "/"))
+        // 防止通过"../"访问映射目录以外的文件
+        if path != "" && (path == item.path || strings.HasPrefix(path, item.path + gfile.Separator)) {
+            if gfile.IsDir(path) {
+                if index := s.searchIndexFile(path); index != "" {
+                    return index, false
+                }
+            } else {
+                return path, false
+            }
+        }
+        if item.spa {
+            if index := s.searchIndexFile(item.path); index != "" {
+                return index, true
+            }
+        }
+        break
+    }
+    // 其次检索SetServerRoot/AddSearchPath设置的目录
+    path := s.paths.Search(uri)
+    if path != "" && gfile.IsDir(path) {
+        // 如果是目录需要处理index files，找不到时返回目录本身，由IndexFolder配置决定是否展示目录列表
+        if index := s.searchIndexFile(path); index != "" {
+            return index, false
+        }
+    }
+    return path, false
+}
+
+// 检索目录下的默认访问文件(IndexFiles)
+func (s *Server) searchIndexFile(dir string) string {
+    for _, file := range s.config.IndexFiles {
+        path := dir + gfile.Separator + file
+        if gfile.Exists(path) && !gfile.IsDir(path) {
+            return path
+        }
+    }
+    return ""
+}
+
+// 输出静态文件内容，处理Cache-Control、ETag以及预压缩(.gz)文件
+func (s *Server) serveStaticFile(r *Request, path string, f *os.File, info os.FileInfo) {
+    header  := r.Response.Header()
+    content := io.ReadSeeker(f)
+    etag    := ""
+    if value := s.getCacheControl(path); value != "" {
+        header.Set("Cache-Control", value)
+    }
+    // 如果客户端支持gzip，并且存在同名的.gz预压缩文件，那么直接返回预压缩文件
+    gzPath := path + ".gz"
+    if gfile.Exists(gzPath) && !gfile.IsDir(gzPath) {
+        header.Add("Vary", "Accept-Encoding")
+        if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+            if gzFile, err := os.Open(gzPath); err == nil {
+                defer gzFile.Close()
+                if gzInfo, err := gzFile.Stat(); err == nil {
+                    content = gzFile
+                    info    = gzInfo
+                    etag    = s.getStaticFileEtag(gzPath)
+                    header.Set("Content-Encoding", "gzip")
+                    // Content-Type按照原始文件类型识别
+                    if contentType := mime.TypeByExtension(gfile.Ext(path)); contentType != "" {
+                        header.Set("Content-Type", contentType)
+                    }
+                }
+            }
+        }
+    }
+    if etag == "" {
+        etag = s.getStaticFileEtag(path)
+    }
+    if etag != "" {
+        header.Set("ETag", etag)
+    }
+    // ServeContent内部会根据ETag/Last-Modified处理If-None-Match/If-Modified-Since等条件请求
+    http.ServeContent(r.Response.Writer, &r.Request, gfile.Basename(path), info.ModTime(), content)
+}
+
+// 获取文件对应的Cache-Control配置
+func (s *Server) getCacheControl(path string) string {
+    if len(s.config.CacheControl) == 0 {
+        return ""
+    }
+    if value, ok := s.config.CacheControl[strings.ToLower(gfile.Ext(path))]; ok {
+        return value
+    }
+    return s.config.CacheControl["*"]
+}
+
+// 获取静态文件的强ETag(文件内容MD5)，结果会被缓存，并在文件变化时通过gfsnotify自动失效
+func (s *Server) getStaticFileEtag(path string) string {
+    if etag := s.staticEtags.Get(path); etag != "" {
+        return etag
+    }
+    md5 := gmd5.EncryptFile(path)
+    if md5 == "" {
+        return ""
+    }
+    etag := `"` + md5 + `"`
+    s.staticEtagMu.Lock()
+    defer s.staticEtagMu.Unlock()
+    if !s.staticEtags.Contains(path) {
+        err := gfsnotify.Add(path, func(event *gfsnotify.Event) {
+            // 文件删除后底层监控也会失效，因此删除缓存项，以便下一次请求重新添加监控
+            s.staticEtagMu.Lock()
+            defer s.staticEtagMu.Unlock()
+            if event.IsRemove() {
+                s.staticEtags.Remove(path)
+            } else {
+                s.staticEtags.Set(path, "")
+            }
+        })
+        // 无法监控文件变化时不缓存，否则文件修改后ETag不会失效
+        if err != nil {
+            return etag
+        }
+    }
+    s.staticEtags.Set(path, etag)
+    return etag
+}
@@ -13,7 +13,12 @@ import (
 
 // 查询状态码回调函数
 func (s *Server)getStatusHandler(status int, r *Request) HandlerFunc {
-    domains := []string{r.GetHost(), gDEFAULT_DOMAIN}
+    // 状态码回调按照请求域名、匹配的域名模式、默认域名的顺序检索
+    domains := make([]string, 0, 2)
+    for _, v := range r.getDomainMatches() {
+        domains = append(domains, v.domain)
+    }
+    domains = append(domains, gDEFAULT_DOMAIN)
     s.hsmu.RLock()
     defer s.hsmu.RUnlock()
     for _, domain := range domains {
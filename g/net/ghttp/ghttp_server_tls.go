@@ -0,0 +1,256 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// HTTPS证书管理(按域名SNI选择证书，证书文件变化时自动重载).
+
+package ghttp
+
+import (
+    "sync"
+    "bytes"
+    "time"
+    "errors"
+    "strings"
+    "io/ioutil"
+    "crypto/tls"
+    "crypto/x509"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/os/gfile"
+    "gitee.com/johng/gf/g/os/gproc"
+    "gitee.com/johng/gf/g/os/gfsnotify"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+const (
+    gTLS_CERT_EXPIRY_WARNING = 30*24*time.Hour // 证书有效期小于该值时输出警告日志
+)
+
+// HTTPS证书管理对象
+type tlsManager struct {
+    mu      sync.RWMutex
+    certs   map[string]*tlsCertItem // 证书项，键名为域名，默认证书的键名为gDEFAULT_DOMAIN
+    watched map[string]bool         // 已经添加监控的证书目录
+}
+
+// 单个证书项
+type tlsCertItem struct {
+    certFile string           // 证书文件绝对路径
+    keyFile  string           // 私钥文件绝对路径
+    cert     *gtype.Interface // 当前使用的证书(*tls.Certificate)，重载时原子替换
+}
+
+func newTlsManager() *tlsManager {
+    return &tlsManager {
+        certs   : make(map[string]*tlsCertItem),
+        watched : make(map[string]bool),
+    }
+}
+
+// 给域名设置HTTPS证书，客户端通过SNI访问该域名时使用此证书，证书文件变化时自动重载，
+// 可以和Server的默认证书(s.EnableHTTPS)一起使用，没有匹配域名证书的请求使用默认证书；
+// 域名模式(例如：*.example.com、api.{tenant}.example.com)使用与域名路由相同的规则匹配SNI域名。
+func (d *Domain) EnableHTTPS(certFile, keyFile string) error {
+    if d.s.isServing() {
+        return errors.New("cannot enable https while server running")
+    }
+    for domain, _ := range d.m {
+        if err := d.s.addCertificate(domain, certFile, keyFile); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// 获取证书的过期时间，不传递域名时返回默认证书的过期时间，证书不存在时返回零值
+func (s *Server) GetCertificateExpiry(domain...string) time.Time {
+    name := gDEFAULT_DOMAIN
+    if len(domain) > 0 && domain[0] != "" {
+        name = strings.ToLower(domain[0])
+    }
+    s.tls.mu.RLock()
+    item, ok := s.tls.certs[name]
+    s.tls.mu.RUnlock()
+    if ok {
+        if cert := item.certificate(); cert != nil && cert.Leaf != nil {
+            return cert.Leaf.NotAfter
+        }
+    }
+    return time.Time{}
+}
+
+// 获取所有证书的过期时间，键名为域名(默认证书为default)
+func (s *Server) GetCertificateExpiries() map[string]time.Time {
+    m := make(map[string]time.Time)
+    s.tls.mu.RLock()
+    defer s.tls.mu.RUnlock()
+    for domain, item := range s.tls.certs {
+        if cert := item.certificate(); cert != nil && cert.Leaf != nil {
+            m[domain] = cert.Leaf.NotAfter
+        }
+    }
+    return m
+}
+
+// 是否开启了HTTPS(设置了默认证书或者域名证书)
+func (s *Server) isHttpsEnabled() bool {
+    if len(s.config.HTTPSCertPath) > 0 && len(s.config.HTTPSKeyPath) > 0 {
+        return true
+    }
+    s.tls.mu.RLock()
+    defer s.tls.mu.RUnlock()
+    return len(s.tls.certs) > 0
+}
+
+// 添加证书，加载失败时返回错误
+func (s *Server) addCertificate(domain, certFile, keyFile string) error {
+    item := &tlsCertItem {
+        certFile : gfile.RealPath(certFile),
+        keyFile  : gfile.RealPath(keyFile),
+        cert     : gtype.NewInterface(),
+    }
+    if item.certFile == "" {
+        return errors.New("certificate file not found: " + certFile)
+    }
+    if item.keyFile == "" {
+        return errors.New("key file not found: " + keyFile)
+    }
+    if err := item.load(domain); err != nil {
+        return err
+    }
+    s.tls.mu.Lock()
+    s.tls.certs[strings.ToLower(domain)] = item
+    s.tls.mu.Unlock()
+    return nil
+}
+
+// 生成HTTPS服务使用的TLS配置
+func (s *Server) newTlsConfig() (*tls.Config, error) {
+    // 默认证书
+    if len(s.config.HTTPSCertPath) > 0 && len(s.config.HTTPSKeyPath) > 0 {
+        s.tls.mu.RLock()
+        _, ok := s.tls.certs[gDEFAULT_DOMAIN]
+        s.tls.mu.RUnlock()
+        if !ok {
+            if err := s.addCertificate(gDEFAULT_DOMAIN, s.config.HTTPSCertPath, s.config.HTTPSKeyPath); err != nil {
+                return nil, err
+            }
+        }
+    }
+    config := &tls.Config {
+        NextProtos     : []string{"http/1.1"},
+        MinVersion     : s.config.TLSMinVersion,
+        CipherSuites   : s.config.TLSCipherSuites,
+        ClientAuth     : s.config.TLSClientAuth,
+        GetCertificate : s.getCertificate,
+    }
+    if s.config.TLSClientCAPath != "" {
+        content, err := ioutil.ReadFile(s.config.TLSClientCAPath)
+        if err != nil {
+            return nil, err
+        }
+        pool := x509.NewCertPool()
+        if !pool.AppendCertsFromPEM(content) {
+            return nil, errors.New("no valid certificate found in client CA file: " + s.config.TLSClientCAPath)
+        }
+        config.ClientCAs = pool
+    }
+    s.watchCertificates()
+    return config, nil
+}
+
+// 根据SNI选择证书：优先完整域名匹配，其次按照优先级匹配域名模式(例如：*.example.com、api.{tenant}.example.com)，
+// 最后使用默认证书；服务运行期间域名模式不再变化，因此可以直接读取
+func (s *Server) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+    name := strings.ToLower(strings.TrimRight(hello.ServerName, "."))
+    s.tls.mu.RLock()
+    item, ok := s.tls.certs[name]
+    if !ok && name != "" {
+        for _, p := range s.domainPatterns {
+            if !p.regex.MatchString(name) {
+                continue
+            }
+            if item, ok = s.tls.certs[strings.ToLower(p.domain)]; ok {
+                break
+            }
+        }
+    }
+    if !ok {
+        item, ok = s.tls.certs[gDEFAULT_DOMAIN]
+    }
+    s.tls.mu.RUnlock()
+    if ok {
+        if cert := item.certificate(); cert != nil {
+            return cert, nil
+        }
+    }
+    return nil, errors.New("no certificate for server name: " + hello.ServerName)
+}
+
+// 监控证书文件所在目录，证书或者私钥文件变化时重新加载；
+// 监控目录而不是文件本身，以便支持通过替换文件(例如：certbot更新证书)的方式更新证书。
+func (s *Server) watchCertificates() {
+    s.tls.mu.Lock()
+    defer s.tls.mu.Unlock()
+    for _, item := range s.tls.certs {
+        for _, path := range []string{item.certFile, item.keyFile} {
+            dir := gfile.Dir(path)
+            if s.tls.watched[dir] {
+                continue
+            }
+            if err := gfsnotify.Add(dir, s.onCertificateChange); err != nil {
+                glog.Error(err)
+                continue
+            }
+            s.tls.watched[dir] = true
+        }
+    }
+}
+
+// 证书文件变化时的回调处理，重新加载使用该文件的证书，已建立的连接不受影响；
+// 证书和私钥文件不是同时写入，加载失败时保留原有证书，等待下一次文件变化时再重新加载。
+func (s *Server) onCertificateChange(event *gfsnotify.Event) {
+    if event.IsChmod() {
+        return
+    }
+    s.tls.mu.RLock()
+    defer s.tls.mu.RUnlock()
+    for domain, item := range s.tls.certs {
+        if event.Path == item.certFile || event.Path == item.keyFile {
+            if err := item.load(domain); err != nil {
+                glog.Warningfln("%d: https certificate for %s reloading failed, keep using the former one: %v", gproc.Pid(), domain, err)
+            }
+        }
+    }
+}
+
+// 获取当前使用的证书
+func (item *tlsCertItem) certificate() *tls.Certificate {
+    if v := item.cert.Val(); v != nil {
+        return v.(*tls.Certificate)
+    }
+    return nil
+}
+
+// 加载证书文件，并解析证书有效期
+func (item *tlsCertItem) load(domain string) error {
+    cert, err := tls.LoadX509KeyPair(item.certFile, item.keyFile)
+    if err != nil {
+        return err
+    }
+    if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+        return err
+    }
+    // 文件变化时会产生多个事件，证书内容未变化时不需要替换
+    if old := item.certificate(); old != nil && bytes.Equal(old.Certificate[0], cert.Certificate[0]) {
+        return nil
+    }
+    item.cert.Set(&cert)
+    expiry := cert.Leaf.NotAfter
+    glog.Printfln("%d: https certificate for %s loaded, expires at %s", gproc.Pid(), domain, expiry.Format("2006-01-02 15:04:05"))
+    if left := expiry.Sub(time.Now()); left < gTLS_CERT_EXPIRY_WARNING {
+        glog.Warningfln("%d: https certificate for %s expires in %d days", gproc.Pid(), domain, int(left.Hours()/24))
+    }
+    return nil
+}
@@ -0,0 +1,409 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+// WebSocket连接管理(Hub)，包括连接注册、房间管理、广播、写队列及心跳.
+
+package ghttp
+
+import (
+    "net"
+    "sync"
+    "time"
+    "errors"
+    "github.com/gorilla/websocket"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/container/gmap"
+    "gitee.com/johng/gf/g/container/gset"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+// WebSocket消息处理方法
+type WebSocketHandler func(c *WebSocketConn, msgType int, data []byte)
+
+// WebSocket连接事件回调方法(连接建立/断开)
+type WebSocketConnHandler func(c *WebSocketConn)
+
+// WebSocket Hub配置
+type WebSocketHubConfig struct {
+    WriteQueueSize int           // 每个连接的写队列大小
+    WriteTimeout   time.Duration // 写入超时时间，同时也是写队列满时Send方法的最大等待时间
+    PingInterval   time.Duration // 服务端发送ping的间隔
+    PongTimeout    time.Duration // 等待客户端pong(或者任意消息)的超时时间，超时后断开连接
+    MaxMessageSize int64         // 允许客户端发送的最大消息大小(byte)，0表示不限制
+}
+
+// WebSocket Hub，管理Server下所有通过BindWebSocket建立的连接
+type WebSocketHub struct {
+    mu           sync.RWMutex                      // 房间互斥锁
+    config       WebSocketHubConfig                // Hub配置
+    conns        *gmap.IntInterfaceMap             // 所有连接(连接ID => *WebSocketConn)
+    rooms        map[string]map[int]*WebSocketConn // 房间 => 连接列表
+    connId       *gtype.Int                        // 连接ID生成
+    onConnect    *gtype.Interface                  // 连接建立回调
+    onDisconnect *gtype.Interface                  // 连接断开回调
+}
+
+// Hub管理的WebSocket连接对象，底层连接只能由读写循环访问(不支持并发写)，因此不对外暴露，消息发送统一使用Send
+type WebSocketConn struct {
+    ws        *WebSocket                      // 底层WebSocket连接
+    Id        int                             // 连接ID(Hub内唯一)
+    Request   *Request                        // 建立连接的请求对象
+    hub       *WebSocketHub                   // 所属Hub
+    config    WebSocketHubConfig              // 连接建立时的Hub配置
+    rooms     *gset.StringSet                 // 已加入的房间
+    data      *gmap.StringInterfaceMap        // 自定义连接数据
+    queue     chan *websocket.PreparedMessage // 写队列
+    done      chan struct{}                   // 关闭事件
+    closeOnce sync.Once                       // 保证只关闭一次
+}
+
+var (
+    // 连接已关闭
+    ErrWebSocketClosed    = errors.New("websocket connection closed")
+    // 写队列已满(客户端接收过慢)
+    ErrWebSocketQueueFull = errors.New("websocket write queue is full")
+)
+
+// 默认的WebSocket Hub配置
+var defaultWebSocketHubConfig = WebSocketHubConfig {
+    WriteQueueSize : 256,
+    WriteTimeout   : 10 * time.Second,
+    PingInterval   : 30 * time.Second,
+    PongTimeout    : 60 * time.Second,
+    MaxMessageSize : 0,
+}
+
+// 创建一个WebSocket Hub
+func NewWebSocketHub() *WebSocketHub {
+    return &WebSocketHub {
+        config       : defaultWebSocketHubConfig,
+        conns        : gmap.NewIntInterfaceMap(),
+        rooms        : make(map[string]map[int]*WebSocketConn),
+        connId       : gtype.NewInt(),
+        onConnect    : gtype.NewInterface(),
+        onDisconnect : gtype.NewInterface(),
+    }
+}
+
+// 获取Server的WebSocket Hub对象
+func (s *Server) WebSocketHub() *WebSocketHub {
+    return s.wsHub
+}
+
+// 绑定WebSocket服务，客户端连接建立后注册到Server的WebSocket Hub中，每收到一条消息调用一次handler。
+// 连接建立/断开的回调通过WebSocketHub().BindConnectHandler/BindDisconnectHandler设置。
+func (s *Server) BindWebSocket(pattern string, handler WebSocketHandler) error {
+    return s.BindHandler(pattern, func(r *Request) {
+        s.wsHub.serve(r, handler)
+    })
+}
+
+// 设置Hub配置，只对之后建立的连接有效
+func (h *WebSocketHub) SetConfig(config WebSocketHubConfig) {
+    h.mu.Lock()
+    h.config = config
+    h.mu.Unlock()
+}
+
+// 绑定连接建立回调方法
+func (h *WebSocketHub) BindConnectHandler(handler WebSocketConnHandler) {
+    h.onConnect.Set(handler)
+}
+
+// 绑定连接断开回调方法
+func (h *WebSocketHub) BindDisconnectHandler(handler WebSocketConnHandler) {
+    h.onDisconnect.Set(handler)
+}
+
+// 获取指定ID的连接，不存在时返回nil
+func (h *WebSocketHub) Get(id int) *WebSocketConn {
+    if v := h.conns.Get(id); v != nil {
+        return v.(*WebSocketConn)
+    }
+    return nil
+}
+
+// 当前连接数量
+func (h *WebSocketHub) Size() int {
+    return h.conns.Size()
+}
+
+// 获取指定房间的连接数量
+func (h *WebSocketHub) RoomSize(room string) int {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+    return len(h.rooms[room])
+}
+
+// 获取所有房间名称
+func (h *WebSocketHub) Rooms() []string {
+    h.mu.RLock()
+    defer h.mu.RUnlock()
+    rooms := make([]string, 0, len(h.rooms))
+    for k, _ := range h.rooms {
+        rooms = append(rooms, k)
+    }
+    return rooms
+}
+
+// 向所有连接广播消息，返回发送失败(写队列已满被断开)的连接数量
+func (h *WebSocketHub) Broadcast(msgType int, data []byte) (int, error) {
+    conns := make([]*WebSocketConn, 0, h.conns.Size())
+    h.conns.RLockFunc(func(m map[int]interface{}) {
+        for _, v := range m {
+            conns = append(conns, v.(*WebSocketConn))
+        }
+    })
+    return h.broadcast(conns, msgType, data)
+}
+
+// 向指定房间的所有连接广播消息，返回发送失败(写队列已满被断开)的连接数量
+func (h *WebSocketHub) BroadcastRoom(room string, msgType int, data []byte) (int, error) {
+    h.mu.RLock()
+    conns := make([]*WebSocketConn, 0, len(h.rooms[room]))
+    for _, c := range h.rooms[room] {
+        conns = append(conns, c)
+    }
+    h.mu.RUnlock()
+    return h.broadcast(conns, msgType, data)
+}
+
+// 广播消息，消息只编码一次；写队列已满的连接会被认为是慢连接并断开，防止阻塞其他连接
+func (h *WebSocketHub) broadcast(conns []*WebSocketConn, msgType int, data []byte) (int, error) {
+    pm, err := websocket.NewPreparedMessage(msgType, data)
+    if err != nil {
+        return 0, err
+    }
+    failed := 0
+    for _, c := range conns {
+        select {
+            case <- c.done:
+                failed++
+            case c.queue <- pm:
+            default:
+                failed++
+                c.Close()
+        }
+    }
+    return failed, nil
+}
+
+// 连接加入房间
+func (h *WebSocketHub) join(room string, c *WebSocketConn) {
+    h.mu.Lock()
+    if _, ok := h.rooms[room]; !ok {
+        h.rooms[room] = make(map[int]*WebSocketConn)
+    }
+    h.rooms[room][c.Id] = c
+    h.mu.Unlock()
+    c.rooms.Add(room)
+}
+
+// 连接离开房间，房间为空时自动删除
+func (h *WebSocketHub) leave(room string, c *WebSocketConn) {
+    h.mu.Lock()
+    if m, ok := h.rooms[room]; ok {
+        delete(m, c.Id)
+        if len(m) == 0 {
+            delete(h.rooms, room)
+        }
+    }
+    h.mu.Unlock()
+    c.rooms.Remove(room)
+}
+
+// 处理WebSocket请求：升级连接、注册、读写循环，直至连接断开
+func (h *WebSocketHub) serve(r *Request, handler WebSocketHandler) {
+    ws, err := r.WebSocket()
+    if err != nil {
+        // 升级失败时底层已经返回了错误信息
+        return
+    }
+    h.mu.RLock()
+    config := h.config
+    h.mu.RUnlock()
+    c := &WebSocketConn {
+        ws        : ws,
+        Id        : h.connId.Add(1),
+        Request   : r,
+        hub       : h,
+        config    : config,
+        rooms     : gset.NewStringSet(),
+        data      : gmap.NewStringInterfaceMap(),
+        queue     : make(chan *websocket.PreparedMessage, config.WriteQueueSize),
+        done      : make(chan struct{}),
+    }
+    h.conns.Set(c.Id, c)
+    if f := h.onConnect.Val(); f != nil {
+        f.(WebSocketConnHandler)(c)
+    }
+    go c.writeLoop()
+    c.readLoop(handler)
+    c.Close()
+    // 清理房间及连接注册信息
+    for _, room := range c.rooms.Slice() {
+        h.leave(room, c)
+    }
+    h.conns.Remove(c.Id)
+    if f := h.onDisconnect.Val(); f != nil {
+        f.(WebSocketConnHandler)(c)
+    }
+}
+
+// 读取循环，在请求处理协程中执行
+func (c *WebSocketConn) readLoop(handler WebSocketHandler) {
+    config := c.config
+    if config.MaxMessageSize > 0 {
+        c.ws.SetReadLimit(config.MaxMessageSize)
+    }
+    // 收到pong或者任意消息时延长读超时时间
+    if config.PongTimeout > 0 {
+        c.ws.SetReadDeadline(time.Now().Add(config.PongTimeout))
+        c.ws.SetPongHandler(func(string) error {
+            return c.ws.SetReadDeadline(time.Now().Add(config.PongTimeout))
+        })
+    }
+    for {
+        msgType, data, err := c.ws.ReadMessage()
+        if err != nil {
+            if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+                glog.Debugfln("websocket connection %d closed: %v", c.Id, err)
+            }
+            return
+        }
+        if config.PongTimeout > 0 {
+            c.ws.SetReadDeadline(time.Now().Add(config.PongTimeout))
+        }
+        if handler != nil {
+            handler(c, msgType, data)
+        }
+    }
+}
+
+// 写入循环，写队列中的消息及心跳ping都在该协程中写出(底层连接不支持并发写)
+func (c *WebSocketConn) writeLoop() {
+    config := c.config
+    var tickerChan <-chan time.Time
+    if config.PingInterval > 0 {
+        ticker := time.NewTicker(config.PingInterval)
+        defer ticker.Stop()
+        tickerChan = ticker.C
+    }
+    deadline := func() time.Time {
+        if config.WriteTimeout > 0 {
+            return time.Now().Add(config.WriteTimeout)
+        }
+        return time.Time{}
+    }
+    for {
+        select {
+            case <- c.done:
+                c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), deadline())
+                c.ws.Close()
+                return
+
+            case pm := <- c.queue:
+                c.ws.SetWriteDeadline(deadline())
+                if err := c.ws.WritePreparedMessage(pm); err != nil {
+                    c.Close()
+                }
+
+            case <- tickerChan:
+                if err := c.ws.WriteControl(websocket.PingMessage, nil, deadline()); err != nil {
+                    c.Close()
+                }
+        }
+    }
+}
+
+// 发送消息(放入写队列)，写队列已满时最多等待WriteTimeout，超时返回ErrWebSocketQueueFull
+func (c *WebSocketConn) Send(msgType int, data []byte) error {
+    pm, err := websocket.NewPreparedMessage(msgType, data)
+    if err != nil {
+        return err
+    }
+    select {
+        case <- c.done:
+            return ErrWebSocketClosed
+        default:
+    }
+    select {
+        case c.queue <- pm:
+            return nil
+        default:
+    }
+    timeout := c.config.WriteTimeout
+    if timeout <= 0 {
+        timeout = defaultWebSocketHubConfig.WriteTimeout
+    }
+    timer := time.NewTimer(timeout)
+    defer timer.Stop()
+    select {
+        case <- c.done:
+            return ErrWebSocketClosed
+        case c.queue <- pm:
+            return nil
+        case <- timer.C:
+            return ErrWebSocketQueueFull
+    }
+}
+
+// 发送文本消息
+func (c *WebSocketConn) SendText(text string) error {
+    return c.Send(websocket.TextMessage, []byte(text))
+}
+
+// 加入房间
+func (c *WebSocketConn) Join(room string) {
+    c.hub.join(room, c)
+}
+
+// 离开房间
+func (c *WebSocketConn) Leave(room string) {
+    c.hub.leave(room, c)
+}
+
+// 获取已加入的房间列表
+func (c *WebSocketConn) Rooms() []string {
+    return c.rooms.Slice()
+}
+
+// 设置连接自定义数据
+func (c *WebSocketConn) Set(key string, value interface{}) {
+    c.data.Set(key, value)
+}
+
+// 获取连接自定义数据
+func (c *WebSocketConn) Get(key string) interface{} {
+    return c.data.Get(key)
+}
+
+// 获取客户端地址
+func (c *WebSocketConn) RemoteAddr() net.Addr {
+    return c.ws.RemoteAddr()
+}
+
+// 获取服务端地址
+func (c *WebSocketConn) LocalAddr() net.Addr {
+    return c.ws.LocalAddr()
+}
+
+// 获取协商的子协议
+func (c *WebSocketConn) Subprotocol() string {
+    return c.ws.Subprotocol()
+}
+
+// 获取关闭事件通道，连接关闭时该通道会被关闭
+func (c *WebSocketConn) Done() <-chan struct{} {
+    return c.done
+}
+
+// 关闭连接(异步发送关闭帧并断开底层连接)
+func (c *WebSocketConn) Close() error {
+    c.closeOnce.Do(func() {
+        close(c.done)
+    })
+    return nil
+}
@@ -63,12 +63,12 @@ func New() (*Watcher, error) {
     }
 }
 
-// 添加对指定文件/目录的监听，并给定回调函数；如果给定的是一个目录，默认递归监控。
-func Add(path string, callback func(event *Event)) error {
+// 添加对指定文件/目录的监听，并给定回调函数；如果给定的是一个目录，默认递归监控，recursive为false时只监控该目录本身。
+func Add(path string, callback func(event *Event), recursive...bool) error {
     if watcher == nil {
         return errors.New("global watcher creating failed")
     }
-    return watcher.Add(path, callback)
+    return watcher.Add(path, callback, recursive...)
 }
 
 // 移除监听，默认递归删除。
@@ -110,9 +110,9 @@ func (w *Watcher) addWatch(path string, callback func(event *Event)) error {
     return nil
 }
 
-// 递归添加监控
-func (w *Watcher) Add(path string, callback func(event *Event)) error {
-    if gfile.IsDir(path) {
+// 添加监控，目录默认递归添加，recursive为false时只监控该目录本身
+func (w *Watcher) Add(path string, callback func(event *Event), recursive...bool) error {
+    if gfile.IsDir(path) && (len(recursive) == 0 || recursive[0]) {
         list := []string{path}
         list  = append(list, gfile.ScanDir(path, true)...)
         for _, v := range list {
@@ -24,6 +24,7 @@ type Logger struct {
     btSkip   *gtype.Int          // 错误产生时的backtrace回调信息skip条数
     stdprint *gtype.Bool         // 控制台打印开关，当输出到文件时也同时打印到终端
                                  // @author zseeker,john
+    header   *gtype.Bool         // 是否在每条日志内容前输出时间头信息
 }
 
 var (
@@ -41,6 +42,7 @@ func New() *Logger {
         debug    : gtype.NewBool(true),
         btSkip   : gtype.NewInt(3),
         stdprint : gtype.NewBool(true),
+        header   : gtype.NewBool(true),
     }
 }
 
@@ -71,6 +73,11 @@ func SetStdPrint(open bool) {
     logger.SetStdPrint(open)
 }
 
+// 设置是否在每条日志内容前输出时间头信息，默认开启
+func SetHeaderPrint(enabled bool) {
+    logger.SetHeaderPrint(enabled)
+}
+
 func Print(v ...interface{}) {
     logger.Print(v ...)
 }
@@ -46,6 +46,7 @@ func (l *Logger) Clone() *Logger {
         debug    : l.debug,
         btSkip   : l.btSkip,
         stdprint : l.stdprint,
+        header   : l.header,
     }
 }
 
@@ -136,6 +137,12 @@ func (l *Logger) SetStdPrint(open bool) {
     l.stdprint.Set(open)
 }
 
+// 设置是否在每条日志内容前输出时间头信息，默认开启；
+// 当日志内容本身为结构化数据(例如JSON)或者已包含时间信息时可以关闭
+func (l *Logger) SetHeaderPrint(enabled bool) {
+    l.header.Set(enabled)
+}
+
 // 这里的写锁保证统一时刻只会写入一行日志，防止串日志的情况
 func (l *Logger) print(def io.Writer, s string) {
     // 优先使用自定义的IO输出
@@ -195,6 +202,9 @@ func (l *Logger) backtrace() string {
 }
 
 func (l *Logger) format(s string) string {
+    if !l.header.Val() {
+        return s
+    }
     return time.Now().Format("2006-01-02 15:04:05.000 ") + s
 }
 
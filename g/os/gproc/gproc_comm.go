@@ -23,7 +23,7 @@ var commPidConnMap    = gmap.NewIntInterfaceMap()
 
 // TCP通信数据结构定义
 type Msg struct {
-    Pid   int     // PID，来源哪个进程
+    Pid   int     // PID，来源哪个进程(只保存了低16位，PID大于65535时不完整，不能用于身份校验)
     Data  []byte  // 数据
     Group string  // 分组名称
 }
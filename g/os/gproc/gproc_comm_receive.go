@@ -117,10 +117,10 @@ func bufferToMsgs(buffer []byte) []*Msg {
             s++
             continue
         }
-        // 接收进程PID校验
-        if Pid() ==  gbinary.DecodeToInt(buffer[s + 5 : s + 7]) {
+        // 接收进程PID校验，数据包中的PID只保存了低16位(无符号)，因此只比较低16位
+        if uint16(Pid()) == gbinary.DecodeToUint16(buffer[s + 5 : s + 7]) {
             msgs = append(msgs, &Msg {
-                Pid   : gbinary.DecodeToInt(buffer[s + 3 : s + 5]),
+                Pid   : int(gbinary.DecodeToUint16(buffer[s + 3 : s + 5])),
                 Data  : buffer[s + 8 + groupLen + 4 : s + length],
                 Group : string(buffer[s + 8 : s + 8 + groupLen]),
             })
@@ -89,6 +89,15 @@ func (sp *SPath) Search(name string) string {
     return path
 }
 
+// 获取所有的搜索路径(按照优先级排序)
+func (sp *SPath) AllPaths() []string {
+    sp.mu.RLock()
+    paths := make([]string, len(sp.paths))
+    copy(paths, sp.paths)
+    sp.mu.RUnlock()
+    return paths
+}
+
 // 当前的搜索路径数量
 func (sp *SPath) Size() int {
     sp.mu.RLock()
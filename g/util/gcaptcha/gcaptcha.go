@@ -0,0 +1,292 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+// 图形验证码.
+// 使用内置的点阵字体及标准库image包生成PNG格式的验证码图片(字符旋转、波形扭曲及干扰点/线)，
+// 验证码答案保存在可替换的存储中(默认为内存存储)，校验时不区分大小写，并且每个验证码只能校验一次。
+package gcaptcha
+
+import (
+    "math"
+    "sync"
+    "time"
+    "bytes"
+    "errors"
+    "strings"
+    "image"
+    "image/png"
+    "image/color"
+    "crypto/rand"
+    mrand "math/rand"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+const (
+    gDEFAULT_WIDTH       = 120                               // 默认图片宽度
+    gDEFAULT_HEIGHT      = 40                                // 默认图片高度
+    gDEFAULT_LENGTH      = 4                                 // 默认验证码长度
+    gDEFAULT_CHARSET     = "23456789ABCDEFGHJKMNPQRSTUVWXYZ" // 默认字符集(去掉了容易混淆的0/O、1/I/L)
+    gDEFAULT_EXPIRE      = 5*time.Minute                     // 默认有效期
+    gDEFAULT_NOISE_LINES = 3                                 // 默认干扰线数量
+    gID_CHARSET          = "abcdefghijklmnopqrstuvwxyz0123456789"
+    gID_LENGTH           = 20                                // 自动生成的验证码ID长度
+)
+
+var (
+    ErrInvalidCharset = errors.New("captcha charset contains unsupported characters")
+)
+
+// 验证码配置，未设置的参数使用默认值
+type Config struct {
+    Width      int           // 图片宽度
+    Height     int           // 图片高度
+    Length     int           // 验证码字符数量
+    Charset    string        // 验证码字符集，只支持数字及字母(字母不区分大小写)
+    Expire     time.Duration // 有效期
+    NoiseDots  int           // 干扰点数量，默认按照图片面积计算
+    NoiseLines int           // 干扰线数量
+    Store      Store         // 答案存储，默认为内存存储
+}
+
+// 验证码对象
+type Captcha struct {
+    config Config
+}
+
+var (
+    // 默认的验证码对象
+    defaultCaptcha = gtype.NewInterface(New())
+    // 图形绘制使用的随机数(不需要密码学安全)
+    randMu         sync.Mutex
+    randSource     = mrand.New(mrand.NewSource(time.Now().UnixNano()))
+)
+
+// 创建验证码对象
+func New(config...Config) *Captcha {
+    c := Config{}
+    if len(config) > 0 {
+        c = config[0]
+    }
+    if c.Width <= 0 {
+        c.Width = gDEFAULT_WIDTH
+    }
+    if c.Height <= 0 {
+        c.Height = gDEFAULT_HEIGHT
+    }
+    if c.Length <= 0 {
+        c.Length = gDEFAULT_LENGTH
+    }
+    if c.Charset == "" {
+        c.Charset = gDEFAULT_CHARSET
+    }
+    c.Charset = strings.ToUpper(c.Charset)
+    if c.Expire <= 0 {
+        c.Expire = gDEFAULT_EXPIRE
+    }
+    if c.NoiseDots <= 0 {
+        c.NoiseDots = c.Width*c.Height/40
+    }
+    if c.NoiseLines <= 0 {
+        c.NoiseLines = gDEFAULT_NOISE_LINES
+    }
+    if c.Store == nil {
+        c.Store = NewMemoryStore()
+    }
+    return &Captcha{c}
+}
+
+// 获取默认的验证码对象
+func Instance() *Captcha {
+    return defaultCaptcha.Val().(*Captcha)
+}
+
+// 设置默认验证码对象的配置
+func SetConfig(config Config) {
+    defaultCaptcha.Set(New(config))
+}
+
+// 使用默认验证码对象生成验证码，参数及返回值同Captcha.Generate
+func Generate(id...string) (string, []byte, error) {
+    return Instance().Generate(id...)
+}
+
+// 使用默认验证码对象校验验证码
+func Verify(id, answer string) bool {
+    return Instance().Verify(id, answer)
+}
+
+// 获取配置
+func (c *Captcha) Config() Config {
+    return c.config
+}
+
+// 复制一个使用指定存储的验证码对象(其他配置相同)
+func (c *Captcha) WithStore(store Store) *Captcha {
+    config      := c.config
+    config.Store = store
+    return &Captcha{config}
+}
+
+// 生成验证码，答案保存到存储中，返回验证码ID及PNG图片内容；
+// id参数为自定义的验证码ID(例如刷新已有的验证码)，不传递时自动生成
+func (c *Captcha) Generate(id...string) (string, []byte, error) {
+    captchaId := ""
+    if len(id) > 0 && id[0] != "" {
+        captchaId = id[0]
+    } else {
+        captchaId = randomString(gID_CHARSET, gID_LENGTH)
+    }
+    answer := c.NewAnswer()
+    image, err := c.Render(answer)
+    if err != nil {
+        return "", nil, err
+    }
+    if err := c.config.Store.Set(captchaId, answer, c.config.Expire); err != nil {
+        return "", nil, err
+    }
+    return captchaId, image, nil
+}
+
+// 校验验证码，不区分大小写，无论校验是否成功，验证码都会被删除(只能校验一次)
+func (c *Captcha) Verify(id, answer string) bool {
+    if id == "" || answer == "" {
+        return false
+    }
+    expect := c.config.Store.Get(id, true)
+    return expect != "" && strings.EqualFold(expect, strings.TrimSpace(answer))
+}
+
+// 随机生成验证码答案
+func (c *Captcha) NewAnswer() string {
+    return randomString(c.config.Charset, c.config.Length)
+}
+
+// 将给定的答案绘制为PNG图片
+func (c *Captcha) Render(answer string) ([]byte, error) {
+    answer = strings.ToUpper(answer)
+    for _, char := range answer {
+        if _, ok := fontGlyphs[char]; !ok {
+            return nil, ErrInvalidCharset
+        }
+    }
+    randMu.Lock()
+    img := c.draw(answer)
+    randMu.Unlock()
+    buffer := bytes.NewBuffer(nil)
+    if err := png.Encode(buffer, img); err != nil {
+        return nil, err
+    }
+    return buffer.Bytes(), nil
+}
+
+// 绘制验证码图片：背景 -> 干扰线 -> 字符(随机旋转、位置及颜色) -> 波形扭曲 -> 干扰点
+func (c *Captcha) draw(answer string) image.Image {
+    width, height := c.config.Width, c.config.Height
+    background    := color.RGBA{uint8(220 + randSource.Intn(36)), uint8(220 + randSource.Intn(36)), uint8(220 + randSource.Intn(36)), 255}
+    img           := image.NewRGBA(image.Rect(0, 0, width, height))
+    for i := 0; i < len(img.Pix); i += 4 {
+        img.Pix[i], img.Pix[i + 1], img.Pix[i + 2], img.Pix[i + 3] = background.R, background.G, background.B, background.A
+    }
+    for i := 0; i < c.config.NoiseLines; i++ {
+        drawCurve(img, randomColor(100, 200))
+    }
+    // 字符缩放比例按照每个字符所占的宽度及图片高度计算
+    chars := []rune(answer)
+    cell  := float64(width)/float64(len(chars))
+    scale := math.Min(cell*0.75/gFONT_WIDTH, float64(height)*0.7/gFONT_HEIGHT)
+    for i, char := range chars {
+        cx := cell*(float64(i) + 0.5) + (randSource.Float64() - 0.5)*cell*0.2
+        cy := float64(height)/2 + (randSource.Float64() - 0.5)*float64(height)*0.15
+        drawGlyph(img, fontGlyphs[char], cx, cy, scale, (randSource.Float64() - 0.5)*0.7, randomColor(0, 120))
+    }
+    result := distort(img, background)
+    for i := 0; i < c.config.NoiseDots; i++ {
+        result.Set(randSource.Intn(width), randSource.Intn(height), randomColor(0, 255))
+    }
+    return result
+}
+
+// 以(cx, cy)为中心绘制旋转angle弧度后的点阵字符
+func drawGlyph(img *image.RGBA, glyph [gFONT_HEIGHT]string, cx, cy, scale, angle float64, c color.RGBA) {
+    sin, cos := math.Sin(angle), math.Cos(angle)
+    radius   := int(math.Ceil(scale*gFONT_HEIGHT*0.75))
+    bounds   := img.Bounds()
+    for y := int(cy) - radius; y <= int(cy) + radius; y++ {
+        for x := int(cx) - radius; x <= int(cx) + radius; x++ {
+            if !(image.Point{x, y}).In(bounds) {
+                continue
+            }
+            // 反向旋转得到在点阵中的位置
+            dx, dy := float64(x) - cx, float64(y) - cy
+            u      := ( dx*cos + dy*sin)/scale + gFONT_WIDTH/2.0
+            v      := (-dx*sin + dy*cos)/scale + gFONT_HEIGHT/2.0
+            if u < 0 || v < 0 || u >= gFONT_WIDTH || v >= gFONT_HEIGHT {
+                continue
+            }
+            if glyph[int(v)][int(u)] == '#' {
+                img.SetRGBA(x, y, c)
+            }
+        }
+    }
+}
+
+// 绘制一条随机的正弦干扰曲线
+func drawCurve(img *image.RGBA, c color.RGBA) {
+    width, height := img.Bounds().Dx(), img.Bounds().Dy()
+    amplitude     := float64(height)*(0.1 + randSource.Float64()*0.25)
+    period        := float64(width)*(0.5 + randSource.Float64())
+    phase         := randSource.Float64()*2*math.Pi
+    offset        := float64(height)*(0.25 + randSource.Float64()*0.5)
+    for x := 0; x < width; x++ {
+        y := int(offset + amplitude*math.Sin(2*math.Pi*float64(x)/period + phase))
+        img.SetRGBA(x, y,     c)
+        img.SetRGBA(x, y + 1, c)
+    }
+}
+
+// 对图片进行正弦波形扭曲，超出范围的位置使用背景色
+func distort(src *image.RGBA, background color.RGBA) *image.RGBA {
+    width, height := src.Bounds().Dx(), src.Bounds().Dy()
+    dst           := image.NewRGBA(src.Bounds())
+    amplitudeX    := float64(height)*0.06
+    amplitudeY    := float64(height)*0.08
+    periodX       := float64(height)*(0.8 + randSource.Float64()*0.4)
+    periodY       := float64(width)*(0.4 + randSource.Float64()*0.3)
+    phaseX        := randSource.Float64()*2*math.Pi
+    phaseY        := randSource.Float64()*2*math.Pi
+    for y := 0; y < height; y++ {
+        for x := 0; x < width; x++ {
+            sx := x + int(amplitudeX*math.Sin(2*math.Pi*float64(y)/periodX + phaseX))
+            sy := y + int(amplitudeY*math.Sin(2*math.Pi*float64(x)/periodY + phaseY))
+            if sx >= 0 && sx < width && sy >= 0 && sy < height {
+                dst.SetRGBA(x, y, src.RGBAAt(sx, sy))
+            } else {
+                dst.SetRGBA(x, y, background)
+            }
+        }
+    }
+    return dst
+}
+
+// 生成各颜色分量在[min, max)范围内的随机颜色
+func randomColor(min, max int) color.RGBA {
+    return color.RGBA {
+        uint8(min + randSource.Intn(max - min)),
+        uint8(min + randSource.Intn(max - min)),
+        uint8(min + randSource.Intn(max - min)),
+        255,
+    }
+}
+
+// 使用密码学安全的随机数从字符集中生成指定长度的字符串
+func randomString(charset string, length int) string {
+    b := make([]byte, length)
+    rand.Read(b)
+    for i, v := range b {
+        b[i] = charset[int(v) % len(charset)]
+    }
+    return string(b)
+}
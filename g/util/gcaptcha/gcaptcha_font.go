@@ -0,0 +1,52 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package gcaptcha
+
+const (
+    gFONT_WIDTH  = 5 // 内置点阵字体的字符宽度
+    gFONT_HEIGHT = 7 // 内置点阵字体的字符高度
+)
+
+// 内置的5x7点阵字体(数字及大写字母)，"#"表示需要绘制的点
+var fontGlyphs = map[rune][gFONT_HEIGHT]string {
+    '0' : {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+    '1' : {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+    '2' : {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+    '3' : {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+    '4' : {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+    '5' : {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+    '6' : {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+    '7' : {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+    '8' : {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+    '9' : {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+    'A' : {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+    'B' : {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+    'C' : {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+    'D' : {"###..", "#..#.", "#...#", "#...#", "#...#", "#..#.", "###.."},
+    'E' : {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+    'F' : {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+    'G' : {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+    'H' : {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+    'I' : {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+    'J' : {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+    'K' : {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+    'L' : {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+    'M' : {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+    'N' : {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+    'O' : {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+    'P' : {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+    'Q' : {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+    'R' : {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+    'S' : {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+    'T' : {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+    'U' : {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+    'V' : {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+    'W' : {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+    'X' : {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+    'Y' : {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+    'Z' : {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+}
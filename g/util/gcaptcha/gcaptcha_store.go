@@ -0,0 +1,106 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package gcaptcha
+
+import (
+    "sync"
+    "time"
+    "gitee.com/johng/gf/g/os/gcache"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/database/gredis"
+)
+
+const (
+    gDEFAULT_REDIS_PREFIX = "gcaptcha:" // Redis存储默认键名前缀
+    gDEFAULT_MEMORY_CAP   = 100000      // 内存存储默认的最大答案数量，超过后按照LRU淘汰，防止客户端使用任意ID无限写入
+)
+
+// 原子性地获取并删除答案，防止同一答案被并发校验多次(GETDEL需要Redis 6.2+，因此使用Lua脚本)
+const gREDIS_GET_DEL_SCRIPT = `local v = redis.call('GET', KEYS[1]); if v then redis.call('DEL', KEYS[1]) end; return v`
+
+// 验证码答案存储接口，Get的clear参数表示获取后是否删除(获取及删除需要是原子操作)，答案不存在或者已过期时返回空字符串
+type Store interface {
+    Set(id, answer string, expire time.Duration) error
+    Get(id string, clear bool) string
+}
+
+// 基于内存缓存的存储(单进程)
+type memoryStore struct {
+    mu    sync.Mutex
+    cache *gcache.Cache
+}
+
+// 基于Redis的存储(多进程/多节点共享)
+type redisStore struct {
+    redis  *gredis.Redis
+    prefix string
+}
+
+// 创建基于gcache的存储，不传递参数时使用新的缓存对象(最多保存100000个答案，超过后按照LRU淘汰)
+func NewMemoryStore(cache...*gcache.Cache) Store {
+    if len(cache) > 0 && cache[0] != nil {
+        return &memoryStore{cache : cache[0]}
+    }
+    c := gcache.New()
+    c.SetCap(gDEFAULT_MEMORY_CAP)
+    return &memoryStore{cache : c}
+}
+
+func (s *memoryStore) Set(id, answer string, expire time.Duration) error {
+    // gcache的过期时间单位为毫秒，并且0表示不过期
+    ms := int(expire/time.Millisecond)
+    if ms <= 0 {
+        ms = 1
+    }
+    s.cache.Set(id, answer, ms)
+    return nil
+}
+
+func (s *memoryStore) Get(id string, clear bool) string {
+    if !clear {
+        return gconv.String(s.cache.Get(id))
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    answer := gconv.String(s.cache.Get(id))
+    s.cache.Remove(id)
+    return answer
+}
+
+// 创建基于gredis的存储，prefix为键名前缀，默认为gcaptcha:
+func NewRedisStore(redis *gredis.Redis, prefix...string) Store {
+    s := &redisStore{redis : redis, prefix : gDEFAULT_REDIS_PREFIX}
+    if len(prefix) > 0 {
+        s.prefix = prefix[0]
+    }
+    return s
+}
+
+func (s *redisStore) Set(id, answer string, expire time.Duration) error {
+    ms := int64(expire/time.Millisecond)
+    if ms <= 0 {
+        ms = 1
+    }
+    _, err := s.redis.Do("SET", s.prefix + id, answer, "PX", ms)
+    return err
+}
+
+func (s *redisStore) Get(id string, clear bool) string {
+    var (
+        v   interface{}
+        err error
+    )
+    if clear {
+        v, err = s.redis.Do("EVAL", gREDIS_GET_DEL_SCRIPT, 1, s.prefix + id)
+    } else {
+        v, err = s.redis.Do("GET", s.prefix + id)
+    }
+    if err != nil || v == nil {
+        return ""
+    }
+    return gconv.String(v)
+}
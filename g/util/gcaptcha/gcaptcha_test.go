@@ -0,0 +1,85 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package gcaptcha
+
+import (
+    "sync"
+    "bytes"
+    "strings"
+    "testing"
+    "image/png"
+)
+
+func Test_Render(t *testing.T) {
+    c := New(Config{Width : 160, Height : 50})
+    b, err := c.Render(c.NewAnswer())
+    if err != nil {
+        t.Fatal(err)
+    }
+    img, err := png.Decode(bytes.NewReader(b))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if img.Bounds().Dx() != 160 || img.Bounds().Dy() != 50 {
+        t.Error("unexpected image size:", img.Bounds())
+    }
+    if _, err := c.Render("a#"); err != ErrInvalidCharset {
+        t.Error("expected charset error, got:", err)
+    }
+}
+
+func Test_Verify(t *testing.T) {
+    store  := NewMemoryStore()
+    c      := New(Config{Store : store})
+    id, _, err := c.Generate()
+    if err != nil {
+        t.Fatal(err)
+    }
+    answer := store.Get(id, false)
+    if len(answer) != gDEFAULT_LENGTH {
+        t.Fatal("unexpected answer:", answer)
+    }
+    if !c.Verify(id, strings.ToLower(answer)) {
+        t.Error("verify failed")
+    }
+    // 每个验证码只能校验一次
+    if c.Verify(id, answer) {
+        t.Error("captcha verified twice")
+    }
+    id, _, _ = c.Generate("custom")
+    if id != "custom" || c.Verify(id, "wrong") || c.Verify(id, store.Get(id, false)) {
+        t.Error("wrong answer should invalidate captcha")
+    }
+}
+
+func Test_VerifyConcurrent(t *testing.T) {
+    store := NewMemoryStore()
+    c     := New(Config{Store : store})
+    id, _, _ := c.Generate()
+    answer   := store.Get(id, false)
+    // 同一答案并发校验时只能有一次校验成功
+    var (
+        wg      sync.WaitGroup
+        mu      sync.Mutex
+        success int
+    )
+    for i := 0; i < 20; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            if c.Verify(id, answer) {
+                mu.Lock()
+                success++
+                mu.Unlock()
+            }
+        }()
+    }
+    wg.Wait()
+    if success != 1 {
+        t.Error("expected exactly one successful verification, got:", success)
+    }
+}
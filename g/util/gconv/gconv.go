@@ -39,6 +39,7 @@ func Convert(i interface{}, t string, params...interface{}) interface{} {
         case "bool":            return Bool(i)
         case "string":          return String(i)
         case "[]byte":          return Bytes(i)
+        case "[]string":        return Strings(i)
         case "time.Time":
             if len(params) > 0 {
                 return Time(i, String(params[0]))
@@ -361,6 +362,40 @@ func bindVarToStruct(elem reflect.Value, name string, value interface{}) {
     if !structFieldValue.CanSet() {
         return
     }
-    // 必须将value转换为struct属性的数据类型，这里必须用到gconv包
-    structFieldValue.Set(reflect.ValueOf(Convert(value, structFieldValue.Type().String())))
+    // 必须将value转换为struct属性的数据类型，无法转换时忽略该属性
+    if v, ok := convertToType(value, structFieldValue.Type()); ok {
+        structFieldValue.Set(v)
+    }
+}
+
+// 将变量转换为指定的反射类型，数组(slice)按照属性的元素类型逐个转换(例如：[]string转换为[]int)，
+// 自定义的基础类型(例如：type Status int)按照底层类型转换；无法转换时返回false
+func convertToType(value interface{}, t reflect.Type) (reflect.Value, bool) {
+    v := reflect.ValueOf(Convert(value, t.String()))
+    if v.IsValid() && v.Type().AssignableTo(t) {
+        return v, true
+    }
+    switch t.Kind() {
+        case reflect.Slice:
+            items := reflect.ValueOf(value)
+            if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+                // 单个值转换为只有一个元素的数组
+                items = reflect.ValueOf([]interface{}{value})
+            }
+            slice := reflect.MakeSlice(t, items.Len(), items.Len())
+            for i := 0; i < items.Len(); i++ {
+                if item, ok := convertToType(items.Index(i).Interface(), t.Elem()); ok {
+                    slice.Index(i).Set(item)
+                }
+            }
+            return slice, true
+
+        case reflect.Ptr, reflect.Struct, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+
+        default:
+            if k := reflect.ValueOf(Convert(value, t.Kind().String())); k.IsValid() && k.Type().ConvertibleTo(t) {
+                return k.Convert(t), true
+            }
+    }
+    return reflect.Value{}, false
 }
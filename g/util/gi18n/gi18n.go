@@ -0,0 +1,362 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+// 国际化(多语言)管理.
+// 翻译文件格式支持：json, xml, toml, yaml/yml，文件按照语言命名，存放在搜索目录下，例如：
+// i18n/en.toml、i18n/ja.yaml、i18n/zh-CN.json，
+// 也可以按照语言建立子目录，目录下的所有翻译文件合并为该语言的翻译，例如：i18n/en/user.toml、i18n/en/order.toml；
+// 翻译文件中的层级键名使用"."连接，例如：[user] name = "Name" 对应的键名为 user.name。
+package gi18n
+
+import (
+    "fmt"
+    "sort"
+    "sync"
+    "strings"
+    "gitee.com/johng/gf/g/os/genv"
+    "gitee.com/johng/gf/g/os/gcmd"
+    "gitee.com/johng/gf/g/os/gfile"
+    "gitee.com/johng/gf/g/os/gspath"
+    "gitee.com/johng/gf/g/os/gfsnotify"
+    "gitee.com/johng/gf/g/util/gconv"
+    "gitee.com/johng/gf/g/container/gtype"
+    "gitee.com/johng/gf/g/encoding/gparser"
+)
+
+const (
+    gDEFAULT_LOCALE = "en"   // 默认语言
+    gDEFAULT_FOLDER = "i18n" // 默认的翻译文件目录名称
+)
+
+// 支持的翻译文件扩展名
+var fileExtensions = map[string]struct{} {
+    ".json" : {},
+    ".xml"  : {},
+    ".toml" : {},
+    ".yaml" : {},
+    ".yml"  : {},
+}
+
+// 国际化管理对象
+type Manager struct {
+    mu       sync.RWMutex
+    paths    *gspath.SPath                // 翻译文件搜索目录
+    data     map[string]map[string]string // 翻译内容(键名为语言及翻译键名)，为nil时表示需要重新加载
+    locale   *gtype.String                // 默认语言
+    monitors map[string]struct{}          // 已经添加文件监控的目录
+}
+
+var (
+    // 默认的国际化管理对象
+    defaultManager *Manager
+    // 默认国际化管理对象初始化
+    defaultOnce    sync.Once
+)
+
+// 生成一个国际化管理对象，path为翻译文件搜索目录(非必需)
+func New(path...string) *Manager {
+    m := &Manager {
+        paths    : gspath.New(),
+        locale   : gtype.NewString(gDEFAULT_LOCALE),
+        monitors : make(map[string]struct{}),
+    }
+    if len(path) > 0 {
+        m.paths.Set(path[0])
+    }
+    return m
+}
+
+// 获取默认的国际化管理对象，翻译文件目录可以通过命令行参数或者环境变量gf.i18npath设置，
+// 默认为可执行文件所在目录以及入口文件所在目录(开发环境)下的i18n目录
+func Instance() *Manager {
+    defaultOnce.Do(func() {
+        path := gcmd.Option.Get("gf.i18npath")
+        if path == "" {
+            path = genv.Get("gf.i18npath")
+            if path == "" {
+                path = gfile.SelfDir() + gfile.Separator + gDEFAULT_FOLDER
+            }
+        }
+        defaultManager = New()
+        defaultManager.paths.Set(path)
+        if p := gfile.MainPkgPath(); gfile.Exists(p) {
+            defaultManager.paths.Add(p + gfile.Separator + gDEFAULT_FOLDER)
+        }
+    })
+    return defaultManager
+}
+
+// 设置默认国际化管理对象的翻译文件搜索目录
+func SetPath(path string) error {
+    return Instance().SetPath(path)
+}
+
+// 添加默认国际化管理对象的翻译文件搜索目录
+func AddPath(path string) error {
+    return Instance().AddPath(path)
+}
+
+// 设置默认国际化管理对象的默认语言
+func SetDefaultLocale(locale string) {
+    Instance().SetDefaultLocale(locale)
+}
+
+// 使用默认国际化管理对象翻译指定的键名
+func T(locale, key string, args...interface{}) string {
+    return Instance().Translate(locale, key, args...)
+}
+
+// 设置翻译文件搜索目录
+func (m *Manager) SetPath(path string) error {
+    if err := m.paths.Set(path); err != nil {
+        return err
+    }
+    m.Reload()
+    return nil
+}
+
+// 添加翻译文件搜索目录，先添加的目录优先级更高
+func (m *Manager) AddPath(path string) error {
+    if err := m.paths.Add(path); err != nil {
+        return err
+    }
+    m.Reload()
+    return nil
+}
+
+// 设置默认语言，请求的语言不存在对应翻译时使用默认语言的翻译
+func (m *Manager) SetDefaultLocale(locale string) {
+    m.locale.Set(normalizeLocale(locale))
+}
+
+// 获取默认语言
+func (m *Manager) GetDefaultLocale() string {
+    return m.locale.Val()
+}
+
+// 清空翻译内容缓存，下一次查询时重新从磁盘文件读取
+func (m *Manager) Reload() {
+    m.mu.Lock()
+    m.data = nil
+    m.mu.Unlock()
+}
+
+// 获取已有翻译文件的语言列表
+func (m *Manager) Locales() []string {
+    data    := m.getData()
+    locales := make([]string, 0, len(data))
+    for locale, _ := range data {
+        locales = append(locales, locale)
+    }
+    return locales
+}
+
+// 查询指定语言下的翻译内容，按照 指定语言 -> 基础语言(如zh-CN对应的zh) 的顺序查找，不存在时返回false
+func (m *Manager) Lookup(locale, key string) (string, bool) {
+    data := m.getData()
+    for _, v := range localeChain(normalizeLocale(locale)) {
+        if content, ok := data[v][key]; ok {
+            return content, true
+        }
+    }
+    return "", false
+}
+
+// 翻译指定的键名，指定语言没有对应翻译时使用默认语言的翻译，都不存在时返回键名本身；
+// 给定args参数时，翻译内容将作为格式化字符串进行格式化，例如："Hello %s" -> T("en", "hello", "john")
+func (m *Manager) Translate(locale, key string, args...interface{}) string {
+    content, ok := m.Lookup(locale, key)
+    if !ok {
+        if content, ok = m.Lookup(m.locale.Val(), key); !ok {
+            content = key
+        }
+    }
+    if len(args) > 0 {
+        return fmt.Sprintf(content, args...)
+    }
+    return content
+}
+
+// 按照顺序从给定的候选语言中选择第一个有对应翻译的语言，
+// 候选语言与翻译语言的基础语言相同时也认为匹配(例如：zh-TW 匹配 zh、en 匹配 en-US)；
+// 没有任何翻译文件时返回第一个有效的候选语言，都不匹配时返回默认语言。
+func (m *Manager) Match(locales...string) string {
+    data := m.getData()
+    for _, locale := range locales {
+        locale = normalizeLocale(locale)
+        if locale == "" {
+            continue
+        }
+        if len(data) == 0 {
+            return locale
+        }
+        for _, v := range localeChain(locale) {
+            if _, ok := data[v]; ok {
+                return v
+            }
+        }
+        // 同一基础语言有多个翻译时(例如：en-GB、en-US)按照名称排序，保证每次匹配的结果一致
+        base       := localeBase(locale)
+        candidates := make([]string, 0)
+        for v, _ := range data {
+            if localeBase(v) == base {
+                candidates = append(candidates, v)
+            }
+        }
+        if len(candidates) > 0 {
+            sort.Strings(candidates)
+            return candidates[0]
+        }
+    }
+    return m.locale.Val()
+}
+
+// 解析Accept-Language头，返回按照权重从高到低排序的语言列表
+func ParseAcceptLanguage(header string) []string {
+    type item struct {
+        locale string
+        q      float64
+    }
+    items := make([]item, 0)
+    for _, part := range strings.Split(header, ",") {
+        fields := strings.Split(part, ";")
+        v      := item{normalizeLocale(fields[0]), 1}
+        for _, param := range fields[1:] {
+            param = strings.TrimSpace(param)
+            if strings.HasPrefix(param, "q=") {
+                v.q = gconv.Float64(param[2:])
+            }
+        }
+        if v.locale != "" && v.locale != "*" && v.q > 0 {
+            items = append(items, v)
+        }
+    }
+    // 稳定排序，相同权重保持原有顺序
+    for i := 1; i < len(items); i++ {
+        for j := i; j > 0 && items[j].q > items[j - 1].q; j-- {
+            items[j], items[j - 1] = items[j - 1], items[j]
+        }
+    }
+    locales := make([]string, len(items))
+    for k, v := range items {
+        locales[k] = v.locale
+    }
+    return locales
+}
+
+// 获取翻译内容，没有缓存时从搜索目录中加载
+func (m *Manager) getData() map[string]map[string]string {
+    m.mu.RLock()
+    data := m.data
+    m.mu.RUnlock()
+    if data != nil {
+        return data
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if m.data == nil {
+        m.data = m.load()
+    }
+    return m.data
+}
+
+// 从所有搜索目录中加载翻译文件，优先级高的目录覆盖优先级低的目录中的相同键名
+func (m *Manager) load() map[string]map[string]string {
+    data  := make(map[string]map[string]string)
+    paths := m.paths.AllPaths()
+    for i := len(paths) - 1; i >= 0; i-- {
+        m.addMonitor(paths[i])
+        for _, name := range gfile.ScanDir(paths[i]) {
+            path := paths[i] + gfile.Separator + name
+            if gfile.IsDir(path) {
+                locale := normalizeLocale(name)
+                for _, file := range gfile.ScanDir(path, true) {
+                    loadFile(data, locale, file)
+                }
+            } else {
+                loadFile(data, normalizeLocale(strings.TrimSuffix(name, gfile.Ext(name))), path)
+            }
+        }
+    }
+    return data
+}
+
+// 添加目录监控，翻译文件变化时清空缓存，下一次查询时自动重新加载
+func (m *Manager) addMonitor(path string) {
+    if _, ok := m.monitors[path]; ok {
+        return
+    }
+    m.monitors[path] = struct{}{}
+    gfsnotify.Add(path, func(event *gfsnotify.Event) {
+        m.Reload()
+    })
+}
+
+// 加载单个翻译文件到指定语言中，非翻译文件将被忽略
+func loadFile(data map[string]map[string]string, locale, path string) {
+    if _, ok := fileExtensions[strings.ToLower(gfile.Ext(path))]; !ok || locale == "" {
+        return
+    }
+    p, err := gparser.Load(path)
+    if err != nil {
+        return
+    }
+    if _, ok := data[locale]; !ok {
+        data[locale] = make(map[string]string)
+    }
+    flatten(data[locale], "", p.ToMap())
+}
+
+// 将层级的翻译内容转换为"."连接的键名
+func flatten(result map[string]string, prefix string, m map[string]interface{}) {
+    for k, v := range m {
+        if prefix != "" {
+            k = prefix + "." + k
+        }
+        if sub, ok := v.(map[string]interface{}); ok {
+            flatten(result, k, sub)
+        } else {
+            result[k] = gconv.String(v)
+        }
+    }
+}
+
+// 统一语言名称格式，例如：zh_cn -> zh-CN
+func NormalizeLocale(locale string) string {
+    return normalizeLocale(locale)
+}
+
+// 统一语言名称格式
+func normalizeLocale(locale string) string {
+    locale = strings.Replace(strings.TrimSpace(locale), "_", "-", -1)
+    array := strings.Split(locale, "-")
+    array[0] = strings.ToLower(array[0])
+    for i := 1; i < len(array); i++ {
+        // 地区使用大写，其他部分(如书写系统Hans)首字母大写
+        if len(array[i]) == 2 {
+            array[i] = strings.ToUpper(array[i])
+        } else if len(array[i]) > 2 {
+            array[i] = strings.ToUpper(array[i][:1]) + strings.ToLower(array[i][1:])
+        }
+    }
+    return strings.Join(array, "-")
+}
+
+// 获取语言对应的基础语言，例如：zh-CN -> zh
+func localeBase(locale string) string {
+    if pos := strings.Index(locale, "-"); pos > 0 {
+        return locale[:pos]
+    }
+    return locale
+}
+
+// 获取语言的查找顺序：语言本身 -> 基础语言
+func localeChain(locale string) []string {
+    if base := localeBase(locale); base != locale {
+        return []string{locale, base}
+    }
+    return []string{locale}
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+package gi18n
+
+import (
+    "os"
+    "testing"
+    "io/ioutil"
+    "path/filepath"
+)
+
+func Test_Translate(t *testing.T) {
+    dir, _ := ioutil.TempDir("", "gi18n")
+    defer os.RemoveAll(dir)
+    os.MkdirAll(filepath.Join(dir, "ja"), 0755)
+    ioutil.WriteFile(filepath.Join(dir, "en.toml"),   []byte("hello = \"Hello %s\"\n[user]\nname = \"Name\"\n"), 0644)
+    ioutil.WriteFile(filepath.Join(dir, "zh-CN.json"), []byte(`{"hello" : "你好 %s"}`), 0644)
+    ioutil.WriteFile(filepath.Join(dir, "ja", "user.yaml"), []byte("user:\n  name: 名前\n"), 0644)
+
+    m := New(dir)
+    if v := m.Translate("en", "hello", "john"); v != "Hello john" {
+        t.Error("unexpected en translation:", v)
+    }
+    if v := m.Translate("zh_cn", "hello", "john"); v != "你好 john" {
+        t.Error("unexpected zh-CN translation:", v)
+    }
+    if v := m.Translate("ja", "user.name"); v != "名前" {
+        t.Error("unexpected ja translation:", v)
+    }
+    // 缺失的翻译使用默认语言，都不存在时返回键名
+    if v := m.Translate("ja", "hello", "john"); v != "Hello john" {
+        t.Error("unexpected fallback translation:", v)
+    }
+    if v := m.Translate("ja", "not.exist"); v != "not.exist" {
+        t.Error("unexpected missing translation:", v)
+    }
+}
+
+func Test_Match(t *testing.T) {
+    dir, _ := ioutil.TempDir("", "gi18n")
+    defer os.RemoveAll(dir)
+    ioutil.WriteFile(filepath.Join(dir, "en-US.toml"), []byte(`a = "a"`), 0644)
+    ioutil.WriteFile(filepath.Join(dir, "zh.toml"),    []byte(`a = "a"`), 0644)
+
+    m := New(dir)
+    locales := ParseAcceptLanguage("fr;q=0.9, zh-TW;q=0.8, en;q=0.95, *;q=0.1")
+    if len(locales) != 3 || locales[0] != "en" || locales[1] != "fr" {
+        t.Error("unexpected accept languages:", locales)
+    }
+    if v := m.Match(locales...); v != "en-US" {
+        t.Error("unexpected match:", v)
+    }
+    if v := m.Match("zh-TW"); v != "zh" {
+        t.Error("unexpected match:", v)
+    }
+    if v := m.Match("fr"); v != m.GetDefaultLocale() {
+        t.Error("unexpected match:", v)
+    }
+    // 同一基础语言有多个翻译时匹配结果稳定
+    ioutil.WriteFile(filepath.Join(dir, "en-GB.toml"), []byte(`a = "a"`), 0644)
+    for i := 0; i < 10; i++ {
+        if v := New(dir).Match("en"); v != "en-GB" {
+            t.Error("unexpected match:", v)
+        }
+    }
+}
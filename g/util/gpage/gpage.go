@@ -23,6 +23,7 @@ type Page struct {
     Url            *url2.URL      // 当前页面的URL对象
     Router         *ghttp.Router  // 当前页面的路由对象(与gf框架耦合，在静态分页下有效)
     UrlTemplate    string         // URL生成规则，内部可使用{.page}变量指定页码
+    UrlBuilder     func(page int) string // URL生成方法，优先级高于UrlTemplate，例如结合命名路由使用ghttp.Server.URL生成
     TotalSize      int            // 总共数据条数
     TotalPage      int            // 总页数
     CurrentPage    int            // 当前页码
@@ -75,6 +76,14 @@ func (page *Page) SetUrlTemplate(template string) {
     page.UrlTemplate = template
 }
 
+// 设置URL生成方法，参数为页码，返回该页码对应的URL地址，例如：
+// page.SetUrlBuilder(func(pageNo int) string {
+//     return s.URL("article.list", g.Map{"page" : pageNo})
+// })
+func (page *Page) SetUrlBuilder(builder func(page int) string) {
+    page.UrlBuilder = builder
+}
+
 // 获取显示"下一页"的内容.
 func (page *Page) NextPage(styles ... string) string {
     var curStyle, style string
@@ -247,6 +256,9 @@ func (page *Page) GetContent(mode int) string {
 
 // 为指定的页面返回地址值
 func (page *Page) GetUrl(pageNo int) string {
+    if page.UrlBuilder != nil {
+        return page.UrlBuilder(pageNo)
+    }
     // 复制一个URL对象
     url := *page.Url
     if len(page.UrlTemplate) == 0  && page.Router != nil {
@@ -272,7 +284,8 @@ func (page *Page) makeUrlTemplate(url string, router *ghttp.Router) (tpl string)
                 tpl          = router.Uri
                 hasPageName := false
                 for i, name := range router.RegNames {
-                    rule := fmt.Sprintf(`[:\*]%s|\{%s\}`, name, name)
+                    // 路由参数可能带有约束规则，例如：:page<int>、{page:\d+}
+                    rule := fmt.Sprintf(`[:\*]%s(<[^>]*>)?|\{%s(:[^/]*)?\}`, name, name)
                     if !hasPageName && strings.Compare(name, page.PageName) == 0 {
                         hasPageName = true
                         tpl, _ = gregex.ReplaceString(rule, `{.page}`, tpl)
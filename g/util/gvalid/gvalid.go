@@ -20,6 +20,7 @@ import (
     "gitee.com/johng/gf/g/util/gconv"
     "gitee.com/johng/gf/g/encoding/gjson"
     "gitee.com/johng/gf/g/container/gmap"
+    "gitee.com/johng/gf/g/container/gtype"
 )
 
 /*
@@ -65,6 +66,7 @@ different            格式：different:field                       说明：参
 in                   格式：in:value1,value2,...                  说明：参数值应该在value1,value2,...中(字符串匹配)
 not-in               格式：not-in:value1,value2,...              说明：参数值不应该在value1,value2,...中(字符串匹配)
 regex                格式：regex:pattern                         说明：参数值应当满足正则匹配规则pattern
+captcha              格式：captcha:field                         说明：参数值为图形验证码答案，field为验证码ID参数名称(使用SetCaptchaVerifier设置的校验方法，未设置时校验失败)
 */
 
 // 默认规则校验错误消息(可以通过接口自定义错误消息)
@@ -111,6 +113,10 @@ var defaultMessages = map[string]string {
     "in"                   : "字段值不合法",
     "not-in"               : "字段值不合法",
     "regex"                : "字段值不合法",
+    "captcha"              : "验证码不正确",
+    "invalid-rule-integer" : "校验参数[:value]应当为整数类型",
+    "invalid-rule-number"  : "校验参数[:value]应当为数字类型",
+    "invalid-value-number" : "输入参数[:value]应当为数字类型",
 }
 
 const (
@@ -123,6 +129,9 @@ var errorMsgMap  = gmap.NewStringStringMap()
 // 单规则正则对象，这里使用包内部变量存储，不需要多次解析
 var ruleRegex, _ = regexp.Compile(gSINGLE_RULE_PATTERN)
 
+// captcha规则的验证码校验方法(func(id, answer string) bool)
+var captchaVerifier = gtype.NewInterface()
+
 // 初始化错误消息管理对象
 func init() {
     errorMsgMap.BatchSet(defaultMessages)
@@ -136,6 +145,12 @@ func SetDefaultErrorMsgs(msgs map[string]string) {
     errorMsgMap.BatchSet(msgs)
 }
 
+// 设置captcha规则的验证码校验方法，参数为验证码ID及答案，例如：gvalid.SetCaptchaVerifier(gcaptcha.Verify)；
+// 校验方法只能获取到提交的参数，因此只适用于ID模式的验证码(ghttp的Session模式验证码请使用r.VerifyCaptcha校验)
+func SetCaptchaVerifier(verifier func(id, answer string) bool) {
+    captchaVerifier.Set(verifier)
+}
+
 // 判断必须字段
 func checkRequired(value, ruleKey, ruleVal string, params map[string]string) bool {
     required := false
@@ -242,7 +257,7 @@ func checkRequired(value, ruleKey, ruleVal string, params map[string]string) boo
 }
 
 // 对字段值长度进行检测
-func checkLength(value, ruleKey, ruleVal string, custonMsgs map[string]string) string {
+func checkLength(locale, value, ruleKey, ruleVal string, custonMsgs map[string]string) string {
     msg := ""
     switch ruleKey {
         // 长度范围
@@ -262,7 +277,7 @@ func checkLength(value, ruleKey, ruleVal string, custonMsgs map[string]string) s
             }
             if len(value) < min || len(value) > max {
                 if v, ok := custonMsgs[ruleKey]; !ok {
-                    msg = errorMsgMap.Get(ruleKey)
+                    msg = getErrorMsg(locale, ruleKey)
                 } else {
                     msg = v
                 }
@@ -276,14 +291,14 @@ func checkLength(value, ruleKey, ruleVal string, custonMsgs map[string]string) s
             if min, err := strconv.Atoi(ruleVal); err == nil {
                 if len(value) < min {
                     if v, ok := custonMsgs[ruleKey]; !ok {
-                        msg = errorMsgMap.Get(ruleKey)
+                        msg = getErrorMsg(locale, ruleKey)
                     } else {
                         msg = v
                     }
                     msg = strings.Replace(msg, ":min", strconv.Itoa(min), -1)
                 }
             } else {
-                msg = "校验参数[" + ruleVal + "]应当为整数类型"
+                msg = strings.Replace(getErrorMsg(locale, "invalid-rule-integer"), ":value", ruleVal, -1)
             }
 
         // 最大长度
@@ -291,21 +306,21 @@ func checkLength(value, ruleKey, ruleVal string, custonMsgs map[string]string) s
             if max, err := strconv.Atoi(ruleVal); err == nil {
                 if len(value) > max {
                     if v, ok := custonMsgs[ruleKey]; !ok {
-                        msg = errorMsgMap.Get(ruleKey)
+                        msg = getErrorMsg(locale, ruleKey)
                     } else {
                         msg = v
                     }
                     msg = strings.Replace(msg, ":max", strconv.Itoa(max), -1)
                 }
             } else {
-                msg = "校验参数[" + ruleVal + "]应当为整数类型"
+                msg = strings.Replace(getErrorMsg(locale, "invalid-rule-integer"), ":value", ruleVal, -1)
             }
     }
     return msg
 }
 
 // 对字段值大小进行检测
-func checkSize(value, ruleKey, ruleVal string, custonMsgs map[string]string) string {
+func checkSize(locale, value, ruleKey, ruleVal string, custonMsgs map[string]string) string {
     msg := ""
     switch ruleKey {
         // 大小范围
@@ -326,7 +341,7 @@ func checkSize(value, ruleKey, ruleVal string, custonMsgs map[string]string) str
             if v, err := strconv.ParseFloat(value, 10); err == nil {
                 if v < min || v > max {
                     if v, ok := custonMsgs[ruleKey]; !ok {
-                        msg = errorMsgMap.Get(ruleKey)
+                        msg = getErrorMsg(locale, ruleKey)
                     } else {
                         msg = v
                     }
@@ -334,7 +349,7 @@ func checkSize(value, ruleKey, ruleVal string, custonMsgs map[string]string) str
                     msg = strings.Replace(msg, ":max", strconv.FormatFloat(max, 'f', -1, 64), -1)
                 }
             } else {
-                msg = "输入参数[" + value + "]应当为数字类型"
+                msg = strings.Replace(getErrorMsg(locale, "invalid-value-number"), ":value", value, -1)
             }
 
         // 最小值
@@ -344,15 +359,15 @@ func checkSize(value, ruleKey, ruleVal string, custonMsgs map[string]string) str
                     if v < min {
                         msg, ok := custonMsgs[ruleKey]
                         if !ok {
-                            msg = errorMsgMap.Get(ruleKey)
+                            msg = getErrorMsg(locale, ruleKey)
                         }
                         msg = strings.Replace(msg, ":min", strconv.FormatFloat(min, 'f', -1, 64), -1)
                     }
                 } else {
-                    msg = "输入参数[" + value + "]应当为数字类型"
+                    msg = strings.Replace(getErrorMsg(locale, "invalid-value-number"), ":value", value, -1)
                 }
             } else {
-                msg = "校验参数[" + ruleVal + "]应当为数字类型"
+                msg = strings.Replace(getErrorMsg(locale, "invalid-rule-number"), ":value", ruleVal, -1)
             }
 
         // 最大值
@@ -362,15 +377,15 @@ func checkSize(value, ruleKey, ruleVal string, custonMsgs map[string]string) str
                     if v > max {
                         msg, ok := custonMsgs[ruleKey]
                         if !ok {
-                            msg = errorMsgMap.Get(ruleKey)
+                            msg = getErrorMsg(locale, ruleKey)
                         }
                         msg = strings.Replace(msg, ":max", strconv.FormatFloat(max, 'f', -1, 64), -1)
                     }
                 } else {
-                    msg = "输入参数[" + value + "]应当为数字类型"
+                    msg = strings.Replace(getErrorMsg(locale, "invalid-value-number"), ":value", value, -1)
                 }
             } else {
-                msg = "校验参数[" + ruleVal + "]应当为数字类型"
+                msg = strings.Replace(getErrorMsg(locale, "invalid-rule-number"), ":value", ruleVal, -1)
             }
     }
     return msg
@@ -378,6 +393,11 @@ func checkSize(value, ruleKey, ruleVal string, custonMsgs map[string]string) str
 
 // 检测键值对参数Map，注意返回参数是一个2维的关联数组，第一维键名为参数键名，第二维为带有错误的校验规则名称，值为错误信息
 func CheckMap(params map[string]interface{}, rules map[string]string, msgs...map[string]interface{}) map[string]map[string]string {
+    return CheckMapLocale("", params, rules, msgs...)
+}
+
+// 检测键值对参数Map，默认的错误信息使用指定的语言(如：en、ja、zh-CN)，其他同CheckMap
+func CheckMapLocale(locale string, params map[string]interface{}, rules map[string]string, msgs...map[string]interface{}) map[string]map[string]string {
     var value interface{}
     // 自定义消息，非必须参数，因此这里需要做判断
     customMsgs := make(map[string]interface{})
@@ -392,7 +412,7 @@ func CheckMap(params map[string]interface{}, rules map[string]string, msgs...map
             value = v
         }
         msg, _ := customMsgs[key]
-        if m := Check(value, rule, msg, params); m != nil {
+        if m := CheckLocale(locale, value, rule, msg, params); m != nil {
             // 如果值为nil，并且不需要require*验证时，其他验证失效
             if value == nil {
                 required := false;
@@ -422,6 +442,11 @@ func CheckMap(params map[string]interface{}, rules map[string]string, msgs...map
 
 // 校验struct对象属性，object参数也可以是一个指向对象的指针，返回值同CheckMap方法
 func CheckStruct(st interface{}, rules map[string]string, msgs...map[string]interface{}) map[string]map[string]string {
+    return CheckStructLocale("", st, rules, msgs...)
+}
+
+// 校验struct对象属性，默认的错误信息使用指定的语言，其他同CheckStruct
+func CheckStructLocale(locale string, st interface{}, rules map[string]string, msgs...map[string]interface{}) map[string]map[string]string {
     fields := structs.Fields(st)
     if rules == nil {
         rules = make(map[string]string)
@@ -469,7 +494,7 @@ func CheckStruct(st interface{}, rules map[string]string, msgs...map[string]inte
         }
 
     }
-    return CheckMap(params, rules, errMsgs)
+    return CheckMapLocale(locale, params, rules, errMsgs)
 }
 
 // 检测单条数据的规则.
@@ -477,6 +502,11 @@ func CheckStruct(st interface{}, rules map[string]string, msgs...map[string]inte
 // msgs为自定义错误信息，由于同一条数据的校验规则可能存在多条，为方便调用，参数类型支持string/map[string]string，允许传递多个自定义的错误信息，如果类型为string，那么中间使用"|"符号分隔多个自定义错误；
 // params参数为表单联合校验参数，对于需要联合校验的规则有效，如：required-*、same、different；
 func Check(val interface{}, rules string, msgs interface{}, params...map[string]interface{}) map[string]string {
+    return CheckLocale("", val, rules, msgs, params...)
+}
+
+// 检测单条数据的规则，默认的错误信息使用指定的语言，其他同Check
+func CheckLocale(locale string, val interface{}, rules string, msgs interface{}, params...map[string]interface{}) map[string]string {
     // 内部会将参数全部转换为字符串类型进行校验
     value  := strings.TrimSpace(gconv.String(val))
     data   := make(map[string]string)
@@ -520,7 +550,7 @@ func Check(val interface{}, rules string, msgs interface{}, params...map[string]
             case "length":            fallthrough
             case "min-length":        fallthrough
             case "max-length":
-                if msg := checkLength(value, ruleKey, ruleVal, custonMsgs); msg != "" {
+                if msg := checkLength(locale, value, ruleKey, ruleVal, custonMsgs); msg != "" {
                     errorMsgs[ruleKey] = msg
                 } else {
                     match = true
@@ -530,7 +560,7 @@ func Check(val interface{}, rules string, msgs interface{}, params...map[string]
             case "min":               fallthrough
             case "max":               fallthrough
             case "between":
-                if msg := checkSize(value, ruleKey, ruleVal, custonMsgs); msg != "" {
+                if msg := checkSize(locale, value, ruleKey, ruleVal, custonMsgs); msg != "" {
                     errorMsgs[ruleKey] = msg
                 } else {
                     match = true
@@ -718,6 +748,12 @@ func Check(val interface{}, rules string, msgs interface{}, params...map[string]
             case "mac":
                 match = gregex.IsMatchString(`^([0-9A-Fa-f]{2}-){5}[0-9A-Fa-f]{2}$`, value)
 
+            // 图形验证码，ruleVal为验证码ID的参数名称
+            case "captcha":
+                if verifier, ok := captchaVerifier.Val().(func(id, answer string) bool); ok && verifier != nil {
+                    match = verifier(data[ruleVal], value)
+                }
+
             default:
                 errorMsgs[ruleKey] = "Invalid rule name:" + ruleKey
         }
@@ -730,7 +766,7 @@ func Check(val interface{}, rules string, msgs interface{}, params...map[string]
                 if msg, ok := custonMsgs[ruleKey]; ok {
                     errorMsgs[ruleKey] = msg
                 } else {
-                    errorMsgs[ruleKey] = errorMsgMap.Get(ruleKey)
+                    errorMsgs[ruleKey] = getErrorMsg(locale, ruleKey)
                 }
             }
         }
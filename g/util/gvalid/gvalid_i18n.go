@@ -0,0 +1,163 @@
+// Copyright 2018 gf Author(https://gitee.com/johng/gf). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://gitee.com/johng/gf.
+
+// 多语言错误消息.
+
+package gvalid
+
+import (
+    "strings"
+    "gitee.com/johng/gf/g/util/gi18n"
+    "gitee.com/johng/gf/g/container/gmap"
+)
+
+const (
+    gI18N_KEY_PREFIX = "gvalid." // 翻译文件中校验错误消息的键名前缀，例如：gvalid.required
+)
+
+// 内置的多语言默认错误消息
+var localeMessages = map[string]map[string]string {
+    "en" : {
+        "required"             : "The field is required",
+        "required-if"          : "The field is required",
+        "required-unless"      : "The field is required",
+        "required-with"        : "The field is required",
+        "required-with-all"    : "The field is required",
+        "required-without"     : "The field is required",
+        "required-without-all" : "The field is required",
+        "date"                 : "The date format is invalid",
+        "date-format"          : "The date format is invalid",
+        "email"                : "The email address is invalid",
+        "phone"                : "The mobile phone number is invalid",
+        "telephone"            : "The telephone number is invalid",
+        "passport"             : "The account must start with a letter, contain only letters, digits and underscores, and be 6 to 18 characters long",
+        "password"             : "The password must be 6 to 18 visible characters",
+        "password2"            : "The password must be 6 to 18 visible characters, including upper and lower case letters and digits",
+        "password3"            : "The password must be 6 to 18 visible characters, including upper and lower case letters, digits and special characters",
+        "postcode"             : "The postal code is invalid",
+        "id-number"            : "The ID number is invalid",
+        "qq"                   : "The QQ number is invalid",
+        "ip"                   : "The IP address is invalid",
+        "ipv4"                 : "The IPv4 address is invalid",
+        "ipv6"                 : "The IPv6 address is invalid",
+        "mac"                  : "The MAC address is invalid",
+        "url"                  : "The URL is invalid",
+        "domain"               : "The domain name is invalid",
+        "length"               : "The field length must be between :min and :max characters",
+        "min-length"           : "The field length must be at least :min characters",
+        "max-length"           : "The field length must be at most :max characters",
+        "between"              : "The field value must be between :min and :max",
+        "min"                  : "The field value must be at least :min",
+        "max"                  : "The field value must be at most :max",
+        "json"                 : "The field must be valid JSON",
+        "xml"                  : "The field must be valid XML",
+        "array"                : "The field must be an array",
+        "integer"              : "The field must be an integer",
+        "float"                : "The field must be a float",
+        "boolean"              : "The field must be a boolean",
+        "same"                 : "The field value is invalid",
+        "different"            : "The field value is invalid",
+        "in"                   : "The field value is invalid",
+        "not-in"               : "The field value is invalid",
+        "regex"                : "The field value is invalid",
+        "captcha"              : "The captcha is incorrect",
+        "invalid-rule-integer" : "The rule parameter [:value] must be an integer",
+        "invalid-rule-number"  : "The rule parameter [:value] must be a number",
+        "invalid-value-number" : "The value [:value] must be a number",
+    },
+    "ja" : {
+        "required"             : "この項目は必須です",
+        "required-if"          : "この項目は必須です",
+        "required-unless"      : "この項目は必須です",
+        "required-with"        : "この項目は必須です",
+        "required-with-all"    : "この項目は必須です",
+        "required-without"     : "この項目は必須です",
+        "required-without-all" : "この項目は必須です",
+        "date"                 : "日付の形式が正しくありません",
+        "date-format"          : "日付の形式が正しくありません",
+        "email"                : "メールアドレスの形式が正しくありません",
+        "phone"                : "携帯電話番号の形式が正しくありません",
+        "telephone"            : "電話番号の形式が正しくありません",
+        "passport"             : "アカウントは英字で始まり、英数字とアンダースコアのみを含む6～18文字で入力してください",
+        "password"             : "パスワードは6～18文字の表示可能な文字で入力してください",
+        "password2"            : "パスワードは6～18文字で、大文字・小文字・数字を含めてください",
+        "password3"            : "パスワードは6～18文字で、大文字・小文字・数字・記号を含めてください",
+        "postcode"             : "郵便番号が正しくありません",
+        "id-number"            : "身分証番号が正しくありません",
+        "qq"                   : "QQ番号の形式が正しくありません",
+        "ip"                   : "IPアドレスの形式が正しくありません",
+        "ipv4"                 : "IPv4アドレスの形式が正しくありません",
+        "ipv6"                 : "IPv6アドレスの形式が正しくありません",
+        "mac"                  : "MACアドレスの形式が正しくありません",
+        "url"                  : "URLの形式が正しくありません",
+        "domain"               : "ドメイン名の形式が正しくありません",
+        "length"               : ":min～:max文字で入力してください",
+        "min-length"           : ":min文字以上で入力してください",
+        "max-length"           : ":max文字以下で入力してください",
+        "between"              : ":min～:maxの値を入力してください",
+        "min"                  : ":min以上の値を入力してください",
+        "max"                  : ":max以下の値を入力してください",
+        "json"                 : "JSON形式で入力してください",
+        "xml"                  : "XML形式で入力してください",
+        "array"                : "配列で入力してください",
+        "integer"              : "整数を入力してください",
+        "float"                : "小数を入力してください",
+        "boolean"              : "真偽値を入力してください",
+        "same"                 : "値が正しくありません",
+        "different"            : "値が正しくありません",
+        "in"                   : "値が正しくありません",
+        "not-in"               : "値が正しくありません",
+        "regex"                : "値が正しくありません",
+        "captcha"              : "認証コードが正しくありません",
+        "invalid-rule-integer" : "ルールのパラメータ[:value]は整数である必要があります",
+        "invalid-rule-number"  : "ルールのパラメータ[:value]は数値である必要があります",
+        "invalid-value-number" : "入力値[:value]は数値である必要があります",
+    },
+}
+
+// 多语言错误消息管理对象(并发安全)，键名为小写的语言名称
+var localeMsgMaps = gmap.NewStringInterfaceMap()
+
+func init() {
+    for locale, msgs := range localeMessages {
+        SetLocaleErrorMsgs(locale, msgs)
+    }
+}
+
+// 设置指定语言的默认错误消息，也可以在gi18n翻译文件中使用"gvalid."前缀的键名设置，例如：gvalid.required
+func SetLocaleErrorMsgs(locale string, msgs map[string]string) {
+    locale = formatLocale(locale)
+    localeMsgMaps.LockFunc(func(m map[string]interface{}) {
+        if _, ok := m[locale]; !ok {
+            m[locale] = gmap.NewStringStringMap()
+        }
+        m[locale].(*gmap.StringStringMap).BatchSet(msgs)
+    })
+}
+
+// 获取指定语言的默认错误消息，查找顺序：gi18n翻译文件 -> 内置的语言消息(语言及基础语言) -> 默认错误消息
+func getErrorMsg(locale, ruleKey string) string {
+    if locale == "" {
+        return errorMsgMap.Get(ruleKey)
+    }
+    if msg, ok := gi18n.Instance().Lookup(locale, gI18N_KEY_PREFIX + ruleKey); ok {
+        return msg
+    }
+    locale = formatLocale(locale)
+    for _, v := range []string{locale, strings.Split(locale, "-")[0]} {
+        if m := localeMsgMaps.Get(v); m != nil {
+            if msg := m.(*gmap.StringStringMap).Get(ruleKey); msg != "" {
+                return msg
+            }
+        }
+    }
+    return errorMsgMap.Get(ruleKey)
+}
+
+// 统一语言名称格式(小写，使用"-"连接)
+func formatLocale(locale string) string {
+    return strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+}
@@ -12,6 +12,7 @@ package gvalid_test
 import (
     "testing"
     "gitee.com/johng/gf/g/util/gvalid"
+    "gitee.com/johng/gf/g/util/gcaptcha"
     "strings"
 )
 
@@ -239,4 +240,44 @@ func Test_CheckMapWithNilAndNotRequiredField(t *testing.T) {
     if m := gvalid.CheckMap(data, rules); m != nil {
         t.Error(m)
     }
-}
\ No newline at end of file
+}
+func Test_CheckLocale(t *testing.T) {
+    rules := map[string]string {
+        "name" : "required|length:6,16",
+    }
+    params := map[string]interface{} {
+        "name" : "john",
+    }
+    if m := gvalid.CheckMapLocale("en-US", params, rules); m == nil || m["name"]["length"] != "The field length must be between 6 and 16 characters" {
+        t.Error("unexpected en messages:", m)
+    }
+    if m := gvalid.CheckLocale("ja", "", "required", nil); m == nil || m["required"] != "この項目は必須です" {
+        t.Error("unexpected ja messages:", m)
+    }
+    gvalid.SetLocaleErrorMsgs("fr", map[string]string{"required" : "Ce champ est obligatoire"})
+    if m := gvalid.CheckLocale("fr_FR", "", "required", nil); m == nil || m["required"] != "Ce champ est obligatoire" {
+        t.Error("unexpected fr messages:", m)
+    }
+}
+
+func Test_Captcha(t *testing.T) {
+    store := gcaptcha.NewMemoryStore()
+    gcaptcha.SetConfig(gcaptcha.Config{Store : store})
+    id, _, _ := gcaptcha.Generate()
+    params := map[string]interface{} {
+        "captcha_id" : id,
+        "captcha"    : store.Get(id, false),
+    }
+    // 未设置校验方法时校验失败
+    if m := gvalid.CheckMap(params, map[string]string{"captcha" : "required|captcha:captcha_id"}); m == nil {
+        t.Error("captcha verified without verifier")
+    }
+    gvalid.SetCaptchaVerifier(gcaptcha.Verify)
+    if m := gvalid.CheckMap(params, map[string]string{"captcha" : "required|captcha:captcha_id"}); m != nil {
+        t.Error(m)
+    }
+    // 验证码只能校验一次
+    if m := gvalid.CheckMap(params, map[string]string{"captcha" : "required|captcha:captcha_id"}); m == nil {
+        t.Error("captcha verified twice")
+    }
+}
@@ -0,0 +1,41 @@
+// 开发环境热编译运行命令，编译安装后在项目目录下执行：
+// gf run [main.go] [程序运行参数...]
+// 可选参数：
+// --path=项目目录 --output=可执行文件输出目录 --ignores=vendor,public --exts=.go,.html --delay=500(毫秒)
+package main
+
+import (
+    "fmt"
+    "time"
+    "strings"
+    "gitee.com/johng/gf/g/os/gcmd"
+    "gitee.com/johng/gf/g/frame/grun"
+)
+
+func run() {
+    config := grun.Config {
+        Path   : gcmd.Option.Get("path"),
+        Main   : gcmd.Value.Get(2),
+        Output : gcmd.Option.Get("output"),
+        Delay  : time.Duration(gcmd.Option.GetInt("delay"))*time.Millisecond,
+    }
+    if values := gcmd.Value.GetAll(); len(values) > 3 {
+        config.Args = values[3:]
+    }
+    if v := gcmd.Option.Get("ignores"); v != "" {
+        config.Ignores = strings.Split(v, ",")
+    }
+    if v := gcmd.Option.Get("exts"); v != "" {
+        config.Extensions = strings.Split(v, ",")
+    }
+    if err := grun.Run(config); err != nil {
+        fmt.Println(err)
+    }
+}
+
+func main() {
+    gcmd.BindHandle("run", run)
+    if err := gcmd.AutoRun(); err != nil {
+        fmt.Println("usage: gf run [main.go] [args...]")
+    }
+}
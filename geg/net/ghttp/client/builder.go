@@ -0,0 +1,38 @@
+package main
+
+import (
+    "os"
+    "fmt"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 链式请求构造示例
+func main() {
+    c := ghttp.NewClient()
+    c.SetPrefix("http://127.0.0.1:8199")
+
+    // JSON提交，返回内容解析到结构体
+    user := struct {
+        Id   int    `json:"id"`
+        Name string `json:"name"`
+    }{}
+    r, err := c.R().SetQuery(g.Map{"debug" : 1}).SetJSON(g.Map{"name" : "john"}).SetHeader("X-Token", "123").Post("/user")
+    if err != nil {
+        panic(err)
+    }
+    if err := r.Error(); err != nil {
+        fmt.Println(err)
+        return
+    }
+    r.Struct(&user)
+    fmt.Println(user)
+
+    // 文件上传
+    f, _ := os.Open("/home/john/avatar.png")
+    defer f.Close()
+    r, err = c.R().SetFormField("id", "1").SetFile("avatar", "avatar.png", f).Post("/user/avatar")
+    if err == nil {
+        fmt.Println(r.ReadAllString())
+    }
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+    "time"
+    "fmt"
+    "net/http"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 客户端中间件及熔断器示例
+func main() {
+    c := ghttp.NewClient()
+    // 请求日志
+    c.Use(func(req *http.Request, next ghttp.ClientHandlerFunc) (*http.Response, error) {
+        start     := time.Now()
+        resp, err := next(req)
+        if err != nil {
+            glog.Printfln("%s %s error: %v", req.Method, req.URL.String(), err)
+        } else {
+            glog.Printfln("%s %s %d %s", req.Method, req.URL.String(), resp.StatusCode, time.Since(start))
+        }
+        return resp, err
+    })
+    // 连续5次失败后熔断10秒
+    c.SetBreaker(5, 2, 10*time.Second)
+    for i := 0; i < 10; i++ {
+        if r, err := c.Get("http://127.0.0.1:8199/"); err == nil {
+            fmt.Println(r.ReadAllString())
+        } else if _, ok := err.(*ghttp.ClientBreakerOpenError); ok {
+            fmt.Println("fail fast:", err)
+        }
+    }
+}
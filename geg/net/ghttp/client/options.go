@@ -0,0 +1,26 @@
+package main
+
+import (
+    "time"
+    "context"
+    "fmt"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 客户端连接池、代理、Cookie、重试及请求超时示例
+func main() {
+    c := ghttp.NewClient()
+    c.SetConnPool(100, 32, time.Minute)
+    c.SetCookieJar()
+    c.SetRetry(3, 100*time.Millisecond)
+    //c.SetProxy("socks5://127.0.0.1:1080")
+
+    ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+    defer cancel()
+    if r, err := c.Ctx(ctx).Get("http://127.0.0.1:8199/"); err == nil {
+        defer r.Close()
+        fmt.Println(r.StatusCode, string(r.ReadAll()))
+    } else {
+        fmt.Println(err)
+    }
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+    "fmt"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 内存测试客户端示例，不需要启动Server监听端口，
+// 请求会完整经过Server的处理流程，并且客户端会保存Cookie，多次请求之间可以保持Session。
+func main() {
+    s := g.Server("test")
+    s.BindHandler("/login", func(r *ghttp.Request){
+        r.Session.Set("user", r.Get("name"))
+    })
+    s.BindHandler("/user", func(r *ghttp.Request){
+        r.Response.Write("user:", r.Session.GetString("user"))
+    })
+    c := ghttp.NewTestClient(s)
+    if _, err := c.Get("/login?name=john"); err != nil {
+        panic(err)
+    }
+    if r, err := c.Get("/user"); err == nil {
+        fmt.Println(r.StatusCode, string(r.ReadAll()))
+    } else {
+        panic(err)
+    }
+}
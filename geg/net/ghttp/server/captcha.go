@@ -0,0 +1,42 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+    "gitee.com/johng/gf/g/util/gvalid"
+    "gitee.com/johng/gf/g/util/gcaptcha"
+)
+
+// 图形验证码示例：
+// 1. Session模式：页面中使用 <img src="/captcha"> 显示验证码，提交后通过r.VerifyCaptcha校验；
+// 2. ID模式：页面中使用 <img src="/captcha?id=xxx"> 显示验证码，表单中同时提交验证码ID，通过gvalid的captcha规则校验(需要设置校验方法)。
+func main() {
+    gcaptcha.SetConfig(gcaptcha.Config {
+        Width  : 150,
+        Height : 50,
+        Length : 5,
+    })
+    gvalid.SetCaptchaVerifier(gcaptcha.Verify)
+    s := g.Server()
+    s.BindCaptcha("/captcha")
+    s.BindHandler("/login", func(r *ghttp.Request) {
+        if !r.VerifyCaptcha(r.GetString("captcha")) {
+            r.Response.Write("captcha incorrect")
+            return
+        }
+        r.Response.Write("ok")
+    })
+    s.BindHandler("/signup", func(r *ghttp.Request) {
+        params := map[string]interface{} {
+            "captcha_id" : r.Get("captcha_id"),
+            "captcha"    : r.Get("captcha"),
+        }
+        if e := gvalid.CheckMap(params, map[string]string{"captcha" : "required|captcha:captcha_id"}); e != nil {
+            r.Response.WriteJson(e)
+            return
+        }
+        r.Response.Write("ok")
+    })
+    s.SetPort(8199)
+    s.Run()
+}
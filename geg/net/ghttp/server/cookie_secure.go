@@ -0,0 +1,29 @@
+package main
+
+import (
+    "net/http"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 签名及加密Cookie示例，密钥轮换时将旧密钥放到SetCookieSecret的后续参数中
+func main() {
+    s := g.Server()
+    s.SetCookieSecret("new-secret", "old-secret")
+    s.BindHandler("/login", func(r *ghttp.Request) {
+        options := ghttp.CookieOptions {
+            MaxAge   : 30*86400,
+            HttpOnly : true,
+            SameSite : http.SameSiteLaxMode,
+        }
+        r.Cookie.SetSigned("remember", "john", options)
+        r.Cookie.SetEncrypted("state", `{"cart":[1,2,3]}`, options)
+        r.Response.Write("ok")
+    })
+    s.BindHandler("/info", func(r *ghttp.Request) {
+        r.Response.Writeln("remember:", r.Cookie.GetSigned("remember"))
+        r.Response.Writeln("state:",    r.Cookie.GetEncrypted("state"))
+    })
+    s.SetPort(8199)
+    s.Run()
+}
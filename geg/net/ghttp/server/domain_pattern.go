@@ -0,0 +1,20 @@
+package main
+
+import "gitee.com/johng/gf/g/net/ghttp"
+
+// 多租户域名示例，本地测试时可在hosts中添加：127.0.0.1 admin.tenant.local foo.tenant.local bar.tenant.local
+func main() {
+    s := ghttp.GetServer()
+    s.Domain("{tenant}.tenant.local").BindHandler("/", func(r *ghttp.Request) {
+        r.Response.Write("tenant: ", r.GetRouterString("tenant"))
+    })
+    // 完全相同的域名优先于域名模式
+    s.Domain("admin.tenant.local").BindHandler("/", func(r *ghttp.Request) {
+        r.Response.Write("admin")
+    })
+    s.Domain("*.tenant.local").BindStatusHandler(404, func(r *ghttp.Request) {
+        r.Response.Write("page not found for tenant ", r.GetRouterString("tenant"))
+    })
+    s.SetPort(8199)
+    s.Run()
+}
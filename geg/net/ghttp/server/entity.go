@@ -0,0 +1,30 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+type User struct {
+    Name string
+    Age  int
+    Tags []string
+}
+
+// 内容协商示例：
+// curl -H "Accept: application/x-yaml" http://127.0.0.1:8199/user
+// curl http://127.0.0.1:8199/user?format=xml
+// curl -H "Content-Type: application/json" -d '{"name":"john","age":18,"tags":["a","b"]}' http://127.0.0.1:8199/user
+func main() {
+    s := g.Server()
+    s.BindHandler("GET:/user", func(r *ghttp.Request) {
+        r.Response.WriteEntity(g.Map{"name" : "john", "age" : 18}, "user")
+    })
+    s.BindHandler("POST:/user", func(r *ghttp.Request) {
+        user := new(User)
+        r.GetPostToStruct(user)
+        r.Response.WriteEntity(user)
+    })
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+    "crypto/tls"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// HTTPS多域名证书示例，客户端通过SNI选择对应域名的证书，未匹配的域名使用默认证书；
+// 证书文件更新后自动重载，不需要重启服务。
+func main() {
+    s := g.Server()
+    c := ghttp.DefaultSetting()
+    c.HTTPSCertPath = "/home/john/https/server.crt"
+    c.HTTPSKeyPath  = "/home/john/https/server.key"
+    c.TLSMinVersion = tls.VersionTLS12
+    s.SetConfig(c)
+    if err := s.Domain("api.example.com").EnableHTTPS("/home/john/https/api.crt", "/home/john/https/api.key"); err != nil {
+        glog.Fatal(err)
+    }
+    if err := s.Domain("*.example.com").EnableHTTPS("/home/john/https/wildcard.crt", "/home/john/https/wildcard.key"); err != nil {
+        glog.Fatal(err)
+    }
+    s.BindHandler("/", func(r *ghttp.Request){
+        r.Response.Write("certificate expires at: ", r.Server.GetCertificateExpiry(r.GetHost()).String())
+    })
+    s.SetHTTPSPort(8199)
+    s.Run()
+}
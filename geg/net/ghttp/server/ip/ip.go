@@ -0,0 +1,29 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// IP访问控制示例，服务部署在nginx之后时，需要将nginx设置为可信代理才能获取真实的客户端IP
+func main() {
+    s := g.Server()
+    s.SetDenyIps([]string{"10.0.0.0/8", "fd00::/8"})
+    s.SetTrustedProxies([]string{"127.0.0.1", "::1"})
+    s.BindHandler("/", func(r *ghttp.Request) {
+        r.Response.Writeln("client ip:", r.GetClientIp())
+        r.Response.Writeln("remote ip:", r.GetRemoteIp())
+    })
+    s.BindHandler("/admin", func(r *ghttp.Request) {
+        r.Response.Writeln("admin")
+    })
+    // 管理后台只允许内网访问
+    s.BindIpFilter("/admin", []string{"127.0.0.1", "192.168.0.0/16", "::1"}, nil)
+    // 域名级别的访问控制
+    s.Domain("localhost").SetAllowIps([]string{"127.0.0.1", "::1"})
+    s.BindStatusHandler(403, func(r *ghttp.Request) {
+        r.Response.Write("access denied: ", r.GetClientIp())
+    })
+    s.SetPort(8199)
+    s.Run()
+}
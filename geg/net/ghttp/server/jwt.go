@@ -0,0 +1,52 @@
+package main
+
+import (
+    "time"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+    "gitee.com/johng/gf/g/crypto/gjwt"
+)
+
+// JWT认证示例：
+// 1. 访问 /login?name=john 获取令牌；
+// 2. 使用 Authorization: Bearer <access_token> 访问 /api/profile；
+// 3. 使用POST请求提交refresh_token参数到 /refresh 刷新令牌(刷新令牌只能使用一次，不要放在URL中)；
+// 4. 访问 /api/logout 注销当前令牌。
+func main() {
+    j, err := gjwt.New(gjwt.Config {
+        Secret  : []byte("my-secret-key"),
+        Issuer  : "gf",
+        Expire  : 30*time.Minute,
+        Store   : gjwt.NewCacheStore(),
+    })
+    if err != nil {
+        panic(err)
+    }
+    s := g.Server()
+    s.BindHandler("/login", func(r *ghttp.Request) {
+        pair, err := j.SignPair(gjwt.Claims{"sub" : r.GetQueryString("name")})
+        if err != nil {
+            r.Response.WriteStatus(500, err.Error())
+            return
+        }
+        r.Response.WriteJson(pair)
+    })
+    s.BindHandler("/refresh", func(r *ghttp.Request) {
+        pair, err := j.Refresh(r.GetPostString("refresh_token"))
+        if err != nil {
+            r.Response.WriteStatus(401, err.Error())
+            return
+        }
+        r.Response.WriteJson(pair)
+    })
+    s.BindHandler("/api/profile", func(r *ghttp.Request) {
+        r.Response.WriteJson(r.GetJwtClaims())
+    })
+    s.BindHandler("/api/logout", func(r *ghttp.Request) {
+        j.Revoke(r.GetJwtToken())
+        r.Response.Write("ok")
+    })
+    s.BindJwtAuth("/api/*any", j)
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+    "net"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/os/glog"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 自定义监听对象示例
+func main() {
+    s := g.Server()
+    s.BindHandler("/", func(r *ghttp.Request){
+        r.Response.Write("hello")
+    })
+    ln, err := net.Listen("tcp", "127.0.0.1:8199")
+    if err != nil {
+        glog.Fatal(err)
+    }
+    s.SetListener(ln)
+    s.Run()
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// unix socket监听示例，nginx配置：proxy_pass http://unix:/tmp/gf.sock;
+// 同时也支持systemd socket activation，使用systemd启动时会自动使用systemd传递的监听文件描述符，
+// 例如：systemd-socket-activate -l 8199 ./unix
+func main() {
+    s := g.Server()
+    s.BindHandler("/", func(r *ghttp.Request){
+        r.Response.Write("hello")
+    })
+    s.SetAddr("unix:/tmp/gf.sock")
+    s.Run()
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// access log格式及请求标识示例，
+// 请求时可以通过X-Request-Id传递请求标识，例如：curl -H "X-Request-Id: abc123" http://127.0.0.1:8199/user/1
+func main() {
+    s := g.Server()
+    s.BindHandler("/user/:id", func(r *ghttp.Request){
+        r.Response.Write("request id:", r.Id())
+    })
+    s.EnableRequestId()
+    s.SetAccessLogEnabled(true)
+    // 可选：default、combined、json，或者自定义模板
+    s.SetAccessLogFormat(ghttp.ACCESS_LOG_FORMAT_JSON)
+    //s.SetAccessLogFormat("{time} {request_id} {ip} \"{method} {uri}\" {status} {bytes} {latency}us {route}")
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// Prometheus监控指标示例，访问 http://127.0.0.1:8199/metrics 查看指标，
+// 请求指标按照路由规则(例如：/user/:id)而不是请求路径进行统计。
+func main() {
+    s := g.Server()
+    s.BindHandler("/user/:id", func(r *ghttp.Request){
+        r.Response.Write("user:", r.Get("id"))
+    })
+    s.EnableMetrics("/metrics")
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+type UserCreateReq struct {
+    Name     string `params:"name"     gvalid:"name@required|length:6,30#请输入用户名称|用户名称长度非法" doc:"用户名称"`
+    Password string `params:"password" gvalid:"password@required|length:6,30"                      doc:"用户密码"`
+}
+
+type User struct {
+    Id   int    `json:"id"   doc:"用户ID"`
+    Name string `json:"name" doc:"用户名称"`
+}
+
+type UserApi struct {}
+
+// 实现ghttp.ApiDocProvider接口，注册时即可确定各个方法的接口文档信息
+func (u *UserApi) ApiDocs() map[string]ghttp.ApiDoc {
+    return map[string]ghttp.ApiDoc {
+        "Create" : {
+            Summary  : "创建用户",
+            Request  : UserCreateReq{},
+            Response : User{},
+        },
+    }
+}
+
+func (u *UserApi) Create(r *ghttp.Request) {
+    req := new(UserCreateReq)
+    r.GetToStruct(req)
+    r.Response.WriteJson(User{Id : 1, Name : req.Name})
+}
+
+// OpenAPI接口文档示例，访问 http://127.0.0.1:8199/swagger 查看文档
+func main() {
+    s := g.Server()
+    s.BindObject("/user", new(UserApi))
+    s.BindHandler("GET:/user/:id", func(r *ghttp.Request) {
+        r.Response.WriteJson(User{Id : r.GetInt("id")})
+    })
+    // 手动设置接口文档信息
+    s.SetApiDoc("GET:/user/:id", ghttp.ApiDoc{
+        Summary  : "获取用户信息",
+        Tags     : []string{"UserApi"},
+        Response : User{},
+    })
+    s.SetApiInfo(ghttp.ApiInfo{
+        Title   : "用户服务接口",
+        Version : "1.0.0",
+    })
+    s.EnableOpenApi("/swagger.json", "/swagger")
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+    "fmt"
+    "time"
+    "testing"
+    "io/ioutil"
+    "net/http"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+    "gitee.com/johng/gf/g/container/gtype"
+)
+
+// 路由检索性能测试，注册大量路由后分别测试静态路由、高基数动态路由(如/user/:id)及模糊路由的请求性能，
+// 其中动态路由每次请求的路径都不相同，用于验证路由检索不依赖缓存。
+func main() {
+    s := g.Server()
+    h := func(r *ghttp.Request) {
+        r.Response.Write(r.Get("id"))
+    }
+    for i := 0; i < 1000; i++ {
+        s.BindHandler(fmt.Sprintf("/static/%d/list",     i), h)
+        s.BindHandler(fmt.Sprintf("/module%d/:id",        i), h)
+        s.BindHandler(fmt.Sprintf("/module%d/:id/edit",   i), h)
+        s.BindHandler(fmt.Sprintf("/module%d/{id}.html",  i), h)
+    }
+    s.BindHandler("/user/:id",    h)
+    s.BindHandler("/files/*path", h)
+    s.SetPort(8199)
+    s.SetAccessLogEnabled(false)
+    s.Start()
+    time.Sleep(time.Second)
+
+    client  := &http.Client{}
+    counter := gtype.NewInt()
+    request := func(b *testing.B, url func() string) {
+        b.RunParallel(func(pb *testing.PB) {
+            for pb.Next() {
+                if resp, err := client.Get(url()); err == nil {
+                    ioutil.ReadAll(resp.Body)
+                    resp.Body.Close()
+                } else {
+                    b.Error(err)
+                }
+            }
+        })
+    }
+    benchmarks := []struct{
+        name string
+        url  func() string
+    }{
+        {"static",  func() string { return "http://127.0.0.1:8199/static/500/list" }},
+        {"dynamic", func() string { return fmt.Sprintf("http://127.0.0.1:8199/user/%d", counter.Add(1)) }},
+        {"deep",    func() string { return fmt.Sprintf("http://127.0.0.1:8199/module999/%d/edit", counter.Add(1)) }},
+        {"field",   func() string { return fmt.Sprintf("http://127.0.0.1:8199/module999/%d.html", counter.Add(1)) }},
+        {"fuzzy",   func() string { return fmt.Sprintf("http://127.0.0.1:8199/files/a/b/%d", counter.Add(1)) }},
+    }
+    for _, v := range benchmarks {
+        url    := v.url
+        result := testing.Benchmark(func(b *testing.B) {
+            request(b, url)
+        })
+        fmt.Printf("%-8s %s\n", v.name, result.String())
+    }
+}
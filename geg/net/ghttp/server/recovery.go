@@ -0,0 +1,30 @@
+package main
+
+import (
+    "errors"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+var ErrNotFound = errors.New("user not found")
+
+// panic恢复示例，开发模式下访问/panic可以看到包含错误堆栈、源码片段及请求内容的调试页面
+func main() {
+    s := g.Server()
+    s.SetErrorMode(ghttp.ERROR_MODE_DEV)
+    s.SetErrorStatusFunc(func(err interface{}) int {
+        if err == ErrNotFound {
+            return 404
+        }
+        return 0
+    })
+    s.BindHandler("/panic", func(r *ghttp.Request) {
+        var m map[string]int
+        m["key"] = 1
+    })
+    s.BindHandler("/user", func(r *ghttp.Request) {
+        panic(ErrNotFound)
+    })
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 路由参数约束规则及命名路由
+func main() {
+    s := g.Server()
+    // 约束规则不匹配时会继续匹配其他路由，例如：/user/john 匹配 /user/{name}
+    s.BindHandler("/user/{id:\\d+}", func(r *ghttp.Request){
+        r.Response.Writeln("id:", r.Get("id"))
+        r.Response.Writeln(s.URL("user.name", g.Map{"name" : "john", "tab" : "profile"}))
+    }, "user.id")
+    s.BindHandler("/user/{name}", func(r *ghttp.Request){
+        r.Response.Writeln("name:", r.Get("name"))
+        r.Response.Writeln(s.URL("user.id", g.Map{"id" : 100}))
+    }, "user.name")
+    // 内置类型约束：int、uint、float、alpha、alnum、word、uuid
+    s.BindHandler("/archive/:year<uint>/{month:(0[1-9]|1[0-2])}.html", func(r *ghttp.Request){
+        r.Response.Writeln(r.Get("year"), r.Get("month"))
+    }, "archive")
+    // 通过r.Response.Template解析的模板中可以使用url函数反向生成URL，例如：
+    // <a href="{{url "archive" "year" 2018 "month" "07"}}">2018-07</a>
+    s.BindHandler("/", func(r *ghttp.Request){
+        r.Response.Writeln(s.URL("archive", g.Map{"year" : 2018, "month" : "07"}))
+    })
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+type User struct {}
+
+func (u *User) Info(r *ghttp.Request) {
+    r.Response.Write("user info")
+}
+
+// 路由列表示例：
+// 1. 调试模式下服务启动时在终端打印所有注册的路由、事件回调及状态码回调；
+// 2. 访问 /debug/admin/routes 查看路由列表页面，/debug/admin/routes?format=json 获取JSON格式的路由列表。
+func main() {
+    s := g.Server()
+    s.BindHandler("/", func(r *ghttp.Request) {
+        r.Response.Write("hello")
+    })
+    s.BindObject("/user", &User{})
+    s.BindHookHandler("/*any", ghttp.HOOK_BEFORE_SERVE, func(r *ghttp.Request) {
+        r.Response.Header().Set("X-Server", "gf")
+    })
+    s.BindStatusHandler(404, func(r *ghttp.Request) {
+        r.Response.Write("not found")
+    })
+    s.EnableAdmin()
+    s.SetDebug(true)
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+    "time"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+    "gitee.com/johng/gf/g/os/gtime"
+)
+
+// SSE及流式输出示例
+func main() {
+    s := g.Server()
+    // 浏览器端使用 new EventSource("/events") 接收
+    s.BindHandler("/events", func(r *ghttp.Request){
+        sse := r.Response.SSE()
+        sse.Retry(3*time.Second)
+        sse.Heartbeat(15*time.Second)
+        for {
+            select {
+                case <- sse.Done():
+                    return
+                case <- time.After(time.Second):
+                    sse.Event("time", g.Map{"now" : gtime.Datetime()})
+            }
+        }
+    })
+    // 普通流式输出
+    s.BindHandler("/stream", func(r *ghttp.Request){
+        r.Response.EnableStream()
+        for i := 0; i < 10; i++ {
+            r.Response.Writeln(i)
+            time.Sleep(time.Second)
+        }
+    })
+    s.SetPort(8199)
+    s.Run()
+}
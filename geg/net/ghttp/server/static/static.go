@@ -0,0 +1,23 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+// 静态文件目录映射、Cache-Control及SPA回退示例
+func main() {
+    s := g.Server()
+    s.SetServerRoot("/home/www/public")
+    s.AddStaticPath("/assets", "/home/www/assets")
+    // 单页应用，未知路径回退到/home/www/app/index.html
+    s.AddStaticPath("/app",    "/home/www/app", true)
+    s.SetCacheControl(".js",   "public, max-age=86400")
+    s.SetCacheControl(".css",  "public, max-age=86400")
+    s.SetCacheControl("*",     "no-cache")
+    s.BindHandler("/app/api/user", func(r *ghttp.Request){
+        r.Response.WriteJson(g.Map{"name" : "john"})
+    })
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+)
+
+type Avatar struct {
+    Uid  int
+    File *ghttp.UploadFile `params:"avatar"`
+}
+
+// 文件上传示例
+func main() {
+    s := g.Server()
+    s.SetUploadConfig("/upload", ghttp.UploadConfig{
+        MaxBodySize  : 10 << 20,
+        MaxFileSize  : 2  << 20,
+        MaxMemory    : 1  << 20,
+        AllowedExts  : []string{".jpg", ".png"},
+        AllowedMimes : []string{"image/*"},
+    })
+    s.BindHandler("/upload", func(r *ghttp.Request){
+        avatar := new(Avatar)
+        r.GetToStruct(avatar)
+        if avatar.File == nil {
+            r.Response.Writeln("upload failed:", r.GetUploadError())
+            return
+        }
+        if name, err := avatar.File.Save("/tmp/avatars", true); err == nil {
+            r.Response.Writeln(avatar.Uid, name, avatar.File.Size, avatar.File.ContentType)
+        } else {
+            r.Response.Writeln(err)
+        }
+    })
+    s.SetPort(8199)
+    s.Run()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+    "fmt"
+    "time"
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+    "github.com/gorilla/websocket"
+)
+
+// WebSocket Hub示例：房间聊天及广播通知
+func main() {
+    s   := g.Server()
+    hub := s.WebSocketHub()
+    hub.SetConfig(ghttp.WebSocketHubConfig{
+        WriteQueueSize : 128,
+        WriteTimeout   : 5  * time.Second,
+        PingInterval   : 20 * time.Second,
+        PongTimeout    : 40 * time.Second,
+        MaxMessageSize : 4096,
+    })
+    hub.BindConnectHandler(func(c *ghttp.WebSocketConn) {
+        room := c.Request.Get("room", "lobby")
+        c.Set("room", room)
+        c.Join(room)
+        hub.BroadcastRoom(room, websocket.TextMessage, []byte(fmt.Sprintf("user %d joined", c.Id)))
+    })
+    hub.BindDisconnectHandler(func(c *ghttp.WebSocketConn) {
+        hub.BroadcastRoom(c.Get("room").(string), websocket.TextMessage, []byte(fmt.Sprintf("user %d left", c.Id)))
+    })
+    s.BindWebSocket("/chat", func(c *ghttp.WebSocketConn, msgType int, data []byte) {
+        hub.BroadcastRoom(c.Get("room").(string), msgType, data)
+    })
+    // 向所有连接广播系统通知
+    s.BindHandler("/notify", func(r *ghttp.Request) {
+        hub.Broadcast(websocket.TextMessage, []byte(r.Get("message")))
+        r.Response.Writeln("sent to", hub.Size(), "connections")
+    })
+    s.SetPort(8199)
+    s.Run()
+}
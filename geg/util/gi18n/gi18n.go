@@ -0,0 +1,29 @@
+package main
+
+import (
+    "gitee.com/johng/gf/g"
+    "gitee.com/johng/gf/g/net/ghttp"
+    "gitee.com/johng/gf/g/util/gi18n"
+    "gitee.com/johng/gf/g/util/gvalid"
+)
+
+// 多语言示例，分别访问以下地址查看效果：
+// http://127.0.0.1:8199/?name=john
+// http://127.0.0.1:8199/?name=john&lang=ja
+// http://127.0.0.1:8199/template?name=john&lang=ja
+func main() {
+    gi18n.SetPath("i18n")
+    s := g.Server()
+    s.BindHandler("/", func(r *ghttp.Request) {
+        r.Response.Writeln(r.T("title"))
+        r.Response.Writeln(r.T("hello", r.GetQueryString("name")))
+        if e := gvalid.CheckLocale(r.GetLocale(), r.Get("age"), "required|integer", nil); e != nil {
+            r.Response.Writeln(e)
+        }
+    })
+    s.BindHandler("/template", func(r *ghttp.Request) {
+        r.Response.Template("index.html", g.Map{"name" : r.GetQueryString("name")})
+    })
+    s.SetPort(8199)
+    s.Run()
+}